@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"frugal-ls/internal/check"
+	"frugal-ls/internal/lsp"
+)
+
+// Exit codes shared by the check and format commands
+const (
+	exitOK       = 0
+	exitProblems = 1
+	exitUsage    = 2
+)
+
+// stringList is a flag that can be repeated to collect several values
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// runCheck implements the check command and returns the process exit code
+func runCheck(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	var includePaths stringList
+	format := flags.String("format", check.FormatHuman, "output format: human, json or sarif")
+	flags.Var(&includePaths, "I", "additional include directory (repeatable)")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: frugal-ls check [flags] [paths...]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Checks .frugal files and exits with status 1 if any errors are found.")
+		fmt.Fprintln(stderr, "Directories are searched recursively; the default path is the current directory.")
+		fmt.Fprintln(stderr)
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	report, err := check.Run(check.Options{
+		Paths:        paths,
+		IncludePaths: includePaths,
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+
+	base, _ := os.Getwd()
+	if err := check.Write(stdout, report, *format, base, lsp.LanguageServerVersion); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+
+	if report.ErrorCount() > 0 {
+		return exitProblems
+	}
+	return exitOK
+}
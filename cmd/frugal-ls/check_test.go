@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunCheck(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.frugal")
+	invalid := filepath.Join(dir, "invalid.frugal")
+	if err := os.WriteFile(valid, []byte("struct User {\n    1: string name\n}"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(invalid, []byte("struct User {\n    1: Missing m\n}"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		args     []string
+		exitCode int
+		stdout   string
+	}{
+		{"clean file", []string{valid}, exitOK, "0 error(s)"},
+		{"errors", []string{invalid}, exitProblems, "Unknown type 'Missing'"},
+		{"json", []string{"--format", "json", invalid}, exitProblems, `"severity": "error"`},
+		{"sarif", []string{"--format=sarif", dir}, exitProblems, `"version": "2.1.0"`},
+		{"unknown format", []string{"--format", "xml", valid}, exitUsage, ""},
+		{"missing path", []string{filepath.Join(dir, "missing")}, exitUsage, ""},
+		{"bad flag", []string{"--nope"}, exitUsage, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := runCheck(tc.args, &stdout, &stderr); code != tc.exitCode {
+				t.Errorf("Expected exit code %d, got %d (stderr: %s)", tc.exitCode, code, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tc.stdout) {
+				t.Errorf("Expected output to contain %q, got:\n%s", tc.stdout, stdout.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	protocol "github.com/tliron/glsp/protocol_3_16"
+
+	"frugal-ls/internal/check"
+	"frugal-ls/internal/compat"
+	"frugal-ls/internal/config"
+	"frugal-ls/internal/lsp"
+)
+
+// runCompat implements the compat command and returns the process exit code
+func runCompat(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("compat", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	base := flags.String("base", "", "baseline to compare against: a git revision or a directory")
+	format := flags.String("format", check.FormatHuman, "output format: human, json or sarif")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: frugal-ls compat --base <git-ref|dir> [flags] [path]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Reports wire-incompatible changes from the baseline to the .frugal files under")
+		fmt.Fprintln(stderr, "path (default: the current directory) and exits with status 1 if there are any.")
+		fmt.Fprintln(stderr)
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *base == "" || flags.NArg() > 1 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	path := "."
+	if flags.NArg() == 1 {
+		path = flags.Arg(0)
+	}
+	report, err := compatReport(*base, path)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+
+	cwd, _ := os.Getwd()
+	if err := check.Write(stdout, report, *format, cwd, lsp.LanguageServerVersion); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+
+	if report.ErrorCount() > 0 {
+		return exitProblems
+	}
+	return exitOK
+}
+
+// compatReport compares the files under path, or the single file path, against the
+// baseline and reports each change as an error
+func compatReport(base, path string) (*check.Report, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, only := abs, ""
+	if !info.IsDir() {
+		dir, only = filepath.Dir(abs), filepath.Base(abs)
+	}
+
+	cfg, err := config.ForPath(abs)
+	if err != nil {
+		return nil, err
+	}
+
+	// A baseline directory given on the command line is relative to the working directory
+	if info, err := os.Stat(base); err == nil && info.IsDir() {
+		if base, err = filepath.Abs(base); err != nil {
+			return nil, err
+		}
+	}
+
+	baseline, err := compat.Load(base, dir, cfg.Ignore)
+	if err != nil {
+		return nil, err
+	}
+	current, err := compat.LoadDir(dir, cfg.Ignore)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := compat.CompareSnapshots(baseline, current)
+	report := &check.Report{}
+	for _, rel := range baseline.Paths() {
+		if only != "" && rel != only {
+			continue
+		}
+
+		file := filepath.Join(dir, filepath.FromSlash(rel))
+		result := check.FileResult{Path: file, URI: "file://" + file, Diagnostics: []protocol.Diagnostic{}}
+		for _, change := range changes[rel] {
+			diagnostic := change.Diagnostic()
+			diagnostic.Severity = &[]protocol.DiagnosticSeverity{protocol.DiagnosticSeverityError}[0]
+			result.Diagnostics = append(result.Diagnostics, diagnostic)
+		}
+		sort.SliceStable(result.Diagnostics, func(i, j int) bool {
+			a, b := result.Diagnostics[i].Range.Start, result.Diagnostics[j].Range.Start
+			return a.Line < b.Line || (a.Line == b.Line && a.Character < b.Character)
+		})
+		report.Files = append(report.Files, result)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunCompat(t *testing.T) {
+	dir := writeFormatFiles(t, map[string]string{
+		"v1/user.frugal":  "struct User {\n    1: string name\n}",
+		"v1/tags.frugal":  "struct Tag {\n    1: string name\n}",
+		"v2/user.frugal":  "struct User {\n    1: i64 name\n}",
+		"v2/tags.frugal":  "struct Tag {\n    1: string name,\n    2: optional string color\n}",
+		"v2b/user.frugal": "struct User {\n    1: string name\n}",
+		"v2b/tags.frugal": "struct Tag {\n    1: string name\n}",
+	})
+	base := filepath.Join(dir, "v1")
+
+	testCases := []struct {
+		name     string
+		args     []string
+		exitCode int
+		stdout   string
+	}{
+		{"breaking", []string{"--base", base, filepath.Join(dir, "v2")}, exitProblems, "user.frugal:2:8: error: Breaking change: type of field 1 'name'"},
+		{"compatible", []string{"--base", base, filepath.Join(dir, "v2b")}, exitOK, "0 error(s), 0 warning(s) in 2 file(s)"},
+		{"single file", []string{"--base", base, filepath.Join(dir, "v2", "tags.frugal")}, exitOK, "in 1 file(s)"},
+		{"json", []string{"--base", base, "--format", "json", filepath.Join(dir, "v2")}, exitProblems, `"code": "field-type-changed"`},
+		{"missing base", []string{filepath.Join(dir, "v2")}, exitUsage, ""},
+		{"unknown revision", []string{"--base", "no-such-revision", filepath.Join(dir, "v2")}, exitUsage, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := runCompat(tc.args, &stdout, &stderr); code != tc.exitCode {
+				t.Fatalf("Expected exit code %d, got %d (stderr: %s)", tc.exitCode, code, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tc.stdout) {
+				t.Errorf("Expected output to contain %q, got:\n%s", tc.stdout, stdout.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	protocol "github.com/tliron/glsp/protocol_3_16"
+
+	"frugal-ls/internal/config"
+	"frugal-ls/internal/diff"
+	"frugal-ls/internal/document"
+	"frugal-ls/internal/features"
+	"frugal-ls/internal/workspace"
+)
+
+// stdinName labels standard input in messages and diffs
+const stdinName = "<standard input>"
+
+// formatOptions holds the format command's flags
+type formatOptions struct {
+	write bool
+	check bool
+	diff  bool
+}
+
+// runFormat implements the format command and returns the process exit code
+func runFormat(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("format", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	var opts formatOptions
+	flags.BoolVar(&opts.write, "w", false, "write the result to the file instead of stdout")
+	flags.BoolVar(&opts.check, "check", false, "list files whose formatting differs and exit with status 1")
+	flags.BoolVar(&opts.diff, "d", false, "print a unified diff instead of the formatted source")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: frugal-ls format [flags] [paths...]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Formats .frugal files. Directories are searched recursively and paths may be")
+		fmt.Fprintln(stderr, "glob patterns. Without paths, or with -, standard input is formatted.")
+		fmt.Fprintln(stderr)
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 || (len(paths) == 1 && paths[0] == "-") {
+		if opts.write {
+			fmt.Fprintln(stderr, "Error: cannot use -w with standard input")
+			return exitUsage
+		}
+		// Standard input is formatted with the configuration for the working directory
+		cfg, err := config.ForPath(".")
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitUsage
+		}
+		content, err := io.ReadAll(stdin)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitUsage
+		}
+		changed, err := formatContent(stdinName, "", content, cfg.Format, opts, stdout)
+		return formatExitCode(changed, err, opts, stderr)
+	}
+
+	// Each file is formatted with the configuration discovered from its own directory
+	configs := config.NewCache()
+	files, err := formatTargets(paths, configs)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+
+	anyChanged := false
+	exitCode := exitOK
+	for _, path := range files {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			exitCode = exitUsage
+			continue
+		}
+		cfg, err := configs.ForPath(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			exitCode = exitUsage
+			continue
+		}
+		changed, err := formatContent(path, path, content, cfg.Format, opts, stdout)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			exitCode = exitUsage
+			continue
+		}
+		anyChanged = anyChanged || changed
+	}
+
+	if exitCode == exitOK && opts.check && anyChanged {
+		return exitProblems
+	}
+	return exitCode
+}
+
+// formatExitCode returns the exit code for a single formatted input
+func formatExitCode(changed bool, err error, opts formatOptions, stderr io.Writer) int {
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	if opts.check && changed {
+		return exitProblems
+	}
+	return exitOK
+}
+
+// formatContent formats one input with the given formatter settings and reports it
+// according to the options. path is empty for standard input. It returns whether
+// formatting changed the content.
+func formatContent(name, path string, content []byte, format config.Format, opts formatOptions, stdout io.Writer) (bool, error) {
+	formatted, err := formatSource(content, format)
+	if err != nil {
+		return false, fmt.Errorf("failed to format %s: %w", name, err)
+	}
+	changed := formatted != string(content)
+
+	if opts.check && changed {
+		fmt.Fprintln(stdout, name)
+	}
+	if opts.diff && changed {
+		fmt.Fprint(stdout, diff.Unified(name+".orig", name, string(content), formatted))
+	}
+	if opts.write && changed {
+		info, err := os.Stat(path)
+		if err != nil {
+			return changed, err
+		}
+		if err := os.WriteFile(path, []byte(formatted), info.Mode().Perm()); err != nil {
+			return changed, err
+		}
+	}
+	if !opts.write && !opts.check && !opts.diff {
+		fmt.Fprint(stdout, formatted)
+	}
+
+	return changed, nil
+}
+
+// formatSource formats Frugal source with the same settings the editor uses by default,
+// overridden by the project configuration
+func formatSource(content []byte, format config.Format) (string, error) {
+	// The provider only formats documents it recognizes as .frugal files
+	doc := &document.Document{
+		URI:     "file:///format.frugal",
+		Path:    "format.frugal",
+		Content: content,
+		Version: 1,
+	}
+
+	options := protocol.FormattingOptions{
+		"tabSize":      4,
+		"insertSpaces": true,
+	}
+
+	provider := features.NewFormattingProvider()
+	provider.SetFormat(format)
+	edits, err := provider.ProvideDocumentFormatting(doc, options)
+	if err != nil {
+		return "", err
+	}
+	if len(edits) == 0 {
+		return string(content), nil
+	}
+	// The provider replaces the whole document with a single edit
+	return edits[0].NewText, nil
+}
+
+// formatTargets expands files, directories and glob patterns into the files to format.
+// Directories matching an ignore pattern of the configuration for the directory being
+// searched are skipped.
+func formatTargets(paths []string, configs *config.Cache) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+
+	for _, pattern := range paths {
+		matches := []string{pattern}
+		if strings.ContainsAny(pattern, "*?[") {
+			var err error
+			matches, err = filepath.Glob(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %s: %w", pattern, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no files match %s", pattern)
+			}
+		}
+
+		for _, path := range matches {
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil, err
+			}
+			if !info.IsDir() {
+				add(path)
+				continue
+			}
+			cfg, err := configs.ForPath(path)
+			if err != nil {
+				return nil, err
+			}
+			for _, file := range workspace.FindFrugalFiles([]string{path}, cfg.Ignore...) {
+				add(file)
+			}
+		}
+	}
+
+	return files, nil
+}
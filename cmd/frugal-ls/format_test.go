@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	unformattedSource = "struct User {\n1: string name\n}\n"
+	formattedSource   = "struct User {\n    1: string name\n}\n"
+)
+
+// writeFormatFiles creates the files under dir and returns dir
+func writeFormatFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestRunFormatStdout(t *testing.T) {
+	dir := writeFormatFiles(t, map[string]string{"user.frugal": unformattedSource})
+
+	var stdout, stderr bytes.Buffer
+	if code := runFormat([]string{filepath.Join(dir, "user.frugal")}, nil, &stdout, &stderr); code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d (stderr: %s)", exitOK, code, stderr.String())
+	}
+	if stdout.String() != formattedSource {
+		t.Errorf("Unexpected output:\n%q", stdout.String())
+	}
+}
+
+func TestRunFormatConfig(t *testing.T) {
+	dir := writeFormatFiles(t, map[string]string{
+		".frugal-ls.yaml":        "format:\n  indentSize: 2\nignore: [generated]\n",
+		"api/user.frugal":        unformattedSource,
+		"api/generated/x.frugal": unformattedSource,
+	})
+
+	var stdout, stderr bytes.Buffer
+	if code := runFormat([]string{"--check", dir}, nil, &stdout, &stderr); code != exitProblems {
+		t.Fatalf("Expected exit code %d, got %d (stderr: %s)", exitProblems, code, stderr.String())
+	}
+	if strings.TrimSpace(stdout.String()) != filepath.Join(dir, "api/user.frugal") {
+		t.Errorf("Expected only the file outside the ignored directory, got %q", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := runFormat([]string{filepath.Join(dir, "api/user.frugal")}, nil, &stdout, &stderr); code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d", exitOK, code)
+	}
+	if stdout.String() != "struct User {\n  1: string name\n}\n" {
+		t.Errorf("Expected configured indentation, got %q", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := os.WriteFile(filepath.Join(dir, ".frugal-ls.yaml"), []byte("format:\n  indent: 2\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if code := runFormat([]string{dir}, nil, &stdout, &stderr); code != exitUsage {
+		t.Errorf("Expected invalid config to be a usage error, got %d", code)
+	}
+}
+
+func TestRunFormatConfigPerPath(t *testing.T) {
+	dir := writeFormatFiles(t, map[string]string{
+		"a/.frugal-ls.yaml":      "format:\n  indentSize: 2\n",
+		"a/user.frugal":          unformattedSource,
+		"b/.frugal-ls.yaml":      "format:\n  indentSize: 8\nignore: [generated]\n",
+		"b/user.frugal":          unformattedSource,
+		"b/generated/out.frugal": unformattedSource,
+	})
+
+	var stdout, stderr bytes.Buffer
+	if code := runFormat([]string{"-w", filepath.Join(dir, "a"), filepath.Join(dir, "b")}, nil, &stdout, &stderr); code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d (stderr: %s)", exitOK, code, stderr.String())
+	}
+
+	for name, expected := range map[string]string{
+		"a/user.frugal":          "struct User {\n  1: string name\n}\n",
+		"b/user.frugal":          "struct User {\n        1: string name\n}\n",
+		"b/generated/out.frugal": unformattedSource,
+	} {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		if string(content) != expected {
+			t.Errorf("Expected %s to use its own directory's config, got %q", name, content)
+		}
+	}
+}
+
+func TestRunFormatStdin(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := runFormat(nil, strings.NewReader(unformattedSource), &stdout, &stderr); code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d", exitOK, code)
+	}
+	if stdout.String() != formattedSource {
+		t.Errorf("Unexpected output:\n%q", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := runFormat([]string{"--check", "-"}, strings.NewReader(unformattedSource), &stdout, &stderr); code != exitProblems {
+		t.Errorf("Expected --check on stdin to fail, got %d", code)
+	}
+	if strings.TrimSpace(stdout.String()) != stdinName {
+		t.Errorf("Expected stdin to be listed, got %q", stdout.String())
+	}
+
+	if code := runFormat([]string{"-w"}, strings.NewReader(unformattedSource), &stdout, &stderr); code != exitUsage {
+		t.Errorf("Expected -w with stdin to be rejected, got %d", code)
+	}
+}
+
+func TestRunFormatWrite(t *testing.T) {
+	dir := writeFormatFiles(t, map[string]string{
+		"api/user.frugal":      unformattedSource,
+		"api/nested/ok.frugal": formattedSource,
+		"api/notes.txt":        unformattedSource,
+	})
+
+	var stdout, stderr bytes.Buffer
+	if code := runFormat([]string{"-w", dir}, nil, &stdout, &stderr); code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d (stderr: %s)", exitOK, code, stderr.String())
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("Expected no output with -w, got %q", stdout.String())
+	}
+
+	for name, expected := range map[string]string{
+		"api/user.frugal":      formattedSource,
+		"api/nested/ok.frugal": formattedSource,
+		"api/notes.txt":        unformattedSource,
+	} {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		if string(content) != expected {
+			t.Errorf("%s: unexpected content %q", name, content)
+		}
+	}
+}
+
+func TestRunFormatCheckAndDiff(t *testing.T) {
+	dir := writeFormatFiles(t, map[string]string{
+		"user.frugal": unformattedSource,
+		"ok.frugal":   formattedSource,
+	})
+	user := filepath.Join(dir, "user.frugal")
+
+	var stdout, stderr bytes.Buffer
+	if code := runFormat([]string{"--check", filepath.Join(dir, "*.frugal")}, nil, &stdout, &stderr); code != exitProblems {
+		t.Fatalf("Expected exit code %d, got %d (stderr: %s)", exitProblems, code, stderr.String())
+	}
+	if stdout.String() != user+"\n" {
+		t.Errorf("Expected only the unformatted file to be listed, got %q", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := runFormat([]string{"-d", user}, nil, &stdout, &stderr); code != exitOK {
+		t.Fatalf("Expected -d alone to succeed, got %d", code)
+	}
+	if !strings.Contains(stdout.String(), "-1: string name\n+    1: string name\n") {
+		t.Errorf("Expected unified diff, got:\n%s", stdout.String())
+	}
+
+	// Checking never modifies files
+	content, _ := os.ReadFile(user)
+	if string(content) != unformattedSource {
+		t.Errorf("Expected file to be unchanged, got %q", content)
+	}
+
+	stdout.Reset()
+	if code := runFormat([]string{"--check", filepath.Join(dir, "ok.frugal")}, nil, &stdout, &stderr); code != exitOK {
+		t.Errorf("Expected formatted file to pass --check, got %d", code)
+	}
+}
+
+func TestRunFormatMissingPath(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	dir := t.TempDir()
+	if code := runFormat([]string{filepath.Join(dir, "missing.frugal")}, nil, &stdout, &stderr); code != exitUsage {
+		t.Errorf("Expected exit code %d for missing file, got %d", exitUsage, code)
+	}
+	if code := runFormat([]string{filepath.Join(dir, "*.frugal")}, nil, &stdout, &stderr); code != exitUsage {
+		t.Errorf("Expected exit code %d for unmatched glob, got %d", exitUsage, code)
+	}
+}
@@ -5,13 +5,10 @@ import (
 	"log"
 	"os"
 
-	"frugal-ls/internal/document"
-	"frugal-ls/internal/features"
 	"frugal-ls/internal/lsp"
 	"frugal-ls/internal/parser"
 	"frugal-ls/pkg/ast"
 
-	protocol "github.com/tliron/glsp/protocol_3_16"
 	tree_sitter "github.com/tree-sitter/go-tree-sitter"
 )
 
@@ -28,14 +25,11 @@ func main() {
 			}
 			return
 		case "format":
-			// Format file
-			if len(os.Args) > 2 {
-				formatFile(os.Args[2])
-			} else {
-				fmt.Println("Error: format command requires a file argument")
-				fmt.Println("Usage: frugal-ls format <file>")
-			}
-			return
+			os.Exit(runFormat(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+		case "check":
+			os.Exit(runCheck(os.Args[2:], os.Stdout, os.Stderr))
+		case "compat":
+			os.Exit(runCompat(os.Args[2:], os.Stdout, os.Stderr))
 		case "--help", "-h":
 			printUsage()
 			return
@@ -54,7 +48,9 @@ func printUsage() {
 	fmt.Println()
 	fmt.Println("Usage:")
 	fmt.Println("  frugal-ls                 Run as LSP server (default)")
-	fmt.Println("  frugal-ls format <file>   Format a .frugal file")
+	fmt.Println("  frugal-ls format [paths]  Format .frugal files or standard input")
+	fmt.Println("  frugal-ls check [paths]   Report diagnostics for .frugal files")
+	fmt.Println("  frugal-ls compat [path]   Report breaking changes against a baseline")
 	fmt.Println("  frugal-ls --test [file]   Test parser with file or sample")
 	fmt.Println("  frugal-ls --version       Show version information")
 	fmt.Println("  frugal-ls --help          Show this help message")
@@ -65,7 +61,22 @@ func printUsage() {
 	fmt.Println("  Vim, Emacs, etc.")
 	fmt.Println()
 	fmt.Println("Format Mode:")
-	fmt.Println("  format <file>              Format and output formatted .frugal file")
+	fmt.Println("  format [paths...]          Print formatted files, directories or globs (default: stdin)")
+	fmt.Println("  -w                         Write the result to the file instead of stdout")
+	fmt.Println("  --check                    List files that would change and exit with status 1")
+	fmt.Println("  -d                         Print a unified diff of the changes")
+	fmt.Println()
+	fmt.Println("Check Mode:")
+	fmt.Println("  check [paths...]           Check files and directories (default: .)")
+	fmt.Println("  --format human|json|sarif  Output format (default: human)")
+	fmt.Println("  -I <dir>                   Additional include directory (repeatable)")
+	fmt.Println("  Exits with status 1 if any errors are reported.")
+	fmt.Println()
+	fmt.Println("Compat Mode:")
+	fmt.Println("  compat --base <ref|dir> [path]  Compare files under path (default: .) with a git")
+	fmt.Println("                             revision or a directory holding the previous version")
+	fmt.Println("  --format human|json|sarif  Output format (default: human)")
+	fmt.Println("  Exits with status 1 if any breaking changes are found.")
 	fmt.Println()
 	fmt.Println("Test Mode:")
 	fmt.Println("  --test                     Parse sample.frugal (if available)")
@@ -188,45 +199,6 @@ func testParseFile(filename string, source ...[]byte) {
 	fmt.Printf("\nParsing test completed for %s\n", filename)
 }
 
-func formatFile(filename string) {
-	// Read the file
-	content, err := os.ReadFile(filename)
-	if err != nil {
-		log.Fatalf("Failed to read file %s: %v", filename, err)
-	}
-
-	// Create a document manually (similar to manager.DidOpen)
-	doc := &document.Document{
-		URI:     "file://" + filename,
-		Path:    filename,
-		Content: content,
-		Version: 1,
-	}
-
-	// Create a formatting provider
-	formatter := features.NewFormattingProvider()
-
-	// Format with default options (spaces, tab size 4)
-	options := protocol.FormattingOptions{
-		"tabSize":      4,
-		"insertSpaces": true,
-	}
-
-	edits, err := formatter.ProvideDocumentFormatting(doc, options)
-	if err != nil {
-		log.Fatalf("Failed to format file: %v", err)
-	}
-
-	// Apply edits and output result
-	if len(edits) > 0 {
-		// For simple cases, we expect one edit that replaces the entire document
-		fmt.Print(edits[0].NewText)
-	} else {
-		// No changes needed
-		fmt.Print(string(content))
-	}
-}
-
 func printLimitedTree(node *tree_sitter.Node, source []byte, indent int, maxDepth int) {
 	if node == nil || indent > maxDepth {
 		return
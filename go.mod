@@ -6,6 +6,7 @@ require (
 	github.com/charliestrawn/tree-sitter-frugal v0.0.0-20250809020924-43e46220c259
 	github.com/tliron/glsp v0.2.2
 	github.com/tree-sitter/go-tree-sitter v0.25.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,249 @@
+// Package check runs the language server's diagnostics over files on disk, for use
+// outside an editor such as in CI.
+package check
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	protocol "github.com/tliron/glsp/protocol_3_16"
+
+	"frugal-ls/internal/config"
+	"frugal-ls/internal/document"
+	"frugal-ls/internal/features"
+	"frugal-ls/internal/parser"
+	"frugal-ls/internal/workspace"
+)
+
+// Options configures a check run
+type Options struct {
+	// Paths are the files and directories to check. Directories are searched recursively.
+	Paths []string
+	// IncludePaths are additional directories searched for includes, like frugal's -I flag
+	IncludePaths []string
+	// Workers is the number of files parsed in parallel; zero uses one per CPU
+	Workers int
+	// Config is the project configuration for every file; nil discovers it for each file
+	// from the file's directory upward
+	Config *config.Config
+}
+
+// FileResult holds the diagnostics reported for a single file
+type FileResult struct {
+	Path        string
+	URI         string
+	Diagnostics []protocol.Diagnostic
+}
+
+// Report is the outcome of a check run
+type Report struct {
+	Files []FileResult
+}
+
+// Run loads the files under the given paths along with everything they include and
+// returns the diagnostics for the requested files. Included files outside the paths
+// are used for cross-file resolution but are not reported on.
+func Run(opts Options) (*Report, error) {
+	if len(opts.Paths) == 0 {
+		return nil, fmt.Errorf("no paths to check")
+	}
+
+	configs := config.NewCache()
+	configFor := func(path string) (*config.Config, error) {
+		if opts.Config != nil {
+			return opts.Config, nil
+		}
+		return configs.ForPath(path)
+	}
+
+	// roots anchor include resolution; only directories given as paths are crawled
+	var roots, files []string
+	for _, path := range opts.Paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path %s: %w", path, err)
+		}
+		info, err := os.Stat(abs)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			cfg, err := configFor(abs)
+			if err != nil {
+				return nil, err
+			}
+			roots = append(roots, abs)
+			files = append(files, workspace.FindFrugalFiles([]string{abs}, cfg.Ignore...)...)
+		} else {
+			roots = append(roots, filepath.Dir(abs))
+			files = append(files, abs)
+		}
+	}
+
+	manager, err := document.NewManager()
+	if err != nil {
+		return nil, err
+	}
+	defer manager.Close()
+
+	var mutex sync.Mutex
+	var checked []string
+	workspace.LoadFiles(dedupe(files), opts.Workers, func(doc *document.Document) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if manager.AddDocument(doc) {
+			checked = append(checked, doc.URI)
+		}
+	})
+
+	var includePaths []string
+	for _, dir := range opts.IncludePaths {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include path %s: %w", dir, err)
+		}
+		includePaths = append(includePaths, abs)
+	}
+
+	// Each checked file is reported with its own configuration, and the include paths
+	// of every configuration involved are searched
+	sort.Strings(checked)
+	fileConfigs := make(map[string]*config.Config, len(checked))
+	seenConfigs := make(map[*config.Config]bool)
+	for _, uri := range checked {
+		cfg, err := configFor(strings.TrimPrefix(uri, "file://"))
+		if err != nil {
+			return nil, err
+		}
+		fileConfigs[uri] = cfg
+		if !seenConfigs[cfg] {
+			seenConfigs[cfg] = true
+			includePaths = append(includePaths, cfg.ResolvedIncludePaths()...)
+		}
+	}
+
+	includeResolver := workspace.NewIncludeResolver(roots)
+	includeResolver.SetIncludePaths(includePaths)
+	includeResolver.SetDocumentSource(manager)
+
+	if err := loadIncludes(manager, includeResolver); err != nil {
+		return nil, err
+	}
+	for _, doc := range manager.GetAllDocuments() {
+		if err := includeResolver.UpdateDocument(doc); err != nil {
+			return nil, err
+		}
+	}
+
+	typeResolver := workspace.NewTypeResolver(includeResolver, manager)
+	providers := make(map[*config.Config]*features.DiagnosticsProvider)
+	report := &Report{Files: make([]FileResult, 0, len(checked))}
+	for _, uri := range checked {
+		cfg := fileConfigs[uri]
+		provider, ok := providers[cfg]
+		if !ok {
+			provider = features.NewDiagnosticsProvider()
+			provider.SetLintConfig(cfg.Lint)
+			provider.SetAnnotationSchema(features.NewAnnotationSchema(cfg.Annotations))
+			provider.SetIncludeResolver(includeResolver)
+			provider.SetTypeResolver(typeResolver)
+			providers[cfg] = provider
+		}
+
+		doc, _ := manager.GetDocument(uri)
+		report.Files = append(report.Files, FileResult{
+			Path:        doc.Path,
+			URI:         uri,
+			Diagnostics: provider.ProvideDiagnostics(doc),
+		})
+	}
+
+	return report, nil
+}
+
+// loadIncludes loads included files that are not yet known until every include that
+// resolves has a document
+func loadIncludes(manager *document.Manager, resolver *workspace.IncludeResolver) error {
+	p, err := parser.NewParser()
+	if err != nil {
+		return err
+	}
+	defer p.Close()
+
+	var queue []*document.Document
+	for _, doc := range manager.GetAllDocuments() {
+		queue = append(queue, doc)
+	}
+
+	for len(queue) > 0 {
+		doc := queue[0]
+		queue = queue[1:]
+
+		for _, include := range doc.GetModel().Includes {
+			uri, err := resolver.ResolveInclude(doc.URI, include.Path)
+			if err != nil {
+				continue
+			}
+			if _, exists := manager.GetDocument(uri); exists {
+				continue
+			}
+
+			included, err := document.LoadFile(p, uri, strings.TrimPrefix(uri, "file://"))
+			if err != nil {
+				continue
+			}
+			manager.AddDocument(included)
+			queue = append(queue, included)
+		}
+	}
+
+	return nil
+}
+
+// ErrorCount returns the number of error diagnostics in the report
+func (r *Report) ErrorCount() int {
+	return r.count(protocol.DiagnosticSeverityError)
+}
+
+// WarningCount returns the number of warning diagnostics in the report
+func (r *Report) WarningCount() int {
+	return r.count(protocol.DiagnosticSeverityWarning)
+}
+
+// count returns the number of diagnostics with the given severity
+func (r *Report) count(severity protocol.DiagnosticSeverity) int {
+	n := 0
+	for _, file := range r.Files {
+		for _, diagnostic := range file.Diagnostics {
+			if diagnosticSeverity(diagnostic) == severity {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// diagnosticSeverity returns a diagnostic's severity, treating a missing one as an error
+func diagnosticSeverity(diagnostic protocol.Diagnostic) protocol.DiagnosticSeverity {
+	if diagnostic.Severity == nil {
+		return protocol.DiagnosticSeverityError
+	}
+	return *diagnostic.Severity
+}
+
+// dedupe returns the paths sorted with duplicates removed
+func dedupe(paths []string) []string {
+	sort.Strings(paths)
+	var unique []string
+	for i, path := range paths {
+		if i == 0 || path != paths[i-1] {
+			unique = append(unique, path)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,132 @@
+package check
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles creates files relative to dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"shared/tags.frugal":   "struct Tag {\n    1: string name\n}",
+		"api/user.frugal":      "include \"tags.frugal\"\n\nstruct User {\n    1: tags.Tag tag,\n    2: tags.Missing missing\n}",
+		"api/ok.frugal":        "struct Ok {\n    1: string name\n}",
+		"api/.hidden/x.frugal": "struct {",
+	})
+
+	report, err := Run(Options{
+		Paths:        []string{filepath.Join(dir, "api")},
+		IncludePaths: []string{filepath.Join(dir, "shared")},
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	// Included files outside the checked paths are loaded but not reported
+	if len(report.Files) != 2 {
+		t.Fatalf("Expected 2 checked files, got %+v", report.Files)
+	}
+	if filepath.Base(report.Files[0].Path) != "ok.frugal" || len(report.Files[0].Diagnostics) != 0 {
+		t.Errorf("Expected ok.frugal without diagnostics, got %+v", report.Files[0])
+	}
+
+	user := report.Files[1]
+	if len(user.Diagnostics) != 1 || user.Diagnostics[0].Message != "Unknown type 'tags.Missing'" {
+		t.Errorf("Expected only the missing cross-file type to be reported, got %+v", user.Diagnostics)
+	}
+	if report.ErrorCount() != 1 || report.WarningCount() != 0 {
+		t.Errorf("Expected 1 error and 0 warnings, got %d and %d", report.ErrorCount(), report.WarningCount())
+	}
+}
+
+func TestRunWithConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		".frugal-ls.yaml":        "includePaths: [shared]\nignore: [generated]\nlint:\n  rules:\n    naming-convention: error\n",
+		"shared/tags.frugal":     "struct Tag {\n    1: string name\n}",
+		"api/user.frugal":        "include \"tags.frugal\"\n\nstruct user {\n    1: tags.Tag tag\n}",
+		"api/generated/x.frugal": "struct {",
+	})
+
+	report, err := Run(Options{Paths: []string{filepath.Join(dir, "api")}})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if len(report.Files) != 1 {
+		t.Fatalf("Expected ignored directory to be skipped, got %+v", report.Files)
+	}
+	diagnostics := report.Files[0].Diagnostics
+	if len(diagnostics) != 1 || !strings.Contains(diagnostics[0].Message, "PascalCase") {
+		t.Fatalf("Expected only the naming diagnostic, got %+v", diagnostics)
+	}
+	if report.ErrorCount() != 1 {
+		t.Errorf("Expected naming convention to be reported as an error, got %d errors", report.ErrorCount())
+	}
+}
+
+func TestRunWithConfigPerPath(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a/.frugal-ls.yaml": "lint:\n  rules:\n    naming-convention: off\n",
+		"a/user.frugal":     "struct user {\n    1: string name\n}",
+		"b/.frugal-ls.yaml": "lint:\n  rules:\n    naming-convention: error\n",
+		"b/user.frugal":     "struct user {\n    1: string name\n}",
+	})
+
+	report, err := Run(Options{Paths: []string{filepath.Join(dir, "a"), filepath.Join(dir, "b")}})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if len(report.Files) != 2 {
+		t.Fatalf("Expected 2 files, got %+v", report.Files)
+	}
+	if diagnostics := report.Files[0].Diagnostics; len(diagnostics) != 0 {
+		t.Errorf("Expected a/ to use its own config, got %+v", diagnostics)
+	}
+	if report.ErrorCount() != 1 || len(report.Files[1].Diagnostics) != 1 {
+		t.Errorf("Expected b/ to report its naming error, got %+v", report.Files[1].Diagnostics)
+	}
+}
+
+func TestRunWithoutIncludePaths(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"api/user.frugal": "include \"tags.frugal\"\n\nstruct User {\n    1: string name\n}",
+	})
+
+	report, err := Run(Options{Paths: []string{filepath.Join(dir, "api", "user.frugal")}})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(report.Files) != 1 || !strings.Contains(report.Files[0].Diagnostics[0].Message, "Cannot resolve include") {
+		t.Errorf("Expected unresolved include, got %+v", report.Files)
+	}
+}
+
+func TestRunMissingPath(t *testing.T) {
+	if _, err := Run(Options{Paths: []string{filepath.Join(t.TempDir(), "missing")}}); err == nil {
+		t.Error("Expected error for missing path")
+	}
+	if _, err := Run(Options{}); err == nil {
+		t.Error("Expected error without paths")
+	}
+}
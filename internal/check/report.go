@@ -0,0 +1,260 @@
+package check
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	protocol "github.com/tliron/glsp/protocol_3_16"
+
+	"frugal-ls/internal/features"
+)
+
+// Output formats supported by Write
+const (
+	FormatHuman = "human"
+	FormatJSON  = "json"
+	FormatSARIF = "sarif"
+)
+
+// defaultRuleID is used in SARIF output for diagnostics without a code
+const defaultRuleID = "frugal-ls"
+
+// Write prints the report in the given format. File paths are shown relative to base
+// when they are inside it.
+func Write(w io.Writer, report *Report, format, base, version string) error {
+	switch format {
+	case FormatHuman, "":
+		return writeHuman(w, report, base)
+	case FormatJSON:
+		return writeJSON(w, report, base)
+	case FormatSARIF:
+		return writeSARIF(w, report, base, version)
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// writeHuman prints one line per diagnostic in the file:line:column form editors recognize
+func writeHuman(w io.Writer, report *Report, base string) error {
+	for _, file := range report.Files {
+		path := relativePath(base, file.Path)
+		for _, diagnostic := range file.Diagnostics {
+			start := diagnostic.Range.Start
+			line := fmt.Sprintf("%s:%d:%d: %s: %s", path, start.Line+1, start.Character+1,
+				severityName(diagnostic), diagnostic.Message)
+			if code := diagnosticCode(diagnostic); code != "" {
+				line += fmt.Sprintf(" [%s]", code)
+			}
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "%d error(s), %d warning(s) in %d file(s)\n",
+		report.ErrorCount(), report.WarningCount(), len(report.Files))
+	return err
+}
+
+// jsonDiagnostic is a diagnostic in JSON output, with one-based lines and columns
+type jsonDiagnostic struct {
+	File      string `json:"file"`
+	Line      uint32 `json:"line"`
+	Column    uint32 `json:"column"`
+	EndLine   uint32 `json:"endLine"`
+	EndColumn uint32 `json:"endColumn"`
+	Severity  string `json:"severity"`
+	Code      string `json:"code,omitempty"`
+	Message   string `json:"message"`
+}
+
+// writeJSON prints every diagnostic as a single JSON array
+func writeJSON(w io.Writer, report *Report, base string) error {
+	diagnostics := make([]jsonDiagnostic, 0)
+	for _, file := range report.Files {
+		path := relativePath(base, file.Path)
+		for _, diagnostic := range file.Diagnostics {
+			diagnostics = append(diagnostics, jsonDiagnostic{
+				File:      path,
+				Line:      diagnostic.Range.Start.Line + 1,
+				Column:    diagnostic.Range.Start.Character + 1,
+				EndLine:   diagnostic.Range.End.Line + 1,
+				EndColumn: diagnostic.Range.End.Character + 1,
+				Severity:  severityName(diagnostic),
+				Code:      diagnosticCode(diagnostic),
+				Message:   diagnostic.Message,
+			})
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(diagnostics)
+}
+
+// SARIF 2.1.0 log structure, limited to the properties frugal-ls fills in
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name    string      `json:"name"`
+		Version string      `json:"version,omitempty"`
+		Rules   []sarifRule `json:"rules"`
+	}
+	sarifRule struct {
+		ID               string        `json:"id"`
+		Name             string        `json:"name,omitempty"`
+		ShortDescription *sarifMessage `json:"shortDescription,omitempty"`
+		HelpURI          string        `json:"helpUri,omitempty"`
+	}
+	sarifResult struct {
+		RuleID    string          `json:"ruleId"`
+		Level     string          `json:"level"`
+		Message   sarifMessage    `json:"message"`
+		Locations []sarifLocation `json:"locations"`
+	}
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           sarifRegion           `json:"region"`
+	}
+	sarifArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+	sarifRegion struct {
+		StartLine   uint32 `json:"startLine"`
+		StartColumn uint32 `json:"startColumn"`
+		EndLine     uint32 `json:"endLine"`
+		EndColumn   uint32 `json:"endColumn"`
+	}
+)
+
+// writeSARIF prints the report as a SARIF log for code scanning tools
+func writeSARIF(w io.Writer, report *Report, base, version string) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:    "frugal-ls",
+			Version: version,
+			Rules:   []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+
+	rules := make(map[string]bool)
+	for _, file := range report.Files {
+		uri := filepath.ToSlash(relativePath(base, file.Path))
+		for _, diagnostic := range file.Diagnostics {
+			ruleID := diagnosticCode(diagnostic)
+			if ruleID == "" {
+				ruleID = defaultRuleID
+			}
+			if !rules[ruleID] {
+				rules[ruleID] = true
+				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRuleFor(ruleID))
+			}
+
+			run.Results = append(run.Results, sarifResult{
+				RuleID:  ruleID,
+				Level:   sarifLevel(diagnostic),
+				Message: sarifMessage{Text: diagnostic.Message},
+				Locations: []sarifLocation{{
+					PhysicalLocation: sarifPhysicalLocation{
+						ArtifactLocation: sarifArtifactLocation{URI: uri},
+						Region: sarifRegion{
+							StartLine:   diagnostic.Range.Start.Line + 1,
+							StartColumn: diagnostic.Range.Start.Character + 1,
+							EndLine:     diagnostic.Range.End.Line + 1,
+							EndColumn:   diagnostic.Range.End.Character + 1,
+						},
+					},
+				}},
+			})
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
+
+// sarifRuleFor describes a rule, with its documentation when it is a registered lint rule
+func sarifRuleFor(ruleID string) sarifRule {
+	rule := features.LookupRule(ruleID)
+	if rule == nil {
+		return sarifRule{ID: ruleID}
+	}
+	return sarifRule{
+		ID:               rule.ID,
+		Name:             rule.Name,
+		ShortDescription: &sarifMessage{Text: rule.Description},
+		HelpURI:          rule.DocumentationURL(),
+	}
+}
+
+// severityName returns the lowercase name of a diagnostic's severity
+func severityName(diagnostic protocol.Diagnostic) string {
+	switch diagnosticSeverity(diagnostic) {
+	case protocol.DiagnosticSeverityWarning:
+		return "warning"
+	case protocol.DiagnosticSeverityInformation:
+		return "info"
+	case protocol.DiagnosticSeverityHint:
+		return "hint"
+	default:
+		return "error"
+	}
+}
+
+// sarifLevel maps a diagnostic's severity to a SARIF result level
+func sarifLevel(diagnostic protocol.Diagnostic) string {
+	switch diagnosticSeverity(diagnostic) {
+	case protocol.DiagnosticSeverityError:
+		return "error"
+	case protocol.DiagnosticSeverityWarning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// diagnosticCode returns a diagnostic's code as a string, or "" if it has none
+func diagnosticCode(diagnostic protocol.Diagnostic) string {
+	if diagnostic.Code == nil || diagnostic.Code.Value == nil {
+		return ""
+	}
+	return fmt.Sprint(diagnostic.Code.Value)
+}
+
+// relativePath returns path relative to base if it is inside base
+func relativePath(base, path string) string {
+	if base == "" {
+		return path
+	}
+	rel, err := filepath.Rel(base, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
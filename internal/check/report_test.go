@@ -0,0 +1,110 @@
+package check
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+func testReport() *Report {
+	return &Report{Files: []FileResult{{
+		Path: "/work/api/user.frugal",
+		URI:  "file:///work/api/user.frugal",
+		Diagnostics: []protocol.Diagnostic{
+			{
+				Range: protocol.Range{
+					Start: protocol.Position{Line: 2, Character: 4},
+					End:   protocol.Position{Line: 2, Character: 10},
+				},
+				Severity: &[]protocol.DiagnosticSeverity{protocol.DiagnosticSeverityError}[0],
+				Code:     &protocol.IntegerOrString{Value: "FL004"},
+				Message:  "Cannot resolve include 'tags.frugal'",
+			},
+			{
+				Severity: &[]protocol.DiagnosticSeverity{protocol.DiagnosticSeverityWarning}[0],
+				Message:  "Struct name 'user' should be PascalCase",
+			},
+		},
+	}}}
+}
+
+func TestWriteHuman(t *testing.T) {
+	var out bytes.Buffer
+	if err := Write(&out, testReport(), FormatHuman, "/work", "1.0"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	expected := "api/user.frugal:3:5: error: Cannot resolve include 'tags.frugal' [FL004]\n" +
+		"api/user.frugal:1:1: warning: Struct name 'user' should be PascalCase\n" +
+		"1 error(s), 1 warning(s) in 1 file(s)\n"
+	if out.String() != expected {
+		t.Errorf("Unexpected output:\n%s", out.String())
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var out bytes.Buffer
+	if err := Write(&out, testReport(), FormatJSON, "/other", "1.0"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	var diagnostics []jsonDiagnostic
+	if err := json.Unmarshal(out.Bytes(), &diagnostics); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if len(diagnostics) != 2 {
+		t.Fatalf("Expected 2 diagnostics, got %d", len(diagnostics))
+	}
+	first := diagnostics[0]
+	// Paths outside the base stay absolute
+	if first.File != "/work/api/user.frugal" || first.Line != 3 || first.Column != 5 || first.EndColumn != 11 ||
+		first.Severity != "error" || first.Code != "FL004" {
+		t.Errorf("Unexpected diagnostic: %+v", first)
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	var out bytes.Buffer
+	if err := Write(&out, testReport(), FormatSARIF, "/work", "1.0"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(out.Bytes(), &log); err != nil {
+		t.Fatalf("Invalid SARIF: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("Unexpected SARIF log: %s", out.String())
+	}
+
+	run := log.Runs[0]
+	if run.Tool.Driver.Name != "frugal-ls" || run.Tool.Driver.Version != "1.0" || len(run.Tool.Driver.Rules) != 2 {
+		t.Errorf("Unexpected driver: %+v", run.Tool.Driver)
+	}
+	if rule := run.Tool.Driver.Rules[0]; rule.ID != "FL004" || rule.Name != "unresolved-include" || !strings.HasSuffix(rule.HelpURI, "#fl004") {
+		t.Errorf("Expected rule metadata for FL004, got %+v", rule)
+	}
+	if len(run.Results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(run.Results))
+	}
+	result := run.Results[0]
+	location := result.Locations[0].PhysicalLocation
+	if result.RuleID != "FL004" || result.Level != "error" ||
+		location.ArtifactLocation.URI != "api/user.frugal" || location.Region.StartLine != 3 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if run.Results[1].RuleID != defaultRuleID || run.Results[1].Level != "warning" {
+		t.Errorf("Expected default rule for diagnostic without code, got %+v", run.Results[1])
+	}
+}
+
+func TestWriteUnknownFormat(t *testing.T) {
+	var out bytes.Buffer
+	err := Write(&out, testReport(), "xml", "", "1.0")
+	if err == nil || !strings.Contains(err.Error(), "xml") {
+		t.Errorf("Expected unknown format error, got %v", err)
+	}
+}
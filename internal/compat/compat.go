@@ -0,0 +1,335 @@
+// Package compat detects wire-incompatible changes between two versions of a Frugal IDL.
+package compat
+
+import (
+	"fmt"
+	"strings"
+
+	protocol "github.com/tliron/glsp/protocol_3_16"
+
+	"frugal-ls/pkg/ast"
+)
+
+// Kinds of incompatible change
+const (
+	KindDefinitionRemoved     = "definition-removed"
+	KindDefinitionKindChanged = "definition-kind-changed"
+	KindTypedefChanged        = "typedef-changed"
+	KindFieldIDReused         = "field-id-reused"
+	KindFieldIDChanged        = "field-id-changed"
+	KindFieldTypeChanged      = "field-type-changed"
+	KindRequirednessChanged   = "requiredness-changed"
+	KindRequiredFieldAdded    = "required-field-added"
+	KindRequiredFieldRemoved  = "required-field-removed"
+	KindEnumValueRemoved      = "enum-value-removed"
+	KindEnumValueChanged      = "enum-value-changed"
+	KindMethodRemoved         = "method-removed"
+	KindMethodChanged         = "method-changed"
+	KindEventRemoved          = "event-removed"
+	KindEventTypeChanged      = "event-type-changed"
+	KindScopePrefixChanged    = "scope-prefix-changed"
+)
+
+// Change is a difference between a baseline and the current IDL that breaks existing
+// clients or servers
+type Change struct {
+	Kind string
+	// Definition is the name of the struct, enum, service or scope that changed
+	Definition string
+	Message    string
+	// Range locates the change in the current file. Removals point at the enclosing
+	// definition, or at the start of the file if the definition itself was removed.
+	Range ast.Range
+}
+
+// Diagnostic returns the change as a diagnostic with its kind as the code and data, so
+// the kind survives callers that replace the code with a rule ID. The severity is left
+// for the caller to decide.
+func (c Change) Diagnostic() protocol.Diagnostic {
+	return protocol.Diagnostic{
+		Range: protocol.Range{
+			Start: protocol.Position{Line: uint32(c.Range.Start.Line), Character: uint32(c.Range.Start.Column)},
+			End:   protocol.Position{Line: uint32(c.Range.End.Line), Character: uint32(c.Range.End.Column)},
+		},
+		Code:    &protocol.IntegerOrString{Value: c.Kind},
+		Data:    c.Kind,
+		Source:  &[]string{"frugal-ls"}[0],
+		Message: "Breaking change: " + c.Message,
+	}
+}
+
+// comparison holds the typedefs used to compare types across the two versions
+type comparison struct {
+	baseTypedefs    map[string]string
+	currentTypedefs map[string]string
+	changes         []Change
+}
+
+// Compare returns the incompatible changes from base to current
+func Compare(base, current *ast.File) []Change {
+	c := &comparison{
+		baseTypedefs:    typedefs(base),
+		currentTypedefs: typedefs(current),
+	}
+
+	for _, def := range base.Definitions() {
+		if def.Type == ast.NodeTypeConst {
+			continue // Constants are compiled into generated code, not sent on the wire
+		}
+
+		now := current.FindDefinition(def.Name)
+		if now == nil {
+			c.add(KindDefinitionRemoved, def.Name, ast.Range{}, "%s '%s' was removed", def.Type, def.Name)
+			continue
+		}
+		if now.Type != def.Type {
+			c.add(KindDefinitionKindChanged, def.Name, now.NameRange, "'%s' changed from %s to %s", def.Name, def.Type, now.Type)
+			continue
+		}
+
+		switch def.Type {
+		case ast.NodeTypeTypedef:
+			c.compareTypedef(base.FindTypedef(def.Name), current.FindTypedef(def.Name))
+		case ast.NodeTypeEnum:
+			c.compareEnum(base.FindEnum(def.Name), current.FindEnum(def.Name))
+		case ast.NodeTypeService:
+			c.compareService(base.FindService(def.Name), current.FindService(def.Name))
+		case ast.NodeTypeScope:
+			c.compareScope(base.FindScope(def.Name), current.FindScope(def.Name))
+		default:
+			baseStruct, currentStruct := base.FindStruct(def.Name), current.FindStruct(def.Name)
+			c.compareFields(def.Name, fmt.Sprintf("%s %s", def.Type, def.Name), currentStruct.NameRange, baseStruct.Fields, currentStruct.Fields)
+		}
+	}
+
+	return c.changes
+}
+
+// add records a change
+func (c *comparison) add(kind, definition string, rng ast.Range, format string, args ...any) {
+	c.changes = append(c.changes, Change{
+		Kind:       kind,
+		Definition: definition,
+		Message:    fmt.Sprintf(format, args...),
+		Range:      rng,
+	})
+}
+
+// compareTypedef reports typedefs whose underlying type changed
+func (c *comparison) compareTypedef(base, current *ast.Typedef) {
+	before, after := c.resolve(base.Type, c.baseTypedefs), c.resolve(current.Type, c.currentTypedefs)
+	if before != after {
+		c.add(KindTypedefChanged, current.Name, current.Type.Range, "typedef '%s' changed from %s to %s", current.Name, before, after)
+	}
+}
+
+// compareFields reports incompatible changes to a struct's fields or a method's
+// parameters or exceptions. owner describes the field list in messages.
+func (c *comparison) compareFields(definition, owner string, ownerRange ast.Range, base, current []*ast.Field) {
+	currentByID := make(map[int]*ast.Field)
+	currentByName := make(map[string]*ast.Field)
+	for _, field := range current {
+		if field.HasID {
+			currentByID[field.ID] = field
+		}
+		currentByName[field.Name] = field
+	}
+
+	baseIDs := make(map[int]bool)
+	for _, before := range base {
+		if !before.HasID {
+			continue
+		}
+		baseIDs[before.ID] = true
+
+		after, exists := currentByID[before.ID]
+		if !exists {
+			if moved, ok := currentByName[before.Name]; ok && moved.HasID {
+				c.add(KindFieldIDChanged, definition, moved.IDRange, "field '%s' of %s changed ID from %d to %d",
+					before.Name, owner, before.ID, moved.ID)
+			} else if before.Requiredness == "required" {
+				c.add(KindRequiredFieldRemoved, definition, ownerRange, "required field %d '%s' was removed from %s",
+					before.ID, before.Name, owner)
+			}
+			continue
+		}
+
+		beforeType, afterType := c.resolve(before.Type, c.baseTypedefs), c.resolve(after.Type, c.currentTypedefs)
+		switch {
+		case after.Name != before.Name && beforeType != afterType:
+			c.add(KindFieldIDReused, definition, after.IDRange, "field ID %d of %s was '%s' (%s) and is reused by '%s' (%s)",
+				before.ID, owner, before.Name, beforeType, after.Name, afterType)
+			continue
+		case beforeType != afterType:
+			c.add(KindFieldTypeChanged, definition, typeRange(after), "type of field %d '%s' of %s changed from %s to %s",
+				after.ID, after.Name, owner, beforeType, afterType)
+		}
+
+		if (before.Requiredness == "required") != (after.Requiredness == "required") {
+			c.add(KindRequirednessChanged, definition, after.Range, "field %d '%s' of %s changed from %s to %s",
+				after.ID, after.Name, owner, requiredness(before), requiredness(after))
+		}
+	}
+
+	for _, after := range current {
+		if after.HasID && !baseIDs[after.ID] && after.Requiredness == "required" {
+			c.add(KindRequiredFieldAdded, definition, after.NameRange, "required field %d '%s' was added to %s",
+				after.ID, after.Name, owner)
+		}
+	}
+}
+
+// compareEnum reports removed enum values and values whose number changed
+func (c *comparison) compareEnum(base, current *ast.Enum) {
+	currentValues := enumValues(current)
+	baseValues := enumValues(base)
+
+	for _, value := range base.Values {
+		after, exists := currentValues[value.Name]
+		if !exists {
+			c.add(KindEnumValueRemoved, base.Name, current.NameRange, "value '%s' was removed from enum %s", value.Name, base.Name)
+			continue
+		}
+		if after.number != baseValues[value.Name].number {
+			c.add(KindEnumValueChanged, base.Name, after.value.NameRange, "value '%s' of enum %s changed from %d to %d",
+				value.Name, base.Name, baseValues[value.Name].number, after.number)
+		}
+	}
+}
+
+// compareService reports removed methods and changes to method signatures
+func (c *comparison) compareService(base, current *ast.Service) {
+	currentMethods := make(map[string]*ast.Method)
+	for _, method := range current.Methods {
+		currentMethods[method.Name] = method
+	}
+
+	for _, before := range base.Methods {
+		after, exists := currentMethods[before.Name]
+		if !exists {
+			c.add(KindMethodRemoved, base.Name, current.NameRange, "method '%s' was removed from service %s", before.Name, base.Name)
+			continue
+		}
+
+		name := base.Name + "." + before.Name
+		if before.ReturnType != nil && after.ReturnType != nil {
+			beforeType, afterType := c.resolve(before.ReturnType, c.baseTypedefs), c.resolve(after.ReturnType, c.currentTypedefs)
+			if beforeType != afterType {
+				c.add(KindMethodChanged, base.Name, after.ReturnType.Range, "return type of %s changed from %s to %s", name, beforeType, afterType)
+			}
+		}
+		if before.Oneway != after.Oneway {
+			c.add(KindMethodChanged, base.Name, after.NameRange, "%s changed from %s to %s", name, callStyle(before), callStyle(after))
+		}
+
+		c.compareFields(base.Name, "parameters of "+name, after.NameRange, before.Params, after.Params)
+		c.compareFields(base.Name, "exceptions of "+name, after.NameRange, before.Throws, after.Throws)
+	}
+}
+
+// compareScope reports prefix changes and removed or retyped events
+func (c *comparison) compareScope(base, current *ast.Scope) {
+	if base.Prefix != current.Prefix {
+		rng := current.PrefixRange
+		if current.Prefix == "" {
+			rng = current.NameRange
+		}
+		c.add(KindScopePrefixChanged, base.Name, rng, "prefix of scope %s changed from '%s' to '%s'", base.Name, base.Prefix, current.Prefix)
+	}
+
+	currentEvents := make(map[string]*ast.Event)
+	for _, event := range current.Events {
+		currentEvents[event.Name] = event
+	}
+
+	for _, before := range base.Events {
+		after, exists := currentEvents[before.Name]
+		if !exists {
+			c.add(KindEventRemoved, base.Name, current.NameRange, "event '%s' was removed from scope %s", before.Name, base.Name)
+			continue
+		}
+		if before.Type == nil || after.Type == nil {
+			continue
+		}
+		beforeType, afterType := c.resolve(before.Type, c.baseTypedefs), c.resolve(after.Type, c.currentTypedefs)
+		if beforeType != afterType {
+			c.add(KindEventTypeChanged, base.Name, after.Type.Range, "type of event '%s' of scope %s changed from %s to %s",
+				before.Name, base.Name, beforeType, afterType)
+		}
+	}
+}
+
+// resolve returns a type's text with whitespace removed and local typedefs expanded,
+// so that introducing or inlining an alias is not reported as a change
+func (c *comparison) resolve(ref *ast.TypeRef, aliases map[string]string) string {
+	if ref == nil {
+		return ""
+	}
+	text := strings.Join(strings.Fields(ref.Text), "")
+
+	// Bounded to stop on typedef cycles
+	for i := 0; i < len(aliases); i++ {
+		target, ok := aliases[text]
+		if !ok {
+			break
+		}
+		text = target
+	}
+	return text
+}
+
+// typedefs maps each typedef name in a file to its type's text
+func typedefs(file *ast.File) map[string]string {
+	aliases := make(map[string]string)
+	for _, typedef := range file.Typedefs {
+		if typedef.Type != nil {
+			aliases[typedef.Name] = strings.Join(strings.Fields(typedef.Type.Text), "")
+		}
+	}
+	return aliases
+}
+
+// numberedValue is an enum value with its implicit number filled in
+type numberedValue struct {
+	value  *ast.EnumValue
+	number int
+}
+
+// enumValues returns an enum's values by name, numbering values without an explicit
+// number one past the previous value
+func enumValues(enum *ast.Enum) map[string]numberedValue {
+	values := make(map[string]numberedValue)
+	next := 0
+	for _, value := range enum.Values {
+		if value.HasValue {
+			next = value.Value
+		}
+		values[value.Name] = numberedValue{value: value, number: next}
+		next++
+	}
+	return values
+}
+
+// typeRange returns the range of a field's type, or of the field if it has none
+func typeRange(field *ast.Field) ast.Range {
+	if field.Type != nil {
+		return field.Type.Range
+	}
+	return field.Range
+}
+
+// requiredness describes a field's requiredness for messages
+func requiredness(field *ast.Field) string {
+	if field.Requiredness == "" {
+		return "default"
+	}
+	return field.Requiredness
+}
+
+// callStyle describes whether a method is oneway for messages
+func callStyle(method *ast.Method) string {
+	if method.Oneway {
+		return "oneway"
+	}
+	return "request-response"
+}
@@ -0,0 +1,159 @@
+package compat
+
+import (
+	"strings"
+	"testing"
+
+	"frugal-ls/internal/parser"
+	"frugal-ls/pkg/ast"
+)
+
+// parseSource builds the model for Frugal source
+func parseSource(t *testing.T, source string) *ast.File {
+	t.Helper()
+
+	p, err := parser.NewParser()
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer p.Close()
+
+	file, err := parse(p, []byte(source))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	return file
+}
+
+// kinds returns the kinds of the changes in order
+func kinds(changes []Change) []string {
+	result := make([]string, 0, len(changes))
+	for _, change := range changes {
+		result = append(result, change.Kind)
+	}
+	return result
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		current  string
+		expected []string
+	}{
+		{
+			name:     "compatible additions",
+			base:     "struct User {\n    1: string name\n}",
+			current:  "struct User {\n    1: string name,\n    2: optional i32 age\n}\n\nstruct Extra {\n    1: string x\n}",
+			expected: []string{},
+		},
+		{
+			name:     "removed definition",
+			base:     "struct User {\n    1: string name\n}\n\nstruct Old {\n    1: string x\n}",
+			current:  "struct User {\n    1: string name\n}",
+			expected: []string{KindDefinitionRemoved},
+		},
+		{
+			name:     "removed constant is not reported",
+			base:     "const i32 LIMIT = 10",
+			current:  "",
+			expected: []string{},
+		},
+		{
+			name:     "field type changed",
+			base:     "struct User {\n    1: string name\n}",
+			current:  "struct User {\n    1: i64 name\n}",
+			expected: []string{KindFieldTypeChanged},
+		},
+		{
+			name:     "field ID reused",
+			base:     "struct User {\n    1: string name\n}",
+			current:  "struct User {\n    1: i64 id\n}",
+			expected: []string{KindFieldIDReused},
+		},
+		{
+			name:     "field ID changed",
+			base:     "struct User {\n    1: string name\n}",
+			current:  "struct User {\n    2: string name\n}",
+			expected: []string{KindFieldIDChanged},
+		},
+		{
+			name:     "requiredness",
+			base:     "struct User {\n    1: string name,\n    2: required string email\n}",
+			current:  "struct User {\n    1: required string name,\n    3: required string phone\n}",
+			expected: []string{KindRequirednessChanged, KindRequiredFieldRemoved, KindRequiredFieldAdded},
+		},
+		{
+			name:     "typedef expanded",
+			base:     "typedef string UserID\n\nstruct User {\n    1: UserID id\n}",
+			current:  "typedef string UserID\n\nstruct User {\n    1: string id\n}",
+			expected: []string{},
+		},
+		{
+			name:     "typedef changed",
+			base:     "typedef string UserID",
+			current:  "typedef i64 UserID",
+			expected: []string{KindTypedefChanged},
+		},
+		{
+			name:     "definition kind changed",
+			base:     "struct User {\n    1: string name\n}",
+			current:  "exception User {\n    1: string name\n}",
+			expected: []string{KindDefinitionKindChanged},
+		},
+		{
+			name:     "enum values",
+			base:     "enum Status {\n    ACTIVE,\n    INACTIVE,\n    DELETED\n}",
+			current:  "enum Status {\n    INACTIVE,\n    ACTIVE\n}",
+			expected: []string{KindEnumValueChanged, KindEnumValueChanged, KindEnumValueRemoved},
+		},
+		{
+			name:     "service methods",
+			base:     "service Users {\n    string get(1: i64 id),\n    void ping(),\n    void remove(1: i64 id)\n}",
+			current:  "service Users {\n    i64 get(1: string id),\n    oneway void ping()\n}",
+			expected: []string{KindMethodChanged, KindFieldTypeChanged, KindMethodChanged, KindMethodRemoved},
+		},
+		{
+			name:     "scope events",
+			base:     "scope Events prefix \"v1.{user}\" {\n    Created: string,\n    Deleted: string\n}",
+			current:  "scope Events prefix \"v2.{user}\" {\n    Created: i64\n}",
+			expected: []string{KindScopePrefixChanged, KindEventTypeChanged, KindEventRemoved},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := Compare(parseSource(t, tt.base), parseSource(t, tt.current))
+			if got := kinds(changes); strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+				for _, change := range changes {
+					t.Logf("  %s", change.Message)
+				}
+			}
+		})
+	}
+}
+
+func TestChangeDiagnostic(t *testing.T) {
+	base := parseSource(t, "struct User {\n    1: string name\n}")
+	current := parseSource(t, "struct User {\n    1: i64 name\n}")
+
+	changes := Compare(base, current)
+	if len(changes) != 1 {
+		t.Fatalf("Expected 1 change, got %+v", changes)
+	}
+
+	diagnostic := changes[0].Diagnostic()
+	if diagnostic.Message != "Breaking change: type of field 1 'name' of struct User changed from string to i64" {
+		t.Errorf("Unexpected message %q", diagnostic.Message)
+	}
+	if diagnostic.Code.Value != KindFieldTypeChanged || diagnostic.Data != KindFieldTypeChanged {
+		t.Errorf("Expected code and data %s, got %v and %v", KindFieldTypeChanged, diagnostic.Code.Value, diagnostic.Data)
+	}
+	if diagnostic.Range.Start.Line != 1 || diagnostic.Range.Start.Character != 7 {
+		t.Errorf("Expected the change at the field's type, got %+v", diagnostic.Range)
+	}
+	if diagnostic.Severity != nil {
+		t.Error("Expected severity to be left to the caller")
+	}
+}
@@ -0,0 +1,157 @@
+package compat
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"frugal-ls/internal/parser"
+	"frugal-ls/internal/workspace"
+	"frugal-ls/pkg/ast"
+)
+
+// Snapshot is a version of the IDL files under a directory, keyed by slash-separated
+// path relative to that directory
+type Snapshot struct {
+	Files map[string]*ast.File
+}
+
+// Load reads a baseline for dir. base is a directory holding the old version of dir's
+// files, relative to dir unless absolute, or else a git revision of the repository
+// containing dir.
+func Load(base, dir string, ignore []string) (*Snapshot, error) {
+	baseDir := base
+	if !filepath.IsAbs(baseDir) {
+		baseDir = filepath.Join(dir, base)
+	}
+	if info, err := os.Stat(baseDir); err == nil && info.IsDir() {
+		return LoadDir(baseDir, ignore)
+	}
+	return LoadGitRevision(dir, base, ignore)
+}
+
+// LoadDir parses every .frugal file under dir
+func LoadDir(dir string, ignore []string) (*Snapshot, error) {
+	p, err := parser.NewParser()
+	if err != nil {
+		return nil, err
+	}
+	defer p.Close()
+
+	snapshot := &Snapshot{Files: make(map[string]*ast.File)}
+	for _, path := range workspace.FindFrugalFiles([]string{dir}, ignore...) {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return nil, err
+		}
+		if snapshot.Files[filepath.ToSlash(rel)], err = parse(p, content); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	}
+	return snapshot, nil
+}
+
+// LoadGitRevision parses every .frugal file under dir as of a git revision, leaving out
+// the same files LoadDir does
+func LoadGitRevision(dir, revision string, ignore []string) (*Snapshot, error) {
+	listing, err := git(dir, "ls-tree", "-r", "--name-only", revision, "--", ".")
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := parser.NewParser()
+	if err != nil {
+		return nil, err
+	}
+	defer p.Close()
+
+	snapshot := &Snapshot{Files: make(map[string]*ast.File)}
+	for _, rel := range strings.Split(strings.TrimSpace(string(listing)), "\n") {
+		if !strings.HasSuffix(rel, ".frugal") || workspace.IsIgnored(rel, ignore) {
+			continue
+		}
+		content, err := git(dir, "show", revision+":./"+rel)
+		if err != nil {
+			return nil, err
+		}
+		if snapshot.Files[rel], err = parse(p, content); err != nil {
+			return nil, fmt.Errorf("failed to parse %s at %s: %w", rel, revision, err)
+		}
+	}
+	return snapshot, nil
+}
+
+// git runs a git command in dir and returns its output
+func git(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// parse builds the model for a file's content
+func parse(p *parser.TreeSitterParser, content []byte) (*ast.File, error) {
+	result, err := p.Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	return ast.BuildFile(result.GetRootNode(), content), nil
+}
+
+// Paths returns the snapshot's file paths in order
+func (s *Snapshot) Paths() []string {
+	paths := make([]string, 0, len(s.Files))
+	for path := range s.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// CompareSnapshots returns the incompatible changes for every file in base, keyed by
+// path. Files missing from current report each of their definitions as removed.
+func CompareSnapshots(base, current *Snapshot) map[string][]Change {
+	changes := make(map[string][]Change)
+	for _, path := range base.Paths() {
+		now, exists := current.Files[path]
+		if !exists {
+			now = &ast.File{}
+		}
+		if fileChanges := Compare(base.Files[path], now); len(fileChanges) > 0 {
+			changes[path] = fileChanges
+		}
+	}
+	return changes
+}
+
+// Baseline maps files in a workspace to their version in a snapshot
+type Baseline struct {
+	// Root is the directory the snapshot's paths are relative to
+	Root     string
+	Snapshot *Snapshot
+}
+
+// File returns the baseline version of the file at path, if it has one
+func (b *Baseline) File(path string) (*ast.File, bool) {
+	rel, err := filepath.Rel(b.Root, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return nil, false
+	}
+	file, ok := b.Snapshot.Files[filepath.ToSlash(rel)]
+	return file, ok
+}
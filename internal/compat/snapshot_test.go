@@ -0,0 +1,126 @@
+package compat
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// writeFiles creates files relative to dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"api/user.frugal":        "struct User {\n    1: string name\n}",
+		"api/generated/x.frugal": "struct X {\n    1: string x\n}",
+		"notes.txt":              "not idl",
+	})
+
+	snapshot, err := LoadDir(dir, []string{"generated"})
+	if err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	paths := snapshot.Paths()
+	if len(paths) != 1 || paths[0] != "api/user.frugal" {
+		t.Fatalf("Expected only api/user.frugal, got %v", paths)
+	}
+
+	baseline := &Baseline{Root: dir, Snapshot: snapshot}
+	if file, ok := baseline.File(filepath.Join(dir, "api", "user.frugal")); !ok || file.FindStruct("User") == nil {
+		t.Error("Expected baseline file for api/user.frugal")
+	}
+	if _, ok := baseline.File(filepath.Join(filepath.Dir(dir), "user.frugal")); ok {
+		t.Error("Expected no baseline for files outside the root")
+	}
+}
+
+func TestCompareSnapshots(t *testing.T) {
+	base, current := t.TempDir(), t.TempDir()
+	writeFiles(t, base, map[string]string{
+		"user.frugal":    "struct User {\n    1: string name\n}",
+		"removed.frugal": "enum Status {\n    ACTIVE\n}",
+	})
+	writeFiles(t, current, map[string]string{
+		"user.frugal": "struct User {\n    1: string name\n}",
+		"new.frugal":  "struct New {\n    1: string name\n}",
+	})
+
+	// A relative base is resolved against the directory being compared
+	baseSnapshot, err := Load(filepath.Join("..", filepath.Base(base)), current, nil)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	currentSnapshot, err := LoadDir(current, nil)
+	if err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+
+	changes := CompareSnapshots(baseSnapshot, currentSnapshot)
+	if len(changes) != 1 || len(changes["removed.frugal"]) != 1 || changes["removed.frugal"][0].Kind != KindDefinitionRemoved {
+		t.Errorf("Expected only the removed file's enum to be reported, got %+v", changes)
+	}
+}
+
+func TestLoadGitRevision(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	writeFiles(t, dir, map[string]string{
+		"idl/user.frugal":          "struct User {\n    1: string name\n}",
+		"idl/generated/out.frugal": "struct Out {\n    1: string name\n}",
+		"README.md":                "docs",
+	})
+	run("init", "-q")
+	run("add", ".")
+	run("commit", "-q", "-m", "initial")
+	writeFiles(t, dir, map[string]string{"idl/user.frugal": "struct User {\n    1: i64 name\n}"})
+
+	idl := filepath.Join(dir, "idl")
+	snapshot, err := Load("HEAD", idl, []string{"generated"})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	paths := snapshot.Paths()
+	if len(paths) != 1 || paths[0] != "user.frugal" {
+		t.Fatalf("Expected paths relative to the directory, got %v", paths)
+	}
+
+	current, err := LoadDir(idl, nil)
+	if err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	if changes := CompareSnapshots(snapshot, current); len(changes["user.frugal"]) != 1 {
+		t.Errorf("Expected the field type change, got %+v", changes)
+	}
+
+	if _, err := Load("no-such-revision", idl, nil); err == nil {
+		t.Error("Expected an error for an unknown revision")
+	}
+}
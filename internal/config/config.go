@@ -0,0 +1,362 @@
+// Package config loads the project configuration file shared by the language server
+// and the command line tools.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	protocol "github.com/tliron/glsp/protocol_3_16"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the project configuration file
+const FileName = ".frugal-ls.yaml"
+
+// Config is the project configuration. Unset values keep the built-in defaults.
+type Config struct {
+	Format Format `yaml:"format" json:"format"`
+	Lint   Lint   `yaml:"lint" json:"lint"`
+	Compat Compat `yaml:"compat" json:"compat"`
+	// Annotations declares annotation keys in addition to the built-in ones
+	Annotations Annotations `yaml:"annotations" json:"annotations"`
+	// IncludePaths are extra directories searched for includes, relative to the config file
+	IncludePaths []string `yaml:"includePaths" json:"includePaths"`
+	// Ignore lists directory names or glob patterns that are never searched for files
+	Ignore []string `yaml:"ignore" json:"ignore"`
+
+	// Path is the file the configuration was loaded from, or "" for the defaults
+	Path string `yaml:"-" json:"-"`
+}
+
+// Format configures the formatter
+type Format struct {
+	IndentSize   *int  `yaml:"indentSize" json:"indentSize"`
+	InsertSpaces *bool `yaml:"insertSpaces" json:"insertSpaces"`
+	// MaxLineLength wraps longer method parameter lists one parameter per line; zero
+	// leaves lines as they are
+	MaxLineLength *int `yaml:"maxLineLength" json:"maxLineLength"`
+	// AlignFields lines up the types and names of consecutive fields and the values of
+	// consecutive enum values
+	AlignFields *bool `yaml:"alignFields" json:"alignFields"`
+	// SortImports sorts each block of consecutive includes
+	SortImports *bool `yaml:"sortImports" json:"sortImports"`
+}
+
+// Formatting option keys for the settings the protocol does not define
+const (
+	FormattingOptionMaxLineLength = "maxLineLength"
+	FormattingOptionAlignFields   = "alignFields"
+	FormattingOptionSortImports   = "sortImports"
+)
+
+// Lint configures which lint rules run and how severe their diagnostics are
+type Lint struct {
+	// Rules maps a rule name to its severity, or "off" to disable it
+	Rules map[string]Severity `yaml:"rules" json:"rules"`
+}
+
+// Compat configures breaking-change diagnostics in the editor
+type Compat struct {
+	// Base is the baseline compared against: a directory, relative to the workspace
+	// root unless absolute, or a git revision. Empty disables the comparison.
+	Base string `yaml:"base" json:"base"`
+}
+
+// Merge returns the settings with those set in override taking precedence
+func (c Compat) Merge(override Compat) Compat {
+	if override.Base != "" {
+		c.Base = override.Base
+	}
+	return c
+}
+
+// Annotations maps annotation keys to the values they accept
+type Annotations map[string]Annotation
+
+// Annotation describes a known annotation key
+type Annotation struct {
+	// Type is the type the quoted value must parse as; empty means string
+	Type AnnotationType `yaml:"type" json:"type"`
+	// Values restricts the value to one of a fixed set, if not empty
+	Values []string `yaml:"values" json:"values"`
+	// Optional allows the key to appear without a value
+	Optional    bool   `yaml:"optional" json:"optional"`
+	Description string `yaml:"description" json:"description"`
+}
+
+// AnnotationType is the type of an annotation's value
+type AnnotationType string
+
+// Supported annotation value types. A bool annotation without a value is true.
+const (
+	AnnotationTypeString AnnotationType = "string"
+	AnnotationTypeInt    AnnotationType = "int"
+	AnnotationTypeBool   AnnotationType = "bool"
+)
+
+// Validate returns an error if an annotation has an unknown type
+func (a Annotations) Validate() error {
+	for key, annotation := range a {
+		switch annotation.Type {
+		case "", AnnotationTypeString, AnnotationTypeInt, AnnotationTypeBool:
+		default:
+			return fmt.Errorf("unknown type %q for annotation %s", annotation.Type, key)
+		}
+	}
+	return nil
+}
+
+// Merge returns the annotations with those declared in override taking precedence
+func (a Annotations) Merge(override Annotations) Annotations {
+	merged := make(Annotations, len(a)+len(override))
+	for key, annotation := range a {
+		merged[key] = annotation
+	}
+	for key, annotation := range override {
+		merged[key] = annotation
+	}
+	return merged
+}
+
+// Severity is a configured diagnostic severity
+type Severity string
+
+// Supported severities
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+	SeverityHint    Severity = "hint"
+	SeverityOff     Severity = "off"
+)
+
+// protocolSeverities maps enabled severities to their protocol values
+var protocolSeverities = map[Severity]protocol.DiagnosticSeverity{
+	SeverityError:   protocol.DiagnosticSeverityError,
+	SeverityWarning: protocol.DiagnosticSeverityWarning,
+	SeverityInfo:    protocol.DiagnosticSeverityInformation,
+	SeverityHint:    protocol.DiagnosticSeverityHint,
+}
+
+// Default returns the configuration used when no file is found
+func Default() *Config {
+	return &Config{}
+}
+
+// Load reads a configuration file. Unknown keys and invalid severities are errors so
+// that typos do not silently leave a rule at its default.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := Default()
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+
+	if err := cfg.Lint.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	if err := cfg.Annotations.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+
+	cfg.Path = path
+	return cfg, nil
+}
+
+// Find returns the nearest configuration file in dir or one of its parents, or ""
+func Find(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+
+	for {
+		path := filepath.Join(dir, FileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// Discover loads the nearest configuration file from dir upward, falling back to the
+// defaults when there is none
+func Discover(dir string) (*Config, error) {
+	path := Find(dir)
+	if path == "" {
+		return Default(), nil
+	}
+	return Load(path)
+}
+
+// ForPath discovers the configuration that applies to a file or directory. Paths that
+// are not directories, including glob patterns, are looked up from their parent.
+func ForPath(path string) (*Config, error) {
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		path = filepath.Dir(path)
+	}
+	return Discover(path)
+}
+
+// Cache discovers the configurations for many paths, looking up each directory and
+// loading each file once. Paths under the same configuration file share its Config.
+// A Cache is not safe for concurrent use.
+type Cache struct {
+	dirs  map[string]*Config
+	files map[string]*Config
+}
+
+// NewCache creates an empty cache
+func NewCache() *Cache {
+	return &Cache{
+		dirs:  make(map[string]*Config),
+		files: make(map[string]*Config),
+	}
+}
+
+// ForPath returns the configuration that applies to a file or directory, as ForPath does
+func (c *Cache) ForPath(path string) (*Config, error) {
+	dir := path
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		dir = filepath.Dir(path)
+	}
+	if cfg, ok := c.dirs[dir]; ok {
+		return cfg, nil
+	}
+
+	file := Find(dir)
+	cfg, ok := c.files[file]
+	if !ok {
+		cfg = Default()
+		if file != "" {
+			var err error
+			if cfg, err = Load(file); err != nil {
+				return nil, err
+			}
+		}
+		c.files[file] = cfg
+	}
+	c.dirs[dir] = cfg
+	return cfg, nil
+}
+
+// Dir returns the directory relative paths in the configuration are resolved against
+func (c *Config) Dir() string {
+	if c.Path == "" {
+		return ""
+	}
+	return filepath.Dir(c.Path)
+}
+
+// ResolvedIncludePaths returns the include paths as absolute paths
+func (c *Config) ResolvedIncludePaths() []string {
+	paths := make([]string, 0, len(c.IncludePaths))
+	for _, path := range c.IncludePaths {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(c.Dir(), path)
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// Apply returns options with the configured formatter settings taking precedence
+func (f Format) Apply(options protocol.FormattingOptions) protocol.FormattingOptions {
+	merged := make(protocol.FormattingOptions, len(options)+5)
+	for key, value := range options {
+		merged[key] = value
+	}
+
+	if f.IndentSize != nil {
+		merged[protocol.FormattingOptionTabSize] = *f.IndentSize
+	}
+	if f.InsertSpaces != nil {
+		merged[protocol.FormattingOptionInsertSpaces] = *f.InsertSpaces
+	}
+	if f.MaxLineLength != nil {
+		merged[FormattingOptionMaxLineLength] = *f.MaxLineLength
+	}
+	if f.AlignFields != nil {
+		merged[FormattingOptionAlignFields] = *f.AlignFields
+	}
+	if f.SortImports != nil {
+		merged[FormattingOptionSortImports] = *f.SortImports
+	}
+
+	return merged
+}
+
+// Merge returns the settings with those set in override taking precedence
+func (f Format) Merge(override Format) Format {
+	if override.IndentSize != nil {
+		f.IndentSize = override.IndentSize
+	}
+	if override.InsertSpaces != nil {
+		f.InsertSpaces = override.InsertSpaces
+	}
+	if override.MaxLineLength != nil {
+		f.MaxLineLength = override.MaxLineLength
+	}
+	if override.AlignFields != nil {
+		f.AlignFields = override.AlignFields
+	}
+	if override.SortImports != nil {
+		f.SortImports = override.SortImports
+	}
+	return f
+}
+
+// Validate returns an error if a rule has an unknown severity
+func (l Lint) Validate() error {
+	for rule, severity := range l.Rules {
+		if _, ok := protocolSeverities[severity]; !ok && severity != SeverityOff {
+			return fmt.Errorf("unknown severity %q for rule %s", severity, rule)
+		}
+	}
+	return nil
+}
+
+// Merge returns the rules with those configured in override taking precedence
+func (l Lint) Merge(override Lint) Lint {
+	rules := make(map[string]Severity, len(l.Rules)+len(override.Rules))
+	for rule, severity := range l.Rules {
+		rules[rule] = severity
+	}
+	for rule, severity := range override.Rules {
+		rules[rule] = severity
+	}
+	return Lint{Rules: rules}
+}
+
+// RuleSeverity returns the severity configured for the first of a rule's identifiers
+// that appears in the configuration. configured is false if none do, and enabled is
+// false if the rule is turned off.
+func (l Lint) RuleSeverity(identifiers ...string) (severity protocol.DiagnosticSeverity, configured, enabled bool) {
+	for _, identifier := range identifiers {
+		value, ok := l.Rules[identifier]
+		if !ok {
+			continue
+		}
+		if value == SeverityOff {
+			return 0, true, false
+		}
+		return protocolSeverities[value], true, true
+	}
+	return 0, false, true
+}
@@ -0,0 +1,216 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+func writeConfig(t *testing.T, dir, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, FileName)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := writeConfig(t, dir, `
+format:
+  indentSize: 2
+  insertSpaces: true
+  maxLineLength: 120
+  alignFields: false
+lint:
+  rules:
+    naming-convention: error
+    unknown-type: off
+includePaths:
+  - idl
+  - /opt/frugal
+ignore:
+  - generated
+annotations:
+  retention.days:
+    type: int
+    description: Days to keep the record
+  go.tag: {}
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.Path != path || cfg.Dir() != dir {
+		t.Errorf("Expected config at %s, got %s", path, cfg.Path)
+	}
+	if *cfg.Format.IndentSize != 2 || !*cfg.Format.InsertSpaces || *cfg.Format.MaxLineLength != 120 || *cfg.Format.AlignFields {
+		t.Errorf("Unexpected format settings: %+v", cfg.Format)
+	}
+	if cfg.Format.SortImports != nil {
+		t.Error("Expected unset options to stay nil")
+	}
+
+	includePaths := cfg.ResolvedIncludePaths()
+	if len(includePaths) != 2 || includePaths[0] != filepath.Join(dir, "idl") || includePaths[1] != "/opt/frugal" {
+		t.Errorf("Unexpected include paths: %v", includePaths)
+	}
+	if len(cfg.Ignore) != 1 || cfg.Ignore[0] != "generated" {
+		t.Errorf("Unexpected ignore list: %v", cfg.Ignore)
+	}
+
+	if retention := cfg.Annotations["retention.days"]; retention.Type != AnnotationTypeInt || retention.Description != "Days to keep the record" {
+		t.Errorf("Unexpected retention.days annotation: %+v", retention)
+	}
+	if _, ok := cfg.Annotations["go.tag"]; !ok {
+		t.Error("Expected go.tag to be declared")
+	}
+
+	if severity, configured, enabled := cfg.Lint.RuleSeverity("FL007", "naming-convention"); !configured || !enabled || severity != protocol.DiagnosticSeverityError {
+		t.Errorf("Expected naming-convention to be an error, got %v %v %v", severity, configured, enabled)
+	}
+	if _, configured, enabled := cfg.Lint.RuleSeverity("unknown-type"); !configured || enabled {
+		t.Error("Expected unknown-type to be off")
+	}
+	if _, configured, enabled := cfg.Lint.RuleSeverity("FL001", "duplicate-field-id"); configured || !enabled {
+		t.Error("Expected unconfigured rules to be enabled with their default severity")
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := map[string]string{
+		"unknown key":             "format:\n  indent: 2\n",
+		"invalid severity":        "lint:\n  rules:\n    naming-convention: loud\n",
+		"invalid annotation type": "annotations:\n  retention.days:\n    type: number\n",
+		"invalid yaml":            "format: [",
+	}
+
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := writeConfig(t, t.TempDir(), content)
+			if _, err := Load(path); err == nil || !strings.Contains(err.Error(), path) {
+				t.Errorf("Expected error naming %s, got %v", path, err)
+			}
+		})
+	}
+}
+
+func TestLoadEmpty(t *testing.T) {
+	cfg, err := Load(writeConfig(t, t.TempDir(), ""))
+	if err != nil {
+		t.Fatalf("Expected empty config to load, got %v", err)
+	}
+	if cfg.Format.IndentSize != nil || len(cfg.Lint.Rules) != 0 {
+		t.Errorf("Expected defaults, got %+v", cfg)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+	nested := filepath.Join(dir, "a", "b")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	cfg, err := Discover(nested)
+	if err != nil || cfg.Path != "" {
+		t.Fatalf("Expected defaults without a config file, got %+v, %v", cfg, err)
+	}
+
+	path := writeConfig(t, dir, "ignore: [build]\n")
+	if found := Find(nested); found != path {
+		t.Errorf("Expected to find %s from nested directory, got %q", path, found)
+	}
+
+	file := filepath.Join(nested, "api.frugal")
+	if err := os.WriteFile(file, []byte("struct A {}"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	cfg, err = ForPath(file)
+	if err != nil || cfg.Path != path {
+		t.Errorf("Expected config for file to be %s, got %+v, %v", path, cfg, err)
+	}
+}
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"a/nested", "b"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+	}
+	rootPath := writeConfig(t, dir, "ignore: [build]\n")
+	bPath := writeConfig(t, filepath.Join(dir, "b"), "ignore: [gen]\n")
+
+	cache := NewCache()
+	a, err := cache.ForPath(filepath.Join(dir, "a", "api.frugal"))
+	if err != nil || a.Path != rootPath {
+		t.Fatalf("Expected %s for a, got %+v, %v", rootPath, a, err)
+	}
+	nested, err := cache.ForPath(filepath.Join(dir, "a", "nested"))
+	if err != nil || nested != a {
+		t.Errorf("Expected directories under the same file to share its config, got %+v, %v", nested, err)
+	}
+	b, err := cache.ForPath(filepath.Join(dir, "b", "api.frugal"))
+	if err != nil || b.Path != bPath {
+		t.Errorf("Expected %s for b, got %+v, %v", bPath, b, err)
+	}
+}
+
+func TestFormatApply(t *testing.T) {
+	indent, width, align := 2, 80, false
+	format := Format{IndentSize: &indent, MaxLineLength: &width, AlignFields: &align}
+
+	options := protocol.FormattingOptions{"tabSize": 4, "trimFinalNewlines": true}
+	merged := format.Apply(options)
+
+	if merged["tabSize"] != 2 || merged["trimFinalNewlines"] != true ||
+		merged[FormattingOptionMaxLineLength] != 80 || merged[FormattingOptionAlignFields] != false {
+		t.Errorf("Unexpected merged options: %v", merged)
+	}
+	if options["tabSize"] != 4 {
+		t.Error("Expected the original options to be left unchanged")
+	}
+	if _, ok := merged[FormattingOptionSortImports]; ok {
+		t.Error("Expected unset settings not to be added")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	two, four := 2, 4
+	spaces, align, sorted := false, false, true
+	base := Format{IndentSize: &four, InsertSpaces: &spaces, AlignFields: &align}
+	merged := base.Merge(Format{IndentSize: &two, SortImports: &sorted})
+	if *merged.IndentSize != 2 || merged.InsertSpaces == nil || *merged.InsertSpaces ||
+		merged.AlignFields == nil || *merged.AlignFields || merged.SortImports == nil || !*merged.SortImports {
+		t.Errorf("Unexpected merged format: %+v", merged)
+	}
+	if *base.IndentSize != 4 {
+		t.Error("Expected the base format to be left unchanged")
+	}
+
+	lint := Lint{Rules: map[string]Severity{"naming-convention": SeverityError, "unknown-type": SeverityWarning}}
+	mergedLint := lint.Merge(Lint{Rules: map[string]Severity{"naming-convention": SeverityOff}})
+	if mergedLint.Rules["naming-convention"] != SeverityOff || mergedLint.Rules["unknown-type"] != SeverityWarning {
+		t.Errorf("Unexpected merged rules: %v", mergedLint.Rules)
+	}
+	if lint.Rules["naming-convention"] != SeverityError {
+		t.Error("Expected the base rules to be left unchanged")
+	}
+
+	annotations := Annotations{"owner": {}, "retention.days": {Type: AnnotationTypeString}}
+	mergedAnnotations := annotations.Merge(Annotations{"retention.days": {Type: AnnotationTypeInt}})
+	if len(mergedAnnotations) != 2 || mergedAnnotations["retention.days"].Type != AnnotationTypeInt {
+		t.Errorf("Unexpected merged annotations: %v", mergedAnnotations)
+	}
+	if annotations["retention.days"].Type != AnnotationTypeString {
+		t.Error("Expected the base annotations to be left unchanged")
+	}
+}
@@ -0,0 +1,198 @@
+// Package diff produces unified diffs between two versions of a text.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around each change
+const contextLines = 3
+
+// opKind identifies how a line changed
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+// op is a single line of an edit script with its zero-based positions in both texts
+type op struct {
+	kind opKind
+	line string
+	a, b int
+}
+
+// Unified returns a unified diff from oldText to newText labelled with the given names,
+// or "" if the texts are equal
+func Unified(oldName, newName, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+
+	ops := edits(splitLines(oldText), splitLines(newText))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+
+	for start := 0; start < len(ops); {
+		first := nextChange(ops, start)
+		if first < 0 {
+			break
+		}
+
+		// Changes separated by less than two contexts' worth of lines share a hunk
+		last := first
+		for {
+			next := nextChange(ops, last+1)
+			if next < 0 || next-last > 2*contextLines {
+				break
+			}
+			last = next
+		}
+
+		from := max(first-contextLines, 0)
+		to := min(last+contextLines+1, len(ops))
+		writeHunk(&out, ops[from:to])
+		start = to
+	}
+
+	return out.String()
+}
+
+// nextChange returns the index of the first non-equal op at or after start, or -1
+func nextChange(ops []op, start int) int {
+	for i := start; i < len(ops); i++ {
+		if ops[i].kind != opEqual {
+			return i
+		}
+	}
+	return -1
+}
+
+// writeHunk writes a hunk header followed by its lines
+func writeHunk(out *strings.Builder, ops []op) {
+	oldCount, newCount := 0, 0
+	for _, o := range ops {
+		if o.kind != opInsert {
+			oldCount++
+		}
+		if o.kind != opDelete {
+			newCount++
+		}
+	}
+
+	fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(ops[0].a, oldCount), hunkRange(ops[0].b, newCount))
+	for _, o := range ops {
+		out.WriteByte(byte(o.kind))
+		out.WriteString(o.line)
+		if !strings.HasSuffix(o.line, "\n") {
+			out.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats the one-based start and length of a hunk side. An empty side
+// names the line before it, as diff and patch expect.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// splitLines splits text into lines that keep their trailing newlines
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// edits returns a shortest edit script turning a into b using Myers' algorithm
+func edits(a, b []string) []op {
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+
+	// trace[d] holds v for diagonals -d-1..d+1 as it was before round d
+	var trace [][]int
+	x, y := 0, 0
+
+search:
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+
+		for k := -d; k <= d; k += 2 {
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y = x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// Walk back from the end through each round, collecting ops in reverse
+	var reversed []op
+	x, y = n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		round := trace[d]
+		at := func(k int) int { return round[k+d+1] }
+
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			reversed = append(reversed, op{kind: opEqual, line: a[x]})
+		}
+		if d > 0 {
+			if x == prevX {
+				y--
+				reversed = append(reversed, op{kind: opInsert, line: b[y]})
+			} else {
+				x--
+				reversed = append(reversed, op{kind: opDelete, line: a[x]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	// Restore order and record where each op sits in both texts
+	ops := make([]op, 0, len(reversed))
+	ai, bi := 0, 0
+	for i := len(reversed) - 1; i >= 0; i-- {
+		o := reversed[i]
+		o.a, o.b = ai, bi
+		if o.kind != opInsert {
+			ai++
+		}
+		if o.kind != opDelete {
+			bi++
+		}
+		ops = append(ops, o)
+	}
+	return ops
+}
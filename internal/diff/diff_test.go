@@ -0,0 +1,77 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedEqual(t *testing.T) {
+	if out := Unified("a", "b", "same\n", "same\n"); out != "" {
+		t.Errorf("Expected no diff for equal texts, got %q", out)
+	}
+}
+
+func TestUnified(t *testing.T) {
+	testCases := []struct {
+		name     string
+		old      string
+		new      string
+		expected string
+	}{
+		{
+			name: "single change",
+			old:  "a\nb\nc\n",
+			new:  "a\nB\nc\n",
+			expected: "--- old\n+++ new\n@@ -1,3 +1,3 @@\n" +
+				" a\n-b\n+B\n c\n",
+		},
+		{
+			name: "insert into empty",
+			old:  "",
+			new:  "a\n",
+			expected: "--- old\n+++ new\n@@ -0,0 +1 @@\n" +
+				"+a\n",
+		},
+		{
+			name: "missing final newline",
+			old:  "a\nb",
+			new:  "a\nb\n",
+			expected: "--- old\n+++ new\n@@ -1,2 +1,2 @@\n" +
+				" a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+		{
+			name: "separate hunks",
+			old:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			new:  "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n",
+			expected: "--- old\n+++ new\n" +
+				"@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n" +
+				"@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+twelve\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if out := Unified("old", "new", tc.old, tc.new); out != tc.expected {
+				t.Errorf("Unexpected diff:\n%s\nExpected:\n%s", out, tc.expected)
+			}
+		})
+	}
+}
+
+func TestEditsRoundTrip(t *testing.T) {
+	a := splitLines("struct A {\n  1: i32 x\n}\n\nstruct B {\n  1: i32 y\n}\n")
+	b := splitLines("struct A {\n    1: i32 x\n}\n\nstruct C {\n}\nstruct B {\n    1: i32 y\n}\n")
+
+	var oldText, newText strings.Builder
+	for _, o := range edits(a, b) {
+		if o.kind != opInsert {
+			oldText.WriteString(o.line)
+		}
+		if o.kind != opDelete {
+			newText.WriteString(o.line)
+		}
+	}
+	if oldText.String() != strings.Join(a, "") || newText.String() != strings.Join(b, "") {
+		t.Errorf("Edit script does not reproduce both texts")
+	}
+}
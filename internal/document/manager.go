@@ -3,11 +3,13 @@ package document
 import (
 	"fmt"
 	"net/url"
+	"os"
 	"path/filepath"
 	"strings"
 	"sync"
 
 	protocol "github.com/tliron/glsp/protocol_3_16"
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
 
 	"frugal-ls/internal/parser"
 	"frugal-ls/pkg/ast"
@@ -36,6 +38,7 @@ type Document struct {
 	// Cached parsing results
 	ParseResult *parser.ParseResult
 	Symbols     []ast.Symbol
+	Model       *ast.File
 
 	// Mutex for thread-safe access
 	mutex sync.RWMutex
@@ -44,8 +47,10 @@ type Document struct {
 // Manager handles document lifecycle and caching
 type Manager struct {
 	documents map[string]*Document
-	parser    *parser.TreeSitterParser
-	mutex     sync.RWMutex
+	// open tracks documents owned by the editor; the rest were loaded from disk
+	open   map[string]bool
+	parser *parser.TreeSitterParser
+	mutex  sync.RWMutex
 }
 
 // NewManager creates a new document manager
@@ -57,6 +62,7 @@ func NewManager() (*Manager, error) {
 
 	return &Manager{
 		documents: make(map[string]*Document),
+		open:      make(map[string]bool),
 		parser:    p,
 	}, nil
 }
@@ -107,7 +113,11 @@ func (m *Manager) DidOpen(params *protocol.DidOpenTextDocumentParams) (*Document
 	}
 
 	m.mutex.Lock()
+	if existing, exists := m.documents[uri]; exists {
+		existing.closeParseResult()
+	}
 	m.documents[uri] = doc
+	m.open[uri] = true
 	m.mutex.Unlock()
 
 	return doc, nil
@@ -129,6 +139,9 @@ func (m *Manager) DidChange(params *protocol.DidChangeTextDocumentParams) (*Docu
 	doc.mutex.Lock()
 	defer doc.mutex.Unlock()
 
+	// The old tree can only be reused if every change was recorded as an edit
+	incremental := doc.ParseResult != nil
+
 	// Apply content changes
 	for _, change := range params.ContentChanges {
 		// Cast the change to the proper type
@@ -140,6 +153,7 @@ func (m *Manager) DidChange(params *protocol.DidChangeTextDocumentParams) (*Docu
 		if textChange.Range == nil {
 			// Full document update
 			doc.Content = []byte(textChange.Text)
+			incremental = false
 		} else {
 			// Incremental update - apply the change to the existing content
 			if err := m.applyIncrementalChange(doc, &textChange); err != nil {
@@ -156,20 +170,30 @@ func (m *Manager) DidChange(params *protocol.DidChangeTextDocumentParams) (*Docu
 
 				// Only do full replacement if the text looks like a complete document
 				doc.Content = []byte(textChange.Text)
+				incremental = false
 			}
 		}
 	}
 
 	doc.Version = version
 
+	old := doc.ParseResult
+	doc.ParseResult = nil
+
+	// Re-parse the document, reusing the edited tree when possible
+	var err error
+	if incremental {
+		err = reparseWith(m.parser, doc, old)
+	} else {
+		err = m.parseDocument(doc)
+	}
+
 	// Close old parse result
-	if doc.ParseResult != nil {
-		doc.ParseResult.Close()
-		doc.ParseResult = nil
+	if old != nil {
+		old.Close()
 	}
 
-	// Re-parse the document
-	if err := m.parseDocument(doc); err != nil {
+	if err != nil {
 		return nil, fmt.Errorf("failed to re-parse document: %w", err)
 	}
 
@@ -189,16 +213,55 @@ func (m *Manager) DidClose(params *protocol.DidCloseTextDocumentParams) error {
 	}
 
 	// Clean up resources
-	doc.mutex.Lock()
-	if doc.ParseResult != nil {
-		doc.ParseResult.Close()
-	}
-	doc.mutex.Unlock()
+	doc.closeParseResult()
 
 	delete(m.documents, uri)
+	delete(m.open, uri)
 	return nil
 }
 
+// AddDocument tracks a document loaded from disk. Documents open in the editor take
+// precedence, so it returns false without replacing them.
+func (m *Manager) AddDocument(doc *Document) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.open[doc.URI] {
+		return false
+	}
+
+	if existing, exists := m.documents[doc.URI]; exists {
+		existing.closeParseResult()
+	}
+	m.documents[doc.URI] = doc
+	return true
+}
+
+// RemoveDocument stops tracking a document loaded from disk. Open documents are kept
+// since the editor owns their contents; it returns false in that case.
+func (m *Manager) RemoveDocument(uri string) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.open[uri] {
+		return false
+	}
+
+	if doc, exists := m.documents[uri]; exists {
+		doc.closeParseResult()
+		delete(m.documents, uri)
+	}
+	return true
+}
+
+// IsOpen reports whether the document is currently open in the editor
+func (m *Manager) IsOpen(uri string) bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.open[uri]
+}
+
 // GetDocument retrieves a document by URI
 func (m *Manager) GetDocument(uri string) (*Document, bool) {
 	m.mutex.RLock()
@@ -254,6 +317,11 @@ func (m *Manager) applyIncrementalChange(doc *Document, change *protocol.TextDoc
 		return fmt.Errorf("end character %d out of bounds (0-%d)", endChar, len(lines[endLine]))
 	}
 
+	// Record the edit against the current tree so the reparse can reuse it
+	if doc.ParseResult != nil {
+		doc.ParseResult.Edit(inputEdit(lines, startLine, startChar, endLine, endChar, change.Text))
+	}
+
 	var result strings.Builder
 
 	// Add lines before the change
@@ -281,28 +349,106 @@ func (m *Manager) applyIncrementalChange(doc *Document, change *protocol.TextDoc
 	return nil
 }
 
+// inputEdit describes a change to the given lines as a tree-sitter edit
+func inputEdit(lines []string, startLine, startChar, endLine, endChar int, text string) *tree_sitter.InputEdit {
+	offset := func(line, char int) uint {
+		n := char
+		for i := 0; i < line; i++ {
+			n += len(lines[i]) + 1
+		}
+		return uint(n)
+	}
+
+	start := offset(startLine, startChar)
+	newEnd := tree_sitter.Point{Row: uint(startLine), Column: uint(startChar)}
+	if idx := strings.LastIndex(text, "\n"); idx >= 0 {
+		newEnd.Row += uint(strings.Count(text, "\n"))
+		newEnd.Column = uint(len(text) - idx - 1)
+	} else {
+		newEnd.Column += uint(len(text))
+	}
+
+	return &tree_sitter.InputEdit{
+		StartByte:      start,
+		OldEndByte:     offset(endLine, endChar),
+		NewEndByte:     start + uint(len(text)),
+		StartPosition:  tree_sitter.Point{Row: uint(startLine), Column: uint(startChar)},
+		OldEndPosition: tree_sitter.Point{Row: uint(endLine), Column: uint(endChar)},
+		NewEndPosition: newEnd,
+	}
+}
+
 // parseDocument parses a document and updates its cached results
 func (m *Manager) parseDocument(doc *Document) error {
+	return parseWith(m.parser, doc)
+}
+
+// LoadFile reads and parses a file from disk with the given parser. The returned
+// document is not tracked until it is passed to Manager.AddDocument.
+func LoadFile(p *parser.TreeSitterParser, uri, path string) (*Document, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	doc := &Document{
+		URI:     uri,
+		Path:    path,
+		Content: content,
+	}
+
+	if err := parseWith(p, doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	return doc, nil
+}
+
+// parseWith parses a document with the given parser and updates its cached results
+func parseWith(p *parser.TreeSitterParser, doc *Document) error {
 	// Only parse .frugal files
 	if !strings.HasSuffix(doc.Path, ".frugal") {
 		return nil
 	}
 
-	result, err := m.parser.Parse(doc.Content)
+	result, err := p.Parse(doc.Content)
+	if err != nil {
+		return err
+	}
+
+	setParseResult(doc, result)
+	return nil
+}
+
+// reparseWith reparses a document from an old result whose tree has been edited to
+// match the document's content
+func reparseWith(p *parser.TreeSitterParser, doc *Document, old *parser.ParseResult) error {
+	if !strings.HasSuffix(doc.Path, ".frugal") {
+		return nil
+	}
+
+	result, err := p.Reparse(doc.Content, old)
 	if err != nil {
 		return err
 	}
 
-	// Extract symbols
+	setParseResult(doc, result)
+	return nil
+}
+
+// setParseResult stores a parse result and the symbols and model derived from it
+func setParseResult(doc *Document, result *parser.ParseResult) {
+	// Extract symbols and build the typed model
 	var symbols []ast.Symbol
+	model := &ast.File{}
 	if result.GetRootNode() != nil {
 		symbols = ast.ExtractSymbols(result.GetRootNode(), doc.Content)
+		model = ast.BuildFile(result.GetRootNode(), doc.Content)
 	}
 
 	doc.ParseResult = result
 	doc.Symbols = symbols
-
-	return nil
+	doc.Model = model
 }
 
 // GetDiagnostics provides comprehensive diagnostics including parse errors and semantic validation
@@ -350,6 +496,17 @@ func (d *Document) getBasicParseErrorDiagnostics() []protocol.Diagnostic {
 	return diagnostics
 }
 
+// closeParseResult releases the document's parse tree
+func (d *Document) closeParseResult() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.ParseResult != nil {
+		d.ParseResult.Close()
+		d.ParseResult = nil
+	}
+}
+
 // GetSymbols returns the cached symbols for the document
 func (d *Document) GetSymbols() []ast.Symbol {
 	d.mutex.RLock()
@@ -358,6 +515,22 @@ func (d *Document) GetSymbols() []ast.Symbol {
 	return d.Symbols
 }
 
+// GetModel returns the typed model for the document, building it on demand
+// for documents that were constructed without going through the manager
+func (d *Document) GetModel() *ast.File {
+	d.mutex.RLock()
+	model := d.Model
+	d.mutex.RUnlock()
+	if model != nil {
+		return model
+	}
+
+	if d.ParseResult == nil || d.ParseResult.GetRootNode() == nil {
+		return &ast.File{}
+	}
+	return ast.BuildFile(d.ParseResult.GetRootNode(), d.Content)
+}
+
 // IsValidFrugalFile checks if the document is a .frugal file
 func (d *Document) IsValidFrugalFile() bool {
 	return strings.HasSuffix(d.Path, ".frugal") ||
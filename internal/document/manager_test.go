@@ -1,6 +1,8 @@
 package document
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -653,3 +655,206 @@ func TestGetBasicParseErrorDiagnosticsNilParseResult(t *testing.T) {
 		t.Errorf("Expected empty diagnostics array, got %d diagnostics", len(diagnostics))
 	}
 }
+
+func TestDocumentModelBuiltOnParse(t *testing.T) {
+	manager, err := NewManager()
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	defer manager.Close()
+
+	doc, err := manager.DidOpen(&protocol.DidOpenTextDocumentParams{
+		TextDocument: protocol.TextDocumentItem{
+			URI:        testURI,
+			LanguageID: "frugal",
+			Version:    1,
+			Text:       testUserStruct,
+		},
+	})
+	if err != nil {
+		t.Fatalf("DidOpen failed: %v", err)
+	}
+
+	model := doc.GetModel()
+	user := model.FindStruct("User")
+	if user == nil {
+		t.Fatal("Expected User struct in model")
+	}
+	if len(user.Fields) != 2 || user.Fields[1].Name != "id" || user.Fields[1].Type.Name != "i64" {
+		t.Errorf("Unexpected fields in model: %+v", user.Fields)
+	}
+}
+
+func TestManagerAddDocument(t *testing.T) {
+	manager, err := NewManager()
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	defer manager.Close()
+
+	path := filepath.Join(t.TempDir(), "common.frugal")
+	if err := os.WriteFile(path, []byte("struct Disk {\n    1: i32 x\n}"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	uri := "file://" + path
+
+	diskDoc, err := LoadFile(manager.parser, uri, path)
+	if err != nil {
+		t.Fatalf("Failed to load file: %v", err)
+	}
+	if !manager.AddDocument(diskDoc) {
+		t.Fatal("Expected document loaded from disk to be tracked")
+	}
+	if manager.IsOpen(uri) {
+		t.Error("Document loaded from disk should not be open")
+	}
+
+	_, err = manager.DidOpen(&protocol.DidOpenTextDocumentParams{
+		TextDocument: protocol.TextDocumentItem{URI: uri, LanguageID: "frugal", Version: 1, Text: "struct Editor {}"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to open document: %v", err)
+	}
+	if !manager.IsOpen(uri) {
+		t.Error("Expected document to be open")
+	}
+
+	// The editor's copy takes precedence over the disk copy
+	reloaded, err := LoadFile(manager.parser, uri, path)
+	if err != nil {
+		t.Fatalf("Failed to reload file: %v", err)
+	}
+	if manager.AddDocument(reloaded) {
+		t.Error("Expected open document not to be replaced")
+	}
+	doc, _ := manager.GetDocument(uri)
+	if doc.GetModel().FindStruct("Editor") == nil {
+		t.Error("Expected editor contents to be kept")
+	}
+	reloaded.ParseResult.Close()
+
+	if err := manager.DidClose(&protocol.DidCloseTextDocumentParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+	}); err != nil {
+		t.Fatalf("Failed to close document: %v", err)
+	}
+	if manager.IsOpen(uri) {
+		t.Error("Expected document to be closed")
+	}
+}
+
+func TestLoadFileMissing(t *testing.T) {
+	manager, err := NewManager()
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	defer manager.Close()
+
+	if _, err := LoadFile(manager.parser, "file:///missing.frugal", "/missing.frugal"); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
+
+func TestManagerRemoveDocument(t *testing.T) {
+	manager, err := NewManager()
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	defer manager.Close()
+
+	_, err = manager.DidOpen(&protocol.DidOpenTextDocumentParams{
+		TextDocument: protocol.TextDocumentItem{URI: testURI, LanguageID: "frugal", Version: 1, Text: testUserStruct},
+	})
+	if err != nil {
+		t.Fatalf("Failed to open document: %v", err)
+	}
+
+	if manager.RemoveDocument(testURI) {
+		t.Error("Expected open document not to be removed")
+	}
+	if _, exists := manager.GetDocument(testURI); !exists {
+		t.Error("Expected open document to remain tracked")
+	}
+
+	if !manager.RemoveDocument("file:///unknown.frugal") {
+		t.Error("Expected removing an untracked document to succeed")
+	}
+}
+
+func TestDocumentDidChangeReusesTree(t *testing.T) {
+	manager, err := NewManager()
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	defer manager.Close()
+
+	content := testUserStruct + "\n\nstruct Account {\n    1: User owner\n}"
+	doc, err := manager.DidOpen(&protocol.DidOpenTextDocumentParams{
+		TextDocument: protocol.TextDocumentItem{URI: testURI, LanguageID: "frugal", Version: 1, Text: content},
+	})
+	if err != nil {
+		t.Fatalf("DidOpen failed: %v", err)
+	}
+	if doc.ParseResult.ChangedRanges != nil {
+		t.Errorf("Expected no changed ranges after open, got %+v", doc.ParseResult.ChangedRanges)
+	}
+
+	change := func(version int32, changes ...any) {
+		t.Helper()
+		if _, err := manager.DidChange(&protocol.DidChangeTextDocumentParams{
+			TextDocument: protocol.VersionedTextDocumentIdentifier{
+				TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: testURI},
+				Version:                version,
+			},
+			ContentChanges: changes,
+		}); err != nil {
+			t.Fatalf("DidChange failed: %v", err)
+		}
+	}
+
+	// Rename the owner field and add a second field in one notification
+	change(2,
+		protocol.TextDocumentContentChangeEvent{
+			Range: &protocol.Range{Start: protocol.Position{Line: 6, Character: 12}, End: protocol.Position{Line: 6, Character: 17}},
+			Text:  "admin",
+		},
+		protocol.TextDocumentContentChangeEvent{
+			Range: &protocol.Range{Start: protocol.Position{Line: 6, Character: 17}, End: protocol.Position{Line: 6, Character: 17}},
+			Text:  ",\n    2: i64 created",
+		},
+	)
+
+	expected := testUserStruct + "\n\nstruct Account {\n    1: User admin,\n    2: i64 created\n}"
+	if string(doc.Content) != expected {
+		t.Fatalf("Unexpected content:\n%q", string(doc.Content))
+	}
+
+	ranges := doc.ParseResult.ChangedRanges
+	if len(ranges) == 0 {
+		t.Fatal("Expected changed ranges after an incremental change")
+	}
+	for _, r := range ranges {
+		if r.StartPoint.Row < 6 || r.EndPoint.Row > 7 {
+			t.Errorf("Expected changes limited to lines 6-7, got %+v", r)
+		}
+	}
+
+	// The reused tree must match a fresh parse of the same content
+	fresh, err := manager.parser.Parse(doc.Content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	defer fresh.Close()
+	if got, want := doc.ParseResult.GetRootNode().ToSexp(), fresh.GetRootNode().ToSexp(); got != want {
+		t.Errorf("Incremental tree differs from fresh parse:\n%s\n%s", got, want)
+	}
+	if account := doc.GetModel().FindStruct("Account"); account == nil || len(account.Fields) != 2 {
+		t.Errorf("Expected Account with 2 fields in model, got %+v", account)
+	}
+
+	// A full replacement cannot reuse the tree
+	change(3, protocol.TextDocumentContentChangeEvent{Text: testUserStructShort})
+	if doc.ParseResult.ChangedRanges != nil {
+		t.Errorf("Expected no changed ranges after a full replacement, got %+v", doc.ParseResult.ChangedRanges)
+	}
+}
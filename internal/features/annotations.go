@@ -0,0 +1,196 @@
+package features
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"frugal-ls/internal/config"
+	"frugal-ls/pkg/ast"
+)
+
+// builtinAnnotations are the annotation keys known without configuration
+var builtinAnnotations = config.Annotations{
+	"deprecated": {
+		Type:        config.AnnotationTypeString,
+		Optional:    true,
+		Description: "Marks the declaration as deprecated. The value, if any, explains what to use instead; \"true\" deprecates without a message and \"false\" does not deprecate.",
+	},
+	"go.name": {
+		Type:        config.AnnotationTypeString,
+		Description: "Name of the generated Go identifier.",
+	},
+	"go.tag": {
+		Type:        config.AnnotationTypeString,
+		Description: "Struct tag added to the generated Go field, e.g. `json:\"id,omitempty\"`.",
+	},
+	"java.name": {
+		Type:        config.AnnotationTypeString,
+		Description: "Name of the generated Java identifier.",
+	},
+	"python.immutable": {
+		Type:        config.AnnotationTypeBool,
+		Description: "Generates an immutable Python class.",
+	},
+}
+
+// AnnotationSchema is the set of annotation keys known to diagnostics, completion and
+// hover: the built-in keys plus those declared in the configuration. A nil schema knows
+// only the built-in keys.
+type AnnotationSchema struct {
+	configured config.Annotations
+	mutex      sync.RWMutex
+}
+
+// NewAnnotationSchema creates a schema with the given configured annotations
+func NewAnnotationSchema(configured config.Annotations) *AnnotationSchema {
+	return &AnnotationSchema{configured: configured}
+}
+
+// SetAnnotations replaces the configured annotations
+func (s *AnnotationSchema) SetAnnotations(configured config.Annotations) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.configured = configured
+}
+
+// Lookup returns the description of an annotation key. Configured keys take precedence
+// over the built-in ones.
+func (s *AnnotationSchema) Lookup(key string) (config.Annotation, bool) {
+	if s != nil {
+		s.mutex.RLock()
+		defer s.mutex.RUnlock()
+
+		if annotation, ok := s.configured[key]; ok {
+			return annotation, true
+		}
+	}
+	annotation, ok := builtinAnnotations[key]
+	return annotation, ok
+}
+
+// Keys returns every known annotation key in sorted order
+func (s *AnnotationSchema) Keys() []string {
+	keys := make([]string, 0, len(builtinAnnotations))
+	for key := range builtinAnnotations {
+		keys = append(keys, key)
+	}
+	if s != nil {
+		s.mutex.RLock()
+		for key := range s.configured {
+			if _, ok := builtinAnnotations[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		s.mutex.RUnlock()
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// annotationType returns the value type of an annotation, defaulting to string
+func annotationType(annotation config.Annotation) config.AnnotationType {
+	if annotation.Type == "" {
+		return config.AnnotationTypeString
+	}
+	return annotation.Type
+}
+
+// annotationValues returns the values an annotation accepts, or nil if any value of its
+// type is accepted
+func annotationValues(annotation config.Annotation) []string {
+	if len(annotation.Values) > 0 {
+		return annotation.Values
+	}
+	if annotationType(annotation) == config.AnnotationTypeBool {
+		return []string{"true", "false"}
+	}
+	return nil
+}
+
+// validateAnnotation returns why an annotation's value does not match its description,
+// or "" if it does
+func validateAnnotation(annotation *ast.Annotation, known config.Annotation) string {
+	valueType := annotationType(known)
+	if !annotation.HasValue {
+		if known.Optional || valueType == config.AnnotationTypeBool {
+			return ""
+		}
+		return fmt.Sprintf("Annotation '%s' requires a %s value", annotation.Key, valueType)
+	}
+
+	if valueType == config.AnnotationTypeInt {
+		if _, err := strconv.Atoi(annotation.Value); err != nil {
+			return fmt.Sprintf("Annotation '%s' expects an integer, got \"%s\"", annotation.Key, annotation.Value)
+		}
+	}
+	if values := annotationValues(known); len(values) > 0 {
+		for _, value := range values {
+			if annotation.Value == value {
+				return ""
+			}
+		}
+		return fmt.Sprintf("Annotation '%s' expects one of %s, got \"%s\"", annotation.Key, strings.Join(values, ", "), annotation.Value)
+	}
+	return ""
+}
+
+// formatAnnotationInfo describes an annotation key for hover and completion
+func formatAnnotationInfo(key string, annotation config.Annotation) string {
+	var info strings.Builder
+	info.WriteString(fmt.Sprintf("**Annotation**: `%s`\n\n", key))
+	if annotation.Description != "" {
+		info.WriteString(annotation.Description + "\n\n")
+	}
+
+	valueType := string(annotationType(annotation))
+	if annotation.Optional {
+		valueType += ", optional"
+	}
+	info.WriteString(fmt.Sprintf("**Value**: %s", valueType))
+	if values := annotationValues(annotation); len(values) > 0 {
+		info.WriteString(" (`" + strings.Join(values, "`, `") + "`)")
+	}
+	return info.String()
+}
+
+// declarationAnnotations returns the annotation list of every declaration in a file
+func declarationAnnotations(model *ast.File) [][]*ast.Annotation {
+	var lists [][]*ast.Annotation
+	addFields := func(fields []*ast.Field) {
+		for _, field := range fields {
+			lists = append(lists, field.Annotations)
+		}
+	}
+
+	for _, typedef := range model.Typedefs {
+		lists = append(lists, typedef.Annotations)
+	}
+	for _, enum := range model.Enums {
+		lists = append(lists, enum.Annotations)
+		for _, value := range enum.Values {
+			lists = append(lists, value.Annotations)
+		}
+	}
+	for _, structDef := range model.Structs {
+		lists = append(lists, structDef.Annotations)
+		addFields(structDef.Fields)
+	}
+	for _, service := range model.Services {
+		lists = append(lists, service.Annotations)
+		for _, method := range service.Methods {
+			lists = append(lists, method.Annotations)
+			addFields(method.Params)
+			addFields(method.Throws)
+		}
+	}
+	for _, scope := range model.Scopes {
+		for _, event := range scope.Events {
+			lists = append(lists, event.Annotations)
+		}
+	}
+	return lists
+}
@@ -0,0 +1,81 @@
+package features
+
+import (
+	"testing"
+
+	protocol "github.com/tliron/glsp/protocol_3_16"
+
+	"frugal-ls/internal/config"
+)
+
+func TestAnnotationDiagnostics(t *testing.T) {
+	content := `struct User {
+    1: string id (go.tag = "json:\"id\"", owner = "accounts"),
+    2: i32 age (retention.days = "thirty", go.name),
+    3: bool active (python.immutable = "yes", deprecated, deprecated)
+}`
+
+	doc := createTestDocumentForDiagnostics(t, "file:///test.frugal", content)
+	defer doc.ParseResult.Close()
+
+	provider := NewDiagnosticsProvider()
+	provider.SetAnnotationSchema(NewAnnotationSchema(config.Annotations{
+		"retention.days": {Type: config.AnnotationTypeInt},
+	}))
+
+	var annotationDiagnostics []protocol.Diagnostic
+	for _, diagnostic := range provider.ProvideDiagnostics(doc) {
+		if diagnostic.Code.Value == "FL016" {
+			annotationDiagnostics = append(annotationDiagnostics, diagnostic)
+		}
+	}
+
+	expected := []struct {
+		message   string
+		severity  protocol.DiagnosticSeverity
+		line      uint32
+		character uint32
+	}{
+		{"Unknown annotation 'owner'", protocol.DiagnosticSeverityWarning, 1, 42},
+		{`Annotation 'retention.days' expects an integer, got "thirty"`, protocol.DiagnosticSeverityError, 2, 33},
+		{"Annotation 'go.name' requires a string value", protocol.DiagnosticSeverityError, 2, 43},
+		{`Annotation 'python.immutable' expects one of true, false, got "yes"`, protocol.DiagnosticSeverityError, 3, 39},
+		{"Duplicate annotation 'deprecated'", protocol.DiagnosticSeverityError, 3, 58},
+	}
+	if len(annotationDiagnostics) != len(expected) {
+		t.Fatalf("Expected %d annotation diagnostics, got %+v", len(expected), annotationDiagnostics)
+	}
+	for i, want := range expected {
+		got := annotationDiagnostics[i]
+		if got.Message != want.message || *got.Severity != want.severity ||
+			got.Range.Start.Line != want.line || got.Range.Start.Character != want.character {
+			t.Errorf("Expected %q (%v) at %d:%d, got %q (%v) at %d:%d", want.message, want.severity, want.line, want.character,
+				got.Message, *got.Severity, got.Range.Start.Line, got.Range.Start.Character)
+		}
+	}
+}
+
+func TestAnnotationSchema(t *testing.T) {
+	var unconfigured *AnnotationSchema
+	if _, ok := unconfigured.Lookup("deprecated"); !ok {
+		t.Error("Expected a nil schema to know the built-in annotations")
+	}
+
+	schema := NewAnnotationSchema(config.Annotations{
+		"owner":  {Description: "Owning team"},
+		"go.tag": {Type: config.AnnotationTypeString, Values: []string{"json"}},
+	})
+	if annotation, ok := schema.Lookup("go.tag"); !ok || len(annotation.Values) != 1 {
+		t.Errorf("Expected the configured go.tag to override the built-in one, got %+v", annotation)
+	}
+
+	keys := schema.Keys()
+	if len(keys) != len(builtinAnnotations)+1 || keys[len(keys)-1] != "python.immutable" {
+		t.Errorf("Expected sorted built-in and configured keys, got %v", keys)
+	}
+
+	schema.SetAnnotations(nil)
+	if _, ok := schema.Lookup("owner"); ok {
+		t.Error("Expected replaced annotations to be forgotten")
+	}
+}
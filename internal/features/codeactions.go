@@ -9,6 +9,7 @@ import (
 	tree_sitter "github.com/tree-sitter/go-tree-sitter"
 
 	"frugal-ls/internal/document"
+	"frugal-ls/internal/workspace"
 	"frugal-ls/pkg/ast"
 )
 
@@ -60,13 +61,26 @@ func FindNodeAtPosition(node *tree_sitter.Node, source []byte, line, character u
 }
 
 // CodeActionProvider handles code actions and quick fixes for Frugal files
-type CodeActionProvider struct{}
+type CodeActionProvider struct {
+	includeResolver *workspace.IncludeResolver
+	typeResolver    *workspace.TypeResolver
+}
 
 // NewCodeActionProvider creates a new code action provider
 func NewCodeActionProvider() *CodeActionProvider {
 	return &CodeActionProvider{}
 }
 
+// SetIncludeResolver enables quick fixes that search the workspace for included files
+func (c *CodeActionProvider) SetIncludeResolver(resolver *workspace.IncludeResolver) {
+	c.includeResolver = resolver
+}
+
+// SetTypeResolver enables quick fixes that edit declarations in included files
+func (c *CodeActionProvider) SetTypeResolver(resolver *workspace.TypeResolver) {
+	c.typeResolver = resolver
+}
+
 // ProvideCodeActions provides code actions for a given range and context
 func (c *CodeActionProvider) ProvideCodeActions(doc *document.Document, rng protocol.Range, context protocol.CodeActionContext) ([]protocol.CodeAction, error) {
 	var actions []protocol.CodeAction
@@ -113,6 +127,18 @@ func (c *CodeActionProvider) getQuickFixes(doc *document.Document, diagnostics [
 					actions = append(actions, *action)
 				}
 			}
+
+			// Handle includes that do not resolve
+			if diagnostic.Code != nil && diagnostic.Code.Value == ruleIDUnresolvedInclude {
+				actions = append(actions, c.createFixUnresolvedInclude(doc, diagnostic)...)
+			}
+
+			// Handle structs thrown by methods
+			if diagnostic.Code != nil && diagnostic.Code.Value == ruleIDThrowsType {
+				if action := c.createConvertToExceptionAction(doc, diagnostic); action != nil {
+					actions = append(actions, *action)
+				}
+			}
 		}
 	}
 
@@ -231,6 +257,80 @@ func (c *CodeActionProvider) createFixMissingParenthesis(doc *document.Document,
 	}
 }
 
+// createFixUnresolvedInclude offers to replace an unresolved include with matching workspace files
+func (c *CodeActionProvider) createFixUnresolvedInclude(doc *document.Document, diagnostic protocol.Diagnostic) []protocol.CodeAction {
+	var actions []protocol.CodeAction
+	if c.includeResolver == nil {
+		return actions
+	}
+
+	for _, include := range doc.GetModel().Includes {
+		if modelRangeToProtocol(include.PathRange).Start != diagnostic.Range.Start {
+			continue
+		}
+
+		candidates := c.includeResolver.FindIncludeCandidates(doc.URI, include.Path)
+		for _, candidate := range candidates {
+			edit := protocol.TextEdit{
+				Range:   diagnostic.Range,
+				NewText: fmt.Sprintf("%q", candidate),
+			}
+
+			kind := protocol.CodeActionKindQuickFix
+			actions = append(actions, protocol.CodeAction{
+				Title: fmt.Sprintf("Change include to \"%s\"", candidate),
+				Kind:  &kind,
+				Edit: &protocol.WorkspaceEdit{
+					Changes: map[string][]protocol.TextEdit{
+						doc.URI: {edit},
+					},
+				},
+				Diagnostics: []protocol.Diagnostic{diagnostic},
+				IsPreferred: &[]bool{len(candidates) == 1}[0],
+			})
+		}
+	}
+
+	return actions
+}
+
+// createConvertToExceptionAction offers to turn a struct named in a throws list into an
+// exception by replacing its struct keyword
+func (c *CodeActionProvider) createConvertToExceptionAction(doc *document.Document, diagnostic protocol.Diagnostic) *protocol.CodeAction {
+	checker := newValueChecker(doc, c.typeResolver)
+	for _, service := range doc.GetModel().Services {
+		for _, method := range service.Methods {
+			for _, field := range method.Throws {
+				if field.Type == nil || modelRangeToProtocol(field.Type.Range) != diagnostic.Range {
+					continue
+				}
+
+				declURI, def, ok := thrownDefinition(checker, field.Type, doc.URI)
+				if !ok || def == nil || def.Type != ast.NodeTypeStruct {
+					return nil
+				}
+
+				keyword := modelRangeToProtocol(def.Range)
+				keyword.End = keyword.Start
+				keyword.End.Character += uint32(len("struct"))
+				kind := protocol.CodeActionKindQuickFix
+				return &protocol.CodeAction{
+					Title: fmt.Sprintf("Convert struct %s to exception", def.Name),
+					Kind:  &kind,
+					Edit: &protocol.WorkspaceEdit{
+						Changes: map[string][]protocol.TextEdit{
+							declURI: {{Range: keyword, NewText: "exception"}},
+						},
+					},
+					Diagnostics: []protocol.Diagnostic{diagnostic},
+					IsPreferred: &[]bool{true}[0],
+				}
+			}
+		}
+	}
+	return nil
+}
+
 // createFixMissingSemicolon creates a quick fix for missing semicolon
 func (c *CodeActionProvider) createFixMissingSemicolon(doc *document.Document, diagnostic protocol.Diagnostic) *protocol.CodeAction {
 	// Insert semicolon at the diagnostic location
@@ -306,10 +406,13 @@ func (c *CodeActionProvider) createAddFieldAction(doc *document.Document, _ prot
 		return nil
 	}
 
+	// The model holds the IDs and names retired by @reserved comments
+	structDef := doc.GetModel().FindStruct(c.extractIdentifier(structNode, doc.Content))
+
 	// Get next field ID by counting existing fields
-	fieldID := c.getNextFieldID(structNode, doc.Content)
+	fieldID := c.getNextFieldID(structNode, doc.Content, structDef)
 
-	newField := fmt.Sprintf("\n    %d: optional string newField,", fieldID)
+	newField := fmt.Sprintf("\n    %d: optional string %s,", fieldID, c.getNewFieldName(structDef))
 
 	// Find insertion point (end of struct body)
 	insertPosition := protocol.Position{
@@ -540,8 +643,9 @@ func (c *CodeActionProvider) findParentOfType(node *tree_sitter.Node, nodeType s
 	return nil
 }
 
-// getNextFieldID determines the next field ID for a struct
-func (c *CodeActionProvider) getNextFieldID(structNode *tree_sitter.Node, source []byte) int {
+// getNextFieldID determines the next field ID for a struct, skipping IDs reserved in
+// structDef when it is known
+func (c *CodeActionProvider) getNextFieldID(structNode *tree_sitter.Node, source []byte, structDef *ast.Struct) int {
 	maxID := 0
 
 	// Walk through struct body to find existing field IDs
@@ -557,7 +661,21 @@ func (c *CodeActionProvider) getNextFieldID(structNode *tree_sitter.Node, source
 		return true // Continue walking
 	})
 
-	return maxID + 1
+	nextID := maxID + 1
+	for structDef != nil && structDef.ReservedID(nextID) != nil {
+		nextID++
+	}
+	return nextID
+}
+
+// getNewFieldName returns the placeholder name for an added field, avoiding names
+// reserved in structDef when it is known
+func (c *CodeActionProvider) getNewFieldName(structDef *ast.Struct) string {
+	name := "newField"
+	for i := 2; structDef != nil && structDef.ReservedName(name) != nil; i++ {
+		name = fmt.Sprintf("newField%d", i)
+	}
+	return name
 }
 
 // extractIdentifier extracts the identifier name from a definition node
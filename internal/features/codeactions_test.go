@@ -1,6 +1,8 @@
 package features
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -9,6 +11,7 @@ import (
 
 	"frugal-ls/internal/document"
 	"frugal-ls/internal/parser"
+	"frugal-ls/internal/workspace"
 	"frugal-ls/pkg/ast"
 )
 
@@ -247,3 +250,146 @@ func createTestDocumentForCodeActions(uri, content string) (*document.Document,
 
 	return doc, nil
 }
+
+func TestUnresolvedIncludeQuickFix(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"service/main.frugal", "shared/common.frugal"} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	content := `include "common.frugal"
+
+struct User {
+    1: i64 id
+}`
+	doc, err := createTestDocumentForCodeActions("file://"+filepath.Join(root, "service/main.frugal"), content)
+	if err != nil {
+		t.Fatalf("Failed to create document: %v", err)
+	}
+	defer doc.ParseResult.Close()
+
+	resolver := workspace.NewIncludeResolver([]string{root})
+
+	diagnosticsProvider := NewDiagnosticsProvider()
+	diagnosticsProvider.SetIncludeResolver(resolver)
+
+	var unresolved []protocol.Diagnostic
+	for _, diagnostic := range diagnosticsProvider.ProvideDiagnostics(doc) {
+		if diagnostic.Message == "Cannot resolve include 'common.frugal'" {
+			unresolved = append(unresolved, diagnostic)
+		}
+	}
+	if len(unresolved) != 1 {
+		t.Fatalf("Expected one unresolved include diagnostic, got %d", len(unresolved))
+	}
+	if unresolved[0].Range.Start != (protocol.Position{Line: 0, Character: 8}) {
+		t.Errorf("Expected diagnostic on the include string, got %+v", unresolved[0].Range)
+	}
+
+	provider := NewCodeActionProvider()
+	provider.SetIncludeResolver(resolver)
+
+	actions, err := provider.ProvideCodeActions(doc, unresolved[0].Range, protocol.CodeActionContext{Diagnostics: unresolved})
+	if err != nil {
+		t.Fatalf("ProvideCodeActions failed: %v", err)
+	}
+
+	var fix *protocol.CodeAction
+	for i := range actions {
+		if actions[i].Title == `Change include to "../shared/common.frugal"` {
+			fix = &actions[i]
+		}
+	}
+	if fix == nil {
+		t.Fatalf("Expected include quick fix, got %d actions", len(actions))
+	}
+
+	edits := fix.Edit.Changes[doc.URI]
+	if len(edits) != 1 || edits[0].NewText != `"../shared/common.frugal"` {
+		t.Errorf("Unexpected edits: %+v", edits)
+	}
+}
+
+func TestAddFieldSkipsReserved(t *testing.T) {
+	content := `struct User {
+    // @reserved 3, 4 "newField"
+    1: string name,
+    2: i64 id
+}`
+
+	doc, err := createTestDocumentForCodeActions("file:///test.frugal", content)
+	if err != nil {
+		t.Fatalf("Failed to create document: %v", err)
+	}
+	defer doc.ParseResult.Close()
+
+	rng := protocol.Range{
+		Start: protocol.Position{Line: 2, Character: 8},
+		End:   protocol.Position{Line: 2, Character: 8},
+	}
+	actions, err := NewCodeActionProvider().ProvideCodeActions(doc, rng, protocol.CodeActionContext{})
+	if err != nil {
+		t.Fatalf("ProvideCodeActions failed: %v", err)
+	}
+
+	for _, action := range actions {
+		if action.Title != "Add field to struct" {
+			continue
+		}
+		edit := action.Edit.Changes[doc.URI][0]
+		if edit.NewText != "\n    5: optional string newField2," {
+			t.Errorf("Expected reserved ID and name to be skipped, got %q", edit.NewText)
+		}
+		return
+	}
+	t.Fatalf("Expected an add field action, got %+v", actions)
+}
+
+func TestConvertThrownStructToException(t *testing.T) {
+	content := `struct Problem {
+    1: string message
+}
+
+service UserService {
+    void get(1: i64 id) throws (1: Problem problem)
+}`
+
+	doc, err := createTestDocumentForCodeActions("file:///test.frugal", content)
+	if err != nil {
+		t.Fatalf("Failed to create document: %v", err)
+	}
+	defer doc.ParseResult.Close()
+
+	var diagnostics []protocol.Diagnostic
+	for _, diagnostic := range NewDiagnosticsProvider().ProvideDiagnostics(doc) {
+		if diagnostic.Code.Value == "FL013" {
+			diagnostics = append(diagnostics, diagnostic)
+		}
+	}
+	if len(diagnostics) != 1 {
+		t.Fatalf("Expected one throws diagnostic, got %+v", diagnostics)
+	}
+
+	actions, err := NewCodeActionProvider().ProvideCodeActions(doc, diagnostics[0].Range, protocol.CodeActionContext{Diagnostics: diagnostics})
+	if err != nil {
+		t.Fatalf("ProvideCodeActions failed: %v", err)
+	}
+	for _, action := range actions {
+		if action.Title != "Convert struct Problem to exception" {
+			continue
+		}
+		edits := action.Edit.Changes[doc.URI]
+		want := protocol.Range{Start: protocol.Position{Line: 0, Character: 0}, End: protocol.Position{Line: 0, Character: 6}}
+		if len(edits) != 1 || edits[0].Range != want || edits[0].NewText != "exception" {
+			t.Errorf("Expected struct keyword to be replaced, got %+v", edits)
+		}
+		return
+	}
+	t.Fatalf("Expected a convert to exception action, got %+v", actions)
+}
@@ -5,18 +5,26 @@ import (
 
 	protocol "github.com/tliron/glsp/protocol_3_16"
 
+	"frugal-ls/internal/config"
 	"frugal-ls/internal/document"
 	"frugal-ls/pkg/ast"
 )
 
 // CompletionProvider handles code completion for Frugal files
-type CompletionProvider struct{}
+type CompletionProvider struct {
+	annotationSchema *AnnotationSchema
+}
 
 // NewCompletionProvider creates a new completion provider
 func NewCompletionProvider() *CompletionProvider {
 	return &CompletionProvider{}
 }
 
+// SetAnnotationSchema sets the annotation keys offered inside annotation lists
+func (c *CompletionProvider) SetAnnotationSchema(schema *AnnotationSchema) {
+	c.annotationSchema = schema
+}
+
 // ProvideCompletion provides completion items for a given position
 func (c *CompletionProvider) ProvideCompletion(doc *document.Document, position protocol.Position) ([]protocol.CompletionItem, error) {
 	completions := make([]protocol.CompletionItem, 0)
@@ -36,6 +44,11 @@ func (c *CompletionProvider) ProvideCompletion(doc *document.Document, position
 	}
 	linePrefix := currentLine[:prefixEnd]
 
+	// Annotation lists only hold annotation keys and values
+	if entry, ok := annotationEntryAt(linePrefix); ok {
+		return c.getAnnotationCompletions(entry), nil
+	}
+
 	// Get all content up to cursor position for context analysis
 	contentUpToCursor := ""
 	for i := 0; i <= int(position.Line); i++ {
@@ -60,6 +73,9 @@ func (c *CompletionProvider) ProvideCompletion(doc *document.Document, position
 	case CompletionContextStruct:
 		completions = append(completions, c.getStructCompletions()...)
 		completions = append(completions, c.getTypeCompletions()...)
+	case CompletionContextUnion:
+		completions = append(completions, c.getUnionCompletions()...)
+		completions = append(completions, c.getTypeCompletions()...)
 	case CompletionContextEnum:
 		completions = append(completions, c.getEnumCompletions()...)
 	case CompletionContextType:
@@ -94,6 +110,8 @@ const (
 	CompletionContextType
 	// CompletionContextGeneral indicates general completion context
 	CompletionContextGeneral
+	// CompletionContextUnion indicates completion within a union definition
+	CompletionContextUnion
 )
 
 // determineCompletionContext analyzes the content up to cursor to determine completion context
@@ -154,6 +172,9 @@ func (c *CompletionProvider) determineCompletionContext(contentUpToCursor string
 			if strings.Contains(line, "struct ") {
 				return CompletionContextStruct
 			}
+			if strings.Contains(line, "union ") {
+				return CompletionContextUnion
+			}
 			if strings.Contains(line, "enum ") {
 				return CompletionContextEnum
 			}
@@ -165,6 +186,88 @@ func (c *CompletionProvider) determineCompletionContext(contentUpToCursor string
 	return CompletionContextGeneral
 }
 
+// annotationEntryAt returns the annotation list entry being typed at the end of a line,
+// e.g. `go.tag = "js` for `1: string id (go.tag = "js`. ok is false outside annotation
+// lists. Parameter lists open directly after the method name, while annotation lists
+// follow a space or a closing parenthesis.
+func annotationEntryAt(linePrefix string) (string, bool) {
+	open := strings.LastIndex(linePrefix, "(")
+	if open < 0 || strings.Contains(linePrefix[open:], ")") {
+		return "", false
+	}
+	before := strings.TrimRight(linePrefix[:open], " \t")
+	if before == "" || (len(before) == open && !strings.HasSuffix(before, ")")) {
+		return "", false
+	}
+
+	list := linePrefix[open+1:]
+	entry := list
+	inQuotes, escaped := false, false
+	for i, r := range list {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && inQuotes:
+			escaped = true
+		case r == '"':
+			inQuotes = !inQuotes
+		case inQuotes:
+		case r == ',' || r == ';':
+			entry = list[i+1:]
+		case strings.ContainsRune(":{}<>", r):
+			return "", false
+		}
+	}
+	return strings.TrimLeft(entry, " \t"), true
+}
+
+// getAnnotationCompletions returns the known annotation keys, or the values the key
+// accepts once an entry has an = sign
+func (c *CompletionProvider) getAnnotationCompletions(entry string) []protocol.CompletionItem {
+	completions := make([]protocol.CompletionItem, 0)
+
+	key, value, hasValue := strings.Cut(entry, "=")
+	if hasValue {
+		annotation, ok := c.annotationSchema.Lookup(strings.TrimSpace(key))
+		if !ok {
+			return completions
+		}
+		quoted := strings.HasPrefix(strings.TrimSpace(value), "\"")
+		for _, allowed := range annotationValues(annotation) {
+			insertText := allowed
+			if !quoted {
+				insertText = "\"" + allowed + "\""
+			}
+			completions = append(completions, protocol.CompletionItem{
+				Label:      allowed,
+				Kind:       &[]protocol.CompletionItemKind{protocol.CompletionItemKindValue}[0],
+				InsertText: &insertText,
+			})
+		}
+		return completions
+	}
+
+	for _, key := range c.annotationSchema.Keys() {
+		annotation, _ := c.annotationSchema.Lookup(key)
+		item := protocol.CompletionItem{
+			Label:  key,
+			Kind:   &[]protocol.CompletionItemKind{protocol.CompletionItemKindProperty}[0],
+			Detail: &[]string{"Annotation (" + string(annotationType(annotation)) + ")"}[0],
+			Documentation: protocol.MarkupContent{
+				Kind:  protocol.MarkupKindMarkdown,
+				Value: formatAnnotationInfo(key, annotation),
+			},
+		}
+		// Keys that need a value insert a placeholder for it
+		if !annotation.Optional && annotationType(annotation) != config.AnnotationTypeBool {
+			item.InsertText = &[]string{key + " = \"$1\""}[0]
+			item.InsertTextFormat = &[]protocol.InsertTextFormat{protocol.InsertTextFormatSnippet}[0]
+		}
+		completions = append(completions, item)
+	}
+	return completions
+}
+
 // getTopLevelCompletions returns completions available at the top level
 func (c *CompletionProvider) getTopLevelCompletions() []protocol.CompletionItem {
 	return []protocol.CompletionItem{
@@ -217,6 +320,13 @@ func (c *CompletionProvider) getTopLevelCompletions() []protocol.CompletionItem
 			InsertText:       &[]string{"exception $1 {\n\t$2\n}"}[0],
 			InsertTextFormat: &[]protocol.InsertTextFormat{protocol.InsertTextFormatSnippet}[0],
 		},
+		{
+			Label:            "union",
+			Kind:             &[]protocol.CompletionItemKind{protocol.CompletionItemKindKeyword}[0],
+			Detail:           &[]string{"Union declaration"}[0],
+			InsertText:       &[]string{"union $1 {\n\t$2\n}"}[0],
+			InsertTextFormat: &[]protocol.InsertTextFormat{protocol.InsertTextFormatSnippet}[0],
+		},
 		{
 			Label:            "service",
 			Kind:             &[]protocol.CompletionItemKind{protocol.CompletionItemKindKeyword}[0],
@@ -292,6 +402,19 @@ func (c *CompletionProvider) getStructCompletions() []protocol.CompletionItem {
 	}
 }
 
+// getUnionCompletions returns completions available inside union blocks. Union fields
+// are always optional, so required is not offered.
+func (c *CompletionProvider) getUnionCompletions() []protocol.CompletionItem {
+	return []protocol.CompletionItem{
+		{
+			Label:      "optional",
+			Kind:       &[]protocol.CompletionItemKind{protocol.CompletionItemKindKeyword}[0],
+			Detail:     &[]string{"Optional field"}[0],
+			InsertText: &[]string{"optional"}[0],
+		},
+	}
+}
+
 // getEnumCompletions returns completions available inside enum blocks
 func (c *CompletionProvider) getEnumCompletions() []protocol.CompletionItem {
 	// Enum values don't have specific keywords, but we can suggest common patterns
@@ -352,17 +475,17 @@ func (c *CompletionProvider) getKeywordCompletions() []protocol.CompletionItem {
 func (c *CompletionProvider) getSymbolCompletions(doc *document.Document, position protocol.Position) []protocol.CompletionItem {
 	var completions []protocol.CompletionItem
 
-	symbols := doc.GetSymbols()
-	for _, symbol := range symbols {
+	model := doc.GetModel()
+	for _, definition := range model.Definitions() {
 		// Don't suggest the symbol at the current position
-		if symbol.Line == int(position.Line) {
+		if definition.NameRange.Start.Line == int(position.Line) {
 			continue
 		}
 
 		var kind protocol.CompletionItemKind
 		var detail string
 
-		switch symbol.Type {
+		switch definition.Type {
 		case ast.NodeTypeService:
 			kind = protocol.CompletionItemKindClass
 			detail = "Service"
@@ -384,13 +507,35 @@ func (c *CompletionProvider) getSymbolCompletions(doc *document.Document, positi
 		case ast.NodeTypeException:
 			kind = protocol.CompletionItemKindClass
 			detail = "Exception"
+		case ast.NodeTypeUnion:
+			kind = protocol.CompletionItemKindStruct
+			detail = "Union"
 		default:
 			kind = protocol.CompletionItemKindVariable
 			detail = "Symbol"
 		}
 
+		item := protocol.CompletionItem{
+			Label:  definition.Name,
+			Kind:   &kind,
+			Detail: &detail,
+		}
+		docs := formatDocComment(definitionDoc(model, &definition)) + formatDeprecation(definitionDeprecation(model, &definition))
+		if docs != "" {
+			item.Documentation = protocol.MarkupContent{
+				Kind:  protocol.MarkupKindMarkdown,
+				Value: strings.TrimPrefix(docs, "\n\n"),
+			}
+		}
+		completions = append(completions, item)
+	}
+
+	// Include prefixes give access to the included file's types
+	for _, include := range model.Includes {
+		kind := protocol.CompletionItemKindModule
+		detail := "Include " + include.Path
 		completions = append(completions, protocol.CompletionItem{
-			Label:  symbol.Name,
+			Label:  include.Name,
 			Kind:   &kind,
 			Detail: &detail,
 		})
@@ -376,3 +376,146 @@ func TestCompletionPositionHandling(t *testing.T) {
 		})
 	}
 }
+
+func TestUnionCompletions(t *testing.T) {
+	content := `union Value {
+    `
+	doc, err := createTestDocumentForCompletion("file:///test.frugal", content)
+	if err != nil {
+		t.Fatalf("Failed to create document: %v", err)
+	}
+	defer doc.ParseResult.Close()
+
+	completions, err := NewCompletionProvider().ProvideCompletion(doc, protocol.Position{Line: 1, Character: 4})
+	if err != nil {
+		t.Fatalf("Completion failed: %v", err)
+	}
+
+	foundLabels := make(map[string]bool)
+	for _, completion := range completions {
+		foundLabels[completion.Label] = true
+	}
+	if !foundLabels["optional"] || !foundLabels["string"] {
+		t.Errorf("Expected optional and type completions in a union, got %v", foundLabels)
+	}
+	if foundLabels["required"] {
+		t.Error("Union fields cannot be required, so required should not be suggested")
+	}
+}
+
+func TestAnnotationCompletions(t *testing.T) {
+	content := `struct User {
+    1: string id (go.tag = "json:\"id\"", d
+    2: bool active (python.immutable = 
+    3: string name (go.name = "N", go.tag = 
+    4: string email (
+}`
+	doc, err := createTestDocumentForCompletion("file:///test.frugal", content)
+	if err != nil {
+		t.Fatalf("Failed to create document: %v", err)
+	}
+	defer doc.ParseResult.Close()
+
+	provider := NewCompletionProvider()
+	complete := func(line, character uint32) map[string]protocol.CompletionItem {
+		completions, err := provider.ProvideCompletion(doc, protocol.Position{Line: line, Character: character})
+		if err != nil {
+			t.Fatalf("Completion failed: %v", err)
+		}
+		items := make(map[string]protocol.CompletionItem)
+		for _, completion := range completions {
+			items[completion.Label] = completion
+		}
+		return items
+	}
+
+	keys := complete(1, 43)
+	if _, ok := keys["deprecated"]; !ok || len(keys) != len(builtinAnnotations) {
+		t.Errorf("Expected only annotation keys after a separator, got %v", keys)
+	}
+	if goTag := keys["go.tag"]; goTag.InsertText == nil || *goTag.InsertText != `go.tag = "$1"` {
+		t.Errorf("Expected go.tag to insert a value placeholder, got %+v", goTag)
+	}
+	if deprecated := keys["deprecated"]; deprecated.InsertText != nil {
+		t.Errorf("Expected deprecated, whose value is optional, to insert only its key, got %+v", deprecated)
+	}
+
+	values := complete(2, 39)
+	if value, ok := values["true"]; !ok || len(values) != 2 || *value.InsertText != `"true"` {
+		t.Errorf("Expected quoted true and false values, got %v", values)
+	}
+
+	if free := complete(3, 44); len(free) != 0 {
+		t.Errorf("Expected no values for a free-form string annotation, got %v", free)
+	}
+
+	if keys := complete(4, 21); len(keys) != len(builtinAnnotations) {
+		t.Errorf("Expected annotation keys after an opening parenthesis, got %v", keys)
+	}
+}
+
+func TestAnnotationEntryAt(t *testing.T) {
+	tests := map[string]struct {
+		entry string
+		ok    bool
+	}{
+		"    1: string id (":                  {"", true},
+		"    1: string id (go.t":              {"go.t", true},
+		`    1: string id (go.tag = "a:b", d`: {"d", true},
+		`    1: string id (go.tag = "a,b`:     {`go.tag = "a,b`, true},
+		`    1: string id (go.tag = "\",", d`: {"d", true},
+		"    void ping() (":                   {"", true},
+		"    void ping(":                      {"", false},
+		"    void ping(1: string id":          {"", false},
+		"    1: string id (go.tag) ":          {"", false},
+	}
+
+	for linePrefix, want := range tests {
+		entry, ok := annotationEntryAt(linePrefix)
+		if entry != want.entry || ok != want.ok {
+			t.Errorf("annotationEntryAt(%q) = %q, %v; expected %q, %v", linePrefix, entry, ok, want.entry, want.ok)
+		}
+	}
+}
+
+func TestCompletionDocumentation(t *testing.T) {
+	content := `/**
+ * A user account.
+ * @deprecated Use Account instead.
+ */
+struct User {
+    1: string name
+}
+
+struct Account {
+    1: string id
+}
+
+service UserService {
+    `
+	doc, err := createTestDocumentForCompletion("file:///test.frugal", content)
+	if err != nil {
+		t.Fatalf("Failed to create document: %v", err)
+	}
+	defer doc.ParseResult.Close()
+
+	completions, err := NewCompletionProvider().ProvideCompletion(doc, protocol.Position{Line: 13, Character: 4})
+	if err != nil {
+		t.Fatalf("Completion failed: %v", err)
+	}
+
+	documentation := map[string]interface{}{}
+	for _, completion := range completions {
+		documentation[completion.Label] = completion.Documentation
+	}
+	expected := protocol.MarkupContent{
+		Kind:  protocol.MarkupKindMarkdown,
+		Value: "A user account.\n\n**Deprecated**: Use Account instead.",
+	}
+	if documentation["User"] != expected {
+		t.Errorf("Expected User documentation %+v, got %+v", expected, documentation["User"])
+	}
+	if account, ok := documentation["Account"]; !ok || account != nil {
+		t.Errorf("Expected no Account documentation, got %+v", account)
+	}
+}
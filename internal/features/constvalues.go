@@ -0,0 +1,427 @@
+package features
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"frugal-ls/internal/document"
+	"frugal-ls/internal/workspace"
+	"frugal-ls/pkg/ast"
+)
+
+// intRanges holds the bounds of the integer base types
+var intRanges = map[string][2]int64{
+	"byte": {math.MinInt8, math.MaxInt8},
+	"i8":   {math.MinInt8, math.MaxInt8},
+	"i16":  {math.MinInt16, math.MaxInt16},
+	"i32":  {math.MinInt32, math.MaxInt32},
+	"i64":  {math.MinInt64, math.MaxInt64},
+}
+
+// valueProblem is a constant value that does not match its type
+type valueProblem struct {
+	Range   ast.Range
+	Message string
+}
+
+// valueChecker validates and evaluates constant values. Typedefs, enums and referenced
+// constants are resolved in the document, and through includes when a type resolver is set.
+type valueChecker struct {
+	doc      *document.Document
+	resolver *workspace.TypeResolver
+	// visiting holds the constants being expanded, to stop on reference cycles
+	visiting map[string]bool
+	problems []valueProblem
+}
+
+// identifierTarget is what an identifier value refers to: a constant or an enum value
+type identifierTarget struct {
+	uri       string
+	constant  *ast.Const
+	enum      *ast.Enum
+	enumValue *ast.EnumValue
+}
+
+// newValueChecker creates a checker for values in doc; resolver may be nil
+func newValueChecker(doc *document.Document, resolver *workspace.TypeResolver) *valueChecker {
+	return &valueChecker{
+		doc:      doc,
+		resolver: resolver,
+		visiting: make(map[string]bool),
+	}
+}
+
+// check validates a value written in the document at valueURI against a type written in
+// the document at typeURI
+func (c *valueChecker) check(typ *ast.TypeRef, typeURI string, value *ast.ConstValue, valueURI string) {
+	if typ == nil || value == nil {
+		return
+	}
+	if value.Kind == ast.ConstValueIdentifier && !isBoolLiteral(value.Text) {
+		c.checkReference(typ, typeURI, value, valueURI)
+		return
+	}
+
+	resolved, resolvedURI := c.underlying(typ, typeURI)
+	if resolved == nil {
+		return
+	}
+
+	switch resolved.Kind {
+	case ast.TypeRefBase:
+		c.checkBase(typ, resolved.Name, value)
+	case ast.TypeRefList, ast.TypeRefSet:
+		if value.Kind != ast.ConstValueList {
+			c.mismatch(typ, value)
+			return
+		}
+		for _, elem := range value.Elements {
+			c.check(resolved.Elem, resolvedURI, elem, valueURI)
+		}
+	case ast.TypeRefMap:
+		if value.Kind != ast.ConstValueMap {
+			c.mismatch(typ, value)
+			return
+		}
+		for _, entry := range value.Entries {
+			c.check(resolved.Key, resolvedURI, entry.Key, valueURI)
+			c.check(resolved.Value, resolvedURI, entry.Value, valueURI)
+		}
+	case ast.TypeRefUser:
+		c.checkUser(typ, resolved, resolvedURI, value, valueURI)
+	}
+}
+
+// checkBase validates a literal against a base type
+func (c *valueChecker) checkBase(typ *ast.TypeRef, base string, value *ast.ConstValue) {
+	switch value.Kind {
+	case ast.ConstValueInteger:
+		if bounds, ok := intRanges[base]; ok {
+			n, err := strconv.ParseInt(value.Text, 10, 64)
+			if err != nil || n < bounds[0] || n > bounds[1] {
+				c.add(value.Range, "Value %s is out of range for %s (%d to %d)", value.Text, typ.Text, bounds[0], bounds[1])
+			}
+			return
+		}
+		// Booleans may be written as 0 or 1, as in Thrift
+		if base == "double" || (base == "bool" && (value.Text == "0" || value.Text == "1")) {
+			return
+		}
+	case ast.ConstValueDouble:
+		if base == "double" {
+			return
+		}
+	case ast.ConstValueString:
+		if base == "string" || base == "binary" {
+			return
+		}
+	case ast.ConstValueIdentifier:
+		if base == "bool" {
+			return
+		}
+	}
+	c.mismatch(typ, value)
+}
+
+// checkUser validates a literal against an enum, struct or exception
+func (c *valueChecker) checkUser(typ, resolved *ast.TypeRef, resolvedURI string, value *ast.ConstValue, valueURI string) {
+	declURI, def, ok := c.lookup(resolvedURI, resolved.Include, resolved.Name)
+	if !ok || def == nil {
+		return
+	}
+	model := c.model(declURI)
+
+	switch def.Type {
+	case ast.NodeTypeEnum:
+		if value.Kind != ast.ConstValueInteger {
+			c.mismatch(typ, value)
+			return
+		}
+		n, err := strconv.Atoi(value.Text)
+		if err != nil || findEnumNumber(model.FindEnum(def.Name), n) == nil {
+			c.add(value.Range, "Value %s is not a value of enum %s", value.Text, def.Name)
+		}
+	case ast.NodeTypeStruct, ast.NodeTypeException, ast.NodeTypeUnion:
+		if value.Kind != ast.ConstValueMap {
+			c.mismatch(typ, value)
+			return
+		}
+		if def.Type == ast.NodeTypeUnion && len(value.Entries) > 1 {
+			c.add(value.Range, "A %s value sets exactly one field, got %d", def.Name, len(value.Entries))
+		}
+		structDef := model.FindStruct(def.Name)
+		for _, entry := range value.Entries {
+			if entry.Key.Kind != ast.ConstValueString {
+				c.add(entry.Key.Range, "Expected a field name of %s, got %s", def.Name, entry.Key.Text)
+				continue
+			}
+			field := findField(structDef, unquoteValue(entry.Key.Text))
+			if field == nil {
+				c.add(entry.Key.Range, "Unknown field %s in %s", entry.Key.Text, def.Name)
+				continue
+			}
+			c.check(field.Type, declURI, entry.Value, valueURI)
+		}
+	}
+}
+
+// checkReference validates a reference to a constant or enum value against a type
+func (c *valueChecker) checkReference(typ *ast.TypeRef, typeURI string, value *ast.ConstValue, valueURI string) {
+	target, ok := c.resolveIdentifier(valueURI, value.Text)
+	if !ok {
+		return
+	}
+
+	switch {
+	case target.constant != nil:
+		key := target.uri + "#" + target.constant.Name
+		if c.visiting[key] {
+			return
+		}
+		c.visiting[key] = true
+		defer delete(c.visiting, key)
+
+		// The referenced value is checked against this type, reporting any problem here
+		problems := c.problems
+		c.problems = nil
+		c.check(typ, typeURI, target.constant.Value, target.uri)
+		invalid := len(c.problems) > 0
+		c.problems = problems
+		if invalid {
+			c.add(value.Range, "Constant '%s' is not a valid %s value", value.Text, typ.Text)
+		}
+
+	case target.enumValue != nil:
+		resolved, resolvedURI := c.underlying(typ, typeURI)
+		if resolved == nil {
+			return
+		}
+		if resolved.Kind == ast.TypeRefBase {
+			if _, isInt := intRanges[resolved.Name]; isInt {
+				return // Enum values are i32
+			}
+		}
+		if resolved.Kind == ast.TypeRefUser {
+			declURI, def, ok := c.lookup(resolvedURI, resolved.Include, resolved.Name)
+			if !ok || def == nil || (def.Type == ast.NodeTypeEnum && declURI == target.uri && def.Name == target.enum.Name) {
+				return
+			}
+			if def.Type == ast.NodeTypeEnum {
+				c.add(value.Range, "'%s' is not a value of enum %s", value.Text, def.Name)
+				return
+			}
+		}
+		c.add(value.Range, "Cannot use %s (enum %s) as %s value", value.Text, target.enum.Name, typ.Text)
+
+	case target.enum != nil:
+		c.add(value.Range, "Unknown value '%s' of enum %s", value.Text, target.enum.Name)
+
+	default:
+		c.add(value.Range, "Unknown constant '%s'", value.Text)
+	}
+}
+
+// evaluate returns a value as text with references to constants and enum values replaced
+// by what they stand for
+func (c *valueChecker) evaluate(value *ast.ConstValue, uri string) string {
+	if value == nil {
+		return ""
+	}
+
+	switch value.Kind {
+	case ast.ConstValueInteger:
+		if n, err := strconv.ParseInt(value.Text, 10, 64); err == nil {
+			return strconv.FormatInt(n, 10)
+		}
+	case ast.ConstValueIdentifier:
+		if isBoolLiteral(value.Text) {
+			return value.Text
+		}
+		target, ok := c.resolveIdentifier(uri, value.Text)
+		if !ok {
+			break
+		}
+		if target.enumValue != nil {
+			return fmt.Sprintf("%s.%s (%d)", target.enum.Name, target.enumValue.Name, target.enumValue.Value)
+		}
+		if target.constant != nil {
+			key := target.uri + "#" + target.constant.Name
+			if c.visiting[key] {
+				break
+			}
+			c.visiting[key] = true
+			defer delete(c.visiting, key)
+			return c.evaluate(target.constant.Value, target.uri)
+		}
+	case ast.ConstValueList:
+		elements := make([]string, 0, len(value.Elements))
+		for _, elem := range value.Elements {
+			elements = append(elements, c.evaluate(elem, uri))
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	case ast.ConstValueMap:
+		entries := make([]string, 0, len(value.Entries))
+		for _, entry := range value.Entries {
+			entries = append(entries, c.evaluate(entry.Key, uri)+": "+c.evaluate(entry.Value, uri))
+		}
+		return "{" + strings.Join(entries, ", ") + "}"
+	}
+	return value.Text
+}
+
+// resolveIdentifier binds an identifier value such as MAX, Status.ACTIVE or
+// common.Status.ACTIVE in the document at uri. ok is false when the identifier can't be
+// resolved because its include is not available.
+func (c *valueChecker) resolveIdentifier(uri, text string) (identifierTarget, bool) {
+	parts := strings.Split(text, ".")
+	include := ""
+	if len(parts) > 1 && c.model(uri).FindInclude(parts[0]) != nil {
+		include = parts[0]
+		parts = parts[1:]
+	}
+
+	declURI, def, ok := c.lookup(uri, include, parts[0])
+	if !ok || def == nil {
+		return identifierTarget{}, ok
+	}
+
+	model := c.model(declURI)
+	target := identifierTarget{uri: declURI}
+	switch {
+	case def.Type == ast.NodeTypeConst && len(parts) == 1:
+		target.constant = model.FindConst(def.Name)
+	case def.Type == ast.NodeTypeEnum && len(parts) == 2:
+		target.enum = model.FindEnum(def.Name)
+		target.enumValue = findEnumValue(target.enum, parts[1])
+	}
+	return target, true
+}
+
+// lookup finds the declaration of a possibly include-prefixed name used in the document
+// at uri. ok is false when the include is not available.
+func (c *valueChecker) lookup(uri, include, name string) (string, *ast.Definition, bool) {
+	if include == "" {
+		return uri, c.model(uri).FindDefinition(name), true
+	}
+	if c.resolver == nil {
+		return "", nil, false
+	}
+
+	decl, err := c.resolver.ResolveTypeRef(uri, &ast.TypeRef{Kind: ast.TypeRefUser, Include: include, Name: name})
+	if errors.Is(err, workspace.ErrUndefinedType) {
+		return "", nil, true
+	}
+	if err != nil {
+		return "", nil, false
+	}
+	return decl.URI, c.model(decl.URI).FindDefinition(decl.Name), true
+}
+
+// underlying follows typedefs from a type written in the document at uri. It returns nil
+// if the type can't be resolved.
+func (c *valueChecker) underlying(typ *ast.TypeRef, uri string) (*ast.TypeRef, string) {
+	visited := make(map[string]bool)
+	for typ != nil && typ.Kind == ast.TypeRefUser {
+		declURI, def, ok := c.lookup(uri, typ.Include, typ.Name)
+		if !ok || def == nil {
+			return nil, ""
+		}
+		if def.Type != ast.NodeTypeTypedef {
+			return typ, uri
+		}
+
+		key := declURI + "#" + def.Name
+		if visited[key] {
+			return nil, ""
+		}
+		visited[key] = true
+		typ, uri = c.model(declURI).FindTypedef(def.Name).Type, declURI
+	}
+	return typ, uri
+}
+
+// model returns the model of the document at uri
+func (c *valueChecker) model(uri string) *ast.File {
+	if uri == c.doc.URI || c.resolver == nil {
+		return c.doc.GetModel()
+	}
+	return c.resolver.Model(uri)
+}
+
+// mismatch reports a value of the wrong kind for its type
+func (c *valueChecker) mismatch(typ *ast.TypeRef, value *ast.ConstValue) {
+	switch value.Kind {
+	case ast.ConstValueList, ast.ConstValueMap:
+		c.add(value.Range, "Cannot use %s literal as %s value", value.Kind, typ.Text)
+	default:
+		c.add(value.Range, "Cannot use %s (%s) as %s value", value.Text, valueKindName(value), typ.Text)
+	}
+}
+
+// add records a problem
+func (c *valueChecker) add(rng ast.Range, format string, args ...any) {
+	c.problems = append(c.problems, valueProblem{Range: rng, Message: fmt.Sprintf(format, args...)})
+}
+
+// valueKindName describes a literal's kind in messages
+func valueKindName(value *ast.ConstValue) string {
+	if value.Kind == ast.ConstValueIdentifier {
+		return "bool"
+	}
+	return string(value.Kind)
+}
+
+// isBoolLiteral reports whether an identifier value is a boolean keyword
+func isBoolLiteral(text string) bool {
+	return text == "true" || text == "false"
+}
+
+// unquoteValue strips the quotes from a string literal's text
+func unquoteValue(text string) string {
+	if len(text) >= 2 {
+		return text[1 : len(text)-1]
+	}
+	return text
+}
+
+// findEnumValue returns the enum member with the given name
+func findEnumValue(enum *ast.Enum, name string) *ast.EnumValue {
+	if enum == nil {
+		return nil
+	}
+	for _, value := range enum.Values {
+		if value.Name == name {
+			return value
+		}
+	}
+	return nil
+}
+
+// findEnumNumber returns the enum member with the given number
+func findEnumNumber(enum *ast.Enum, number int) *ast.EnumValue {
+	if enum == nil {
+		return nil
+	}
+	for _, value := range enum.Values {
+		if value.Value == number {
+			return value
+		}
+	}
+	return nil
+}
+
+// findField returns the struct field with the given name
+func findField(structDef *ast.Struct, name string) *ast.Field {
+	if structDef == nil {
+		return nil
+	}
+	for _, field := range structDef.Fields {
+		if field.Name == name {
+			return field
+		}
+	}
+	return nil
+}
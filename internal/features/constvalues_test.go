@@ -0,0 +1,300 @@
+package features
+
+import (
+	"strings"
+	"testing"
+
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+func TestConstValueDiagnostics(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{
+			name: "valid constants",
+			content: `typedef i64 UserID
+enum Status {
+    ACTIVE = 1,
+    INACTIVE = 2
+}
+struct Point {
+    1: i32 x,
+    2: double y
+}
+const i8 SMALL = -128
+const i64 BIG = 9223372036854775807
+const double RATIO = 1
+const bool ENABLED = true
+const bool LEGACY = 0
+const binary DATA = "abc"
+const UserID ROOT = 1
+const i32 TIMEOUT = SMALL
+const Status DEFAULT_STATUS = Status.ACTIVE
+const Status CODED = 2
+const i32 STATUS_CODE = Status.INACTIVE
+const list<Status> STATUSES = [Status.ACTIVE, DEFAULT_STATUS]
+const map<string, list<i16>> RANGES = {"a": [1, 2], "b": []}
+const Point ORIGIN = {"x": 0, "y": 0.5}`,
+			expected: nil,
+		},
+		{
+			name:     "string for integer",
+			content:  `const i32 DEFAULT_TIMEOUT = "abc"`,
+			expected: []string{`Cannot use "abc" (string) as i32 value`},
+		},
+		{
+			name: "integer ranges",
+			content: `const i8 A = 128
+const i16 B = -32769
+const i32 C = 2147483648
+const i64 D = 9223372036854775808`,
+			expected: []string{
+				"Value 128 is out of range for i8 (-128 to 127)",
+				"Value -32769 is out of range for i16 (-32768 to 32767)",
+				"Value 2147483648 is out of range for i32 (-2147483648 to 2147483647)",
+				"Value 9223372036854775808 is out of range for i64 (-9223372036854775808 to 9223372036854775807)",
+			},
+		},
+		{
+			name: "scalar mismatches",
+			content: `const i32 A = 1.5
+const bool B = 2
+const string C = true
+const double D = "x"`,
+			expected: []string{
+				"Cannot use 1.5 (double) as i32 value",
+				"Cannot use 2 (integer) as bool value",
+				"Cannot use true (bool) as string value",
+				`Cannot use "x" (string) as double value`,
+			},
+		},
+		{
+			name: "containers",
+			content: `const list<i8> A = [1, 200]
+const set<string> B = {"a": "b"}
+const map<string, i32> C = {"a": "b", 1: 2}
+const i32 D = [1]`,
+			expected: []string{
+				"Value 200 is out of range for i8 (-128 to 127)",
+				"Cannot use map literal as set<string> value",
+				`Cannot use "b" (string) as i32 value`,
+				"Cannot use 1 (integer) as string value",
+				"Cannot use list literal as i32 value",
+			},
+		},
+		{
+			name: "typedefs",
+			content: `typedef i8 Small
+typedef list<Small> Smalls
+const Smalls VALUES = [1, 300]`,
+			expected: []string{"Value 300 is out of range for Small (-128 to 127)"},
+		},
+		{
+			name: "enums",
+			content: `enum Status {
+    ACTIVE = 1
+}
+enum Color {
+    RED
+}
+const Status A = Color.RED
+const Status B = Status.DELETED
+const Status C = 3
+const Status D = "ACTIVE"
+const string E = Status.ACTIVE`,
+			expected: []string{
+				"'Color.RED' is not a value of enum Status",
+				"Unknown value 'Status.DELETED' of enum Status",
+				"Value 3 is not a value of enum Status",
+				`Cannot use "ACTIVE" (string) as Status value`,
+				"Cannot use Status.ACTIVE (enum Status) as string value",
+			},
+		},
+		{
+			name: "constant references",
+			content: `const string NAME = "x"
+const i32 LARGE = 100000
+const i32 A = NAME
+const i8 B = LARGE
+const i32 C = MISSING
+const i32 D = E
+const i32 E = D`,
+			expected: []string{
+				"Constant 'NAME' is not a valid i32 value",
+				"Constant 'LARGE' is not a valid i8 value",
+				"Unknown constant 'MISSING'",
+			},
+		},
+		{
+			name: "struct literals",
+			content: `struct Point {
+    1: i32 x
+}
+const Point A = {"x": "one", "z": 1, 2: 3}
+const Point B = [1]`,
+			expected: []string{
+				`Cannot use "one" (string) as i32 value`,
+				`Unknown field "z" in Point`,
+				"Expected a field name of Point, got 2",
+				"Cannot use list literal as Point value",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := createTestDocumentForDiagnostics(t, "file:///test.frugal", tt.content)
+			defer doc.ParseResult.Close()
+
+			var messages []string
+			for _, diagnostic := range NewDiagnosticsProvider().ProvideDiagnostics(doc) {
+				if diagnostic.Code.Value == "FL010" {
+					messages = append(messages, diagnostic.Message)
+				}
+			}
+			if strings.Join(messages, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("Expected:\n%s\ngot:\n%s", strings.Join(tt.expected, "\n"), strings.Join(messages, "\n"))
+			}
+		})
+	}
+}
+
+func TestConstValueDiagnosticsValidFile(t *testing.T) {
+	content := `enum Status {
+    ACTIVE = 1,
+    INACTIVE = 2
+}
+const Status DEFAULT_STATUS = Status.ACTIVE
+const Status CODED = 2
+const list<Status> STATUSES = [Status.ACTIVE, CODED]`
+
+	doc := createTestDocumentForDiagnostics(t, "file:///test.frugal", content)
+	defer doc.ParseResult.Close()
+
+	// Constants are named by their identifier, not their enum type, so no other rule fires
+	if diagnostics := NewDiagnosticsProvider().ProvideDiagnostics(doc); len(diagnostics) != 0 {
+		t.Errorf("Expected no diagnostics, got %+v", diagnostics)
+	}
+}
+
+func TestEvaluateConstValue(t *testing.T) {
+	content := `enum Status {
+    ACTIVE = 1,
+    INACTIVE
+}
+const i32 BASE = 010
+const i32 COPY = BASE
+const list<Status> STATUSES = [Status.INACTIVE, DEFAULT]
+const Status DEFAULT = Status.ACTIVE
+const map<string, i32> LIMITS = {"base": COPY}
+const i32 LOOP = LOOP`
+
+	doc := createTestDocumentForDiagnostics(t, "file:///test.frugal", content)
+	defer doc.ParseResult.Close()
+
+	checker := newValueChecker(doc, nil)
+	for name, expected := range map[string]string{
+		"BASE":     "10",
+		"COPY":     "10",
+		"STATUSES": "[Status.INACTIVE (2), Status.ACTIVE (1)]",
+		"LIMITS":   `{"base": 10}`,
+		"LOOP":     "LOOP",
+	} {
+		if got := checker.evaluate(doc.GetModel().FindConst(name).Value, doc.URI); got != expected {
+			t.Errorf("%s: expected %s, got %s", name, expected, got)
+		}
+	}
+}
+
+func TestConstValuesAcrossIncludes(t *testing.T) {
+	common := "enum Status {\n    ACTIVE = 1\n}\n\ntypedef i8 Small\n\nconst i32 LIMIT = 500"
+	content := `include "common.frugal"
+
+const common.Status A = common.Status.ACTIVE
+const common.Small B = common.LIMIT
+const i32 C = common.Missing
+const i32 D = other.VALUE`
+
+	doc, resolver := openTestWorkspace(t, content, map[string]string{"common.frugal": common})
+
+	provider := NewDiagnosticsProvider()
+	provider.SetTypeResolver(resolver)
+	var messages []string
+	for _, diagnostic := range provider.ProvideDiagnostics(doc) {
+		if diagnostic.Code.Value == "FL010" {
+			messages = append(messages, diagnostic.Message)
+		}
+	}
+	expected := []string{
+		"Constant 'common.LIMIT' is not a valid common.Small value",
+		"Unknown constant 'common.Missing'",
+		"Unknown constant 'other.VALUE'",
+	}
+	if strings.Join(messages, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected %v, got %v", expected, messages)
+	}
+
+	hover := NewHoverProvider()
+	hover.SetTypeResolver(resolver)
+	result, err := hover.ProvideHover(doc, protocol.Position{Line: 3, Character: 19})
+	if err != nil || result == nil {
+		t.Fatalf("Expected hover for B, got %v, %v", result, err)
+	}
+	if value := result.Contents.(protocol.MarkupContent).Value; !strings.Contains(value, "**Value**: `500`") {
+		t.Errorf("Expected the evaluated value in hover, got %q", value)
+	}
+}
+
+func TestDefaultValueDiagnostics(t *testing.T) {
+	common := "enum Status {\n    ACTIVE = 1\n}"
+	content := `include "common.frugal"
+
+enum Color {
+    RED
+}
+
+struct Settings {
+    1: i32 retries = 3,
+    2: i8 level = 1000,
+    3: common.Status status = common.Status.ACTIVE,
+    4: common.Status other = common.Status.DELETED,
+    5: Color color = common.Status.ACTIVE,
+    6: required string name = "x",
+    7: list<string> tags = ["a", 1]
+}
+
+service Api {
+    void call(1: bool verbose = "yes")
+}`
+
+	doc, resolver := openTestWorkspace(t, content, map[string]string{"common.frugal": common})
+
+	provider := NewDiagnosticsProvider()
+	provider.SetTypeResolver(resolver)
+
+	var messages []string
+	for _, diagnostic := range provider.ProvideDiagnostics(doc) {
+		if diagnostic.Code.Value != "FL011" {
+			continue
+		}
+		messages = append(messages, diagnostic.Message)
+		if strings.Contains(diagnostic.Message, "never used") && *diagnostic.Severity != protocol.DiagnosticSeverityWarning {
+			t.Errorf("Expected the unused default as a warning, got %v", *diagnostic.Severity)
+		}
+	}
+
+	expected := []string{
+		"Value 1000 is out of range for i8 (-128 to 127)",
+		"Unknown value 'common.Status.DELETED' of enum Status",
+		"'common.Status.ACTIVE' is not a value of enum Color",
+		"Cannot use 1 (integer) as string value",
+		`Cannot use "yes" (string) as bool value`,
+		"Default value of required field 'name' is never used",
+	}
+	if strings.Join(messages, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(messages, "\n"))
+	}
+}
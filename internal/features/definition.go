@@ -171,6 +171,7 @@ func (d *DefinitionProvider) isDefinitionNode(node *tree_sitter.Node, symbolName
 		"const_definition":     true,
 		"typedef_definition":   true,
 		"exception_definition": true,
+		"union_definition":     true,
 		"method":               true,
 		"field":                true,
 		"enum_value":           true,
@@ -309,6 +310,7 @@ func (d *DefinitionProvider) isInDeclarationContext(node *tree_sitter.Node, _ []
 			"const_definition":     true,
 			"typedef_definition":   true,
 			"exception_definition": true,
+			"union_definition":     true,
 		}
 
 		if declarationTypes[parentType] {
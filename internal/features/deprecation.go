@@ -0,0 +1,220 @@
+package features
+
+import (
+	"fmt"
+	"strings"
+
+	"frugal-ls/internal/document"
+	"frugal-ls/internal/workspace"
+	"frugal-ls/pkg/ast"
+)
+
+// deprecatedUsage is a reference to a deprecated type, field or enum value
+type deprecatedUsage struct {
+	// Name is the deprecated declaration, e.g. User, User.email or Status.ACTIVE
+	Name        string
+	Deprecation *ast.Deprecation
+	Range       ast.Range
+}
+
+// message describes the usage for diagnostics
+func (u deprecatedUsage) message() string {
+	if u.Deprecation.Message == "" {
+		return fmt.Sprintf("'%s' is deprecated", u.Name)
+	}
+	return fmt.Sprintf("'%s' is deprecated: %s", u.Name, u.Deprecation.Message)
+}
+
+// definitionDeprecation returns how a top-level definition is deprecated, or nil
+func definitionDeprecation(model *ast.File, def *ast.Definition) *ast.Deprecation {
+	switch def.Type {
+	case ast.NodeTypeStruct, ast.NodeTypeException, ast.NodeTypeUnion:
+		if structDef := model.FindStruct(def.Name); structDef != nil {
+			return structDef.Deprecated
+		}
+	case ast.NodeTypeEnum:
+		if enum := model.FindEnum(def.Name); enum != nil {
+			return enum.Deprecated
+		}
+	case ast.NodeTypeTypedef:
+		if typedef := model.FindTypedef(def.Name); typedef != nil {
+			return typedef.Deprecated
+		}
+	case ast.NodeTypeService:
+		if service := model.FindService(def.Name); service != nil {
+			return service.Deprecated
+		}
+	}
+	return nil
+}
+
+// deprecatedDeclarations returns the name ranges of the deprecated declarations in a file
+func deprecatedDeclarations(model *ast.File) []ast.Range {
+	var ranges []ast.Range
+	addFields := func(fields []*ast.Field) {
+		for _, field := range fields {
+			if field.Deprecated != nil {
+				ranges = append(ranges, field.NameRange)
+			}
+		}
+	}
+
+	for _, typedef := range model.Typedefs {
+		if typedef.Deprecated != nil {
+			ranges = append(ranges, typedef.NameRange)
+		}
+	}
+	for _, enum := range model.Enums {
+		if enum.Deprecated != nil {
+			ranges = append(ranges, enum.NameRange)
+		}
+		for _, value := range enum.Values {
+			if value.Deprecated != nil {
+				ranges = append(ranges, value.NameRange)
+			}
+		}
+	}
+	for _, structDef := range model.Structs {
+		if structDef.Deprecated != nil {
+			ranges = append(ranges, structDef.NameRange)
+		}
+		addFields(structDef.Fields)
+	}
+	for _, service := range model.Services {
+		if service.Deprecated != nil {
+			ranges = append(ranges, service.NameRange)
+		}
+		for _, method := range service.Methods {
+			if method.Deprecated != nil {
+				ranges = append(ranges, method.NameRange)
+			}
+			addFields(method.Params)
+		}
+	}
+	return ranges
+}
+
+// deprecatedUsages finds the references in a document to deprecated types, to deprecated
+// fields in struct constants and to deprecated enum values. References into included
+// files are followed when resolver is set.
+func deprecatedUsages(doc *document.Document, resolver *workspace.TypeResolver) []deprecatedUsage {
+	checker := newValueChecker(doc, resolver)
+	model := doc.GetModel()
+	var usages []deprecatedUsage
+
+	addType := func(rng ast.Range, include, name string) {
+		declURI, def, ok := checker.lookup(doc.URI, include, name)
+		if !ok || def == nil {
+			return
+		}
+		if deprecation := definitionDeprecation(checker.model(declURI), def); deprecation != nil {
+			usages = append(usages, deprecatedUsage{Name: def.Name, Deprecation: deprecation, Range: rng})
+		}
+	}
+
+	for _, ref := range model.TypeRefs() {
+		if ref.Kind == ast.TypeRefUser {
+			addType(ref.Range, ref.Include, ref.Name)
+		}
+	}
+	for _, service := range model.Services {
+		if service.Extends != "" {
+			include, name := "", service.Extends
+			if dot := strings.LastIndex(name, "."); dot >= 0 {
+				include, name = name[:dot], name[dot+1:]
+			}
+			addType(service.ExtendsRange, include, name)
+		}
+	}
+
+	// Values are walked with their types so that struct literal keys can be bound to fields
+	var walkValue func(typ *ast.TypeRef, typeURI string, value *ast.ConstValue)
+	walkValue = func(typ *ast.TypeRef, typeURI string, value *ast.ConstValue) {
+		if value == nil {
+			return
+		}
+		if value.Kind == ast.ConstValueIdentifier && !isBoolLiteral(value.Text) {
+			target, _ := checker.resolveIdentifier(doc.URI, value.Text)
+			switch {
+			case target.enumValue != nil && target.enumValue.Deprecated != nil:
+				usages = append(usages, deprecatedUsage{
+					Name:        target.enum.Name + "." + target.enumValue.Name,
+					Deprecation: target.enumValue.Deprecated,
+					Range:       value.Range,
+				})
+			case target.enum != nil && target.enum.Deprecated != nil:
+				usages = append(usages, deprecatedUsage{Name: target.enum.Name, Deprecation: target.enum.Deprecated, Range: value.Range})
+			}
+			return
+		}
+
+		resolved, resolvedURI := checker.underlying(typ, typeURI)
+		if resolved == nil {
+			return
+		}
+		switch resolved.Kind {
+		case ast.TypeRefList, ast.TypeRefSet:
+			for _, elem := range value.Elements {
+				walkValue(resolved.Elem, resolvedURI, elem)
+			}
+		case ast.TypeRefMap:
+			for _, entry := range value.Entries {
+				walkValue(resolved.Key, resolvedURI, entry.Key)
+				walkValue(resolved.Value, resolvedURI, entry.Value)
+			}
+		case ast.TypeRefUser:
+			declURI, def, ok := checker.lookup(resolvedURI, resolved.Include, resolved.Name)
+			if !ok || def == nil {
+				return
+			}
+			structDef := checker.model(declURI).FindStruct(def.Name)
+			if structDef == nil {
+				return
+			}
+			for _, entry := range value.Entries {
+				field := findField(structDef, unquoteValue(entry.Key.Text))
+				if field == nil {
+					continue
+				}
+				if field.Deprecated != nil {
+					usages = append(usages, deprecatedUsage{
+						Name:        structDef.Name + "." + field.Name,
+						Deprecation: field.Deprecated,
+						Range:       entry.Key.Range,
+					})
+				}
+				walkValue(field.Type, declURI, entry.Value)
+			}
+		}
+	}
+
+	for _, constDef := range model.Consts {
+		walkValue(constDef.Type, doc.URI, constDef.Value)
+	}
+	for _, structDef := range model.Structs {
+		for _, field := range structDef.Fields {
+			walkValue(field.Type, doc.URI, field.Default)
+		}
+	}
+	for _, service := range model.Services {
+		for _, method := range service.Methods {
+			for _, param := range method.Params {
+				walkValue(param.Type, doc.URI, param.Default)
+			}
+		}
+	}
+
+	return usages
+}
+
+// formatDeprecation renders the deprecation line shown in hover, preceded by a blank
+// line, or "" if the declaration is not deprecated
+func formatDeprecation(deprecation *ast.Deprecation) string {
+	if deprecation == nil {
+		return ""
+	}
+	if deprecation.Message == "" {
+		return "\n\n**Deprecated**"
+	}
+	return fmt.Sprintf("\n\n**Deprecated**: %s", deprecation.Message)
+}
@@ -0,0 +1,136 @@
+package features
+
+import (
+	"strings"
+	"testing"
+
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+const deprecationCommon = `// @deprecated Use Account instead.
+struct User {
+    1: string id,
+    2: string email (deprecated = "use contacts")
+}
+
+enum Status {
+    ACTIVE,
+    LEGACY (deprecated)
+}`
+
+const deprecationMain = `include "common.frugal"
+
+const common.User ADMIN = {"id": "1", "email": "admin@example.com"}
+
+const list<common.Status> STATUSES = [common.Status.ACTIVE, common.Status.LEGACY]
+
+service Users {
+    common.User get(1: string id)
+}`
+
+func TestDeprecatedUsageDiagnostics(t *testing.T) {
+	doc, resolver := openTestWorkspace(t, deprecationMain, map[string]string{"common.frugal": deprecationCommon})
+
+	provider := NewDiagnosticsProvider()
+	provider.SetTypeResolver(resolver)
+
+	var deprecated []protocol.Diagnostic
+	for _, diagnostic := range provider.ProvideDiagnostics(doc) {
+		if diagnostic.Code.Value == "FL017" {
+			deprecated = append(deprecated, diagnostic)
+		}
+	}
+
+	expected := []struct {
+		message   string
+		line      uint32
+		character uint32
+	}{
+		{"'User' is deprecated: Use Account instead.", 2, 6},
+		{"'User' is deprecated: Use Account instead.", 7, 4},
+		{"'User.email' is deprecated: use contacts", 2, 38},
+		{"'Status.LEGACY' is deprecated", 4, 60},
+	}
+	if len(deprecated) != len(expected) {
+		t.Fatalf("Expected %d deprecation diagnostics, got %+v", len(expected), deprecated)
+	}
+	for i, want := range expected {
+		got := deprecated[i]
+		if got.Message != want.message || got.Range.Start.Line != want.line || got.Range.Start.Character != want.character {
+			t.Errorf("Expected %q at %d:%d, got %q at %d:%d", want.message, want.line, want.character,
+				got.Message, got.Range.Start.Line, got.Range.Start.Character)
+		}
+		if *got.Severity != protocol.DiagnosticSeverityHint || len(got.Tags) != 1 || got.Tags[0] != protocol.DiagnosticTagDeprecated {
+			t.Errorf("Expected a hint tagged deprecated, got %+v", got)
+		}
+	}
+}
+
+func TestDeprecatedUsagesInFile(t *testing.T) {
+	content := `typedef i64 LegacyID (deprecated = "use UUID")
+
+struct Record {
+    1: LegacyID id,
+    2: Mode mode = Mode.OLD
+}
+
+enum Mode {
+    NEW,
+    // @deprecated
+    OLD
+}`
+
+	doc := createTestDocumentForDiagnostics(t, "file:///test.frugal", content)
+	defer doc.ParseResult.Close()
+
+	usages := deprecatedUsages(doc, nil)
+	if len(usages) != 2 {
+		t.Fatalf("Expected 2 deprecated usages, got %+v", usages)
+	}
+	if usages[0].Name != "LegacyID" || usages[0].Range.Start.Line != 3 {
+		t.Errorf("Expected the typedef reference first, got %+v", usages[0])
+	}
+	if usages[1].Name != "Mode.OLD" || usages[1].Range.Start.Line != 4 || usages[1].message() != "'Mode.OLD' is deprecated" {
+		t.Errorf("Expected the enum value default second, got %+v", usages[1])
+	}
+
+	declarations := deprecatedDeclarations(doc.GetModel())
+	if len(declarations) != 2 || declarations[0].Start.Line != 0 || declarations[1].Start.Line != 10 {
+		t.Errorf("Expected the typedef and enum value declarations, got %+v", declarations)
+	}
+}
+
+func TestDeprecatedBooleanAnnotation(t *testing.T) {
+	content := `struct Old {
+    1: string id
+} (deprecated = "true")
+
+struct Kept {
+    1: string id
+} (deprecated = "false")
+
+struct Holder {
+    1: Old old,
+    2: Kept kept
+}`
+
+	doc := createTestDocumentForDiagnostics(t, "file:///test.frugal", content)
+	defer doc.ParseResult.Close()
+
+	// Both values are accepted, and only "true" deprecates, without a message
+	var messages []string
+	for _, diagnostic := range NewDiagnosticsProvider().ProvideDiagnostics(doc) {
+		messages = append(messages, diagnostic.Message)
+	}
+	if len(messages) != 1 || messages[0] != "'Old' is deprecated" {
+		t.Errorf("Expected only the usage of Old, got %v", messages)
+	}
+
+	hover, err := NewHoverProvider().ProvideHover(doc, protocol.Position{Line: 0, Character: 8})
+	if err != nil || hover == nil {
+		t.Fatalf("Expected hover for Old, got %v (%v)", hover, err)
+	}
+	if text := hover.Contents.(protocol.MarkupContent).Value; !strings.Contains(text, "**Deprecated**") || strings.Contains(text, "**Deprecated**:") {
+		t.Errorf("Expected a deprecation line without a message, got:\n%s", text)
+	}
+}
@@ -1,14 +1,19 @@
 package features
 
 import (
+	"errors"
 	"fmt"
-	"strconv"
+	"path/filepath"
 	"strings"
+	"sync"
 
 	protocol "github.com/tliron/glsp/protocol_3_16"
 	tree_sitter "github.com/tree-sitter/go-tree-sitter"
 
+	"frugal-ls/internal/compat"
+	"frugal-ls/internal/config"
 	"frugal-ls/internal/document"
+	"frugal-ls/internal/workspace"
 	"frugal-ls/pkg/ast"
 )
 
@@ -19,9 +24,11 @@ const (
 	diagnosticsNodeTypeException           = "exception"
 	diagnosticsNodeTypeEnum                = "enum"
 	diagnosticsNodeTypeScope               = "scope"
+	diagnosticsNodeTypeUnion               = "union"
 	diagnosticsNodeTypeEnumDefinition      = "enum_definition"
 	diagnosticsNodeTypeServiceDefinition   = "service_definition"
 	diagnosticsNodeTypeExceptionDefinition = "exception_definition"
+	diagnosticsNodeTypeUnionDefinition     = "union_definition"
 	diagnosticsNodeTypeTypedefDefinition   = "typedef_definition"
 	diagnosticsNodeTypeConstDefinition     = "const_definition"
 	diagnosticsNodeTypeScopeDefinition     = "scope_definition"
@@ -29,13 +36,54 @@ const (
 )
 
 // DiagnosticsProvider provides comprehensive diagnostics for Frugal files
-type DiagnosticsProvider struct{}
+type DiagnosticsProvider struct {
+	typeResolver    *workspace.TypeResolver
+	includeResolver *workspace.IncludeResolver
+	// annotationSchema lists the known annotation keys; nil knows only the built-ins
+	annotationSchema *AnnotationSchema
+
+	lint        config.Lint
+	baseline    *compat.Baseline
+	configMutex sync.RWMutex
+}
 
 // NewDiagnosticsProvider creates a new diagnostics provider
 func NewDiagnosticsProvider() *DiagnosticsProvider {
 	return &DiagnosticsProvider{}
 }
 
+// SetIncludeResolver enables checking that included files exist
+func (d *DiagnosticsProvider) SetIncludeResolver(resolver *workspace.IncludeResolver) {
+	d.includeResolver = resolver
+}
+
+// SetTypeResolver enables cross-file validation of include-prefixed types
+func (d *DiagnosticsProvider) SetTypeResolver(resolver *workspace.TypeResolver) {
+	d.typeResolver = resolver
+}
+
+// SetAnnotationSchema sets the annotation keys known to the annotation rule
+func (d *DiagnosticsProvider) SetAnnotationSchema(schema *AnnotationSchema) {
+	d.annotationSchema = schema
+}
+
+// SetLintConfig sets which rules run and the severities they report with
+func (d *DiagnosticsProvider) SetLintConfig(lint config.Lint) {
+	d.configMutex.Lock()
+	defer d.configMutex.Unlock()
+
+	d.lint = lint
+}
+
+// SetBaseline enables reporting breaking changes against a previous version of the
+// workspace; nil disables it
+func (d *DiagnosticsProvider) SetBaseline(baseline *compat.Baseline) {
+	d.configMutex.Lock()
+	defer d.configMutex.Unlock()
+
+	d.baseline = baseline
+}
+
 // ProvideDiagnostics analyzes a document and returns diagnostics
 func (d *DiagnosticsProvider) ProvideDiagnostics(doc *document.Document) []protocol.Diagnostic {
 	diagnostics := make([]protocol.Diagnostic, 0)
@@ -90,118 +138,267 @@ func (d *DiagnosticsProvider) getSemanticDiagnostics(doc *document.Document) []p
 		return diagnostics
 	}
 
-	// Check for various semantic issues
-	diagnostics = append(diagnostics, d.checkDuplicateDefinitions(doc, root)...)
-	diagnostics = append(diagnostics, d.checkFieldIdValidation(doc, root)...)
-	diagnostics = append(diagnostics, d.checkUnusedImports(doc, root)...)
-	diagnostics = append(diagnostics, d.checkNamingConventions(doc, root)...)
-	diagnostics = append(diagnostics, d.checkTypeReferences(doc, root)...)
+	// Run every registered lint rule that is not disabled for this file
+	suppressions := d.findSuppressions(doc, root)
+	for _, rule := range Rules() {
+		if suppressions.disables(rule) {
+			continue
+		}
+		diagnostics = append(diagnostics, d.runRule(rule, doc, root)...)
+	}
 
 	return diagnostics
 }
 
 // checkDuplicateDefinitions checks for duplicate struct, service, enum names
-func (d *DiagnosticsProvider) checkDuplicateDefinitions(doc *document.Document, root *tree_sitter.Node) []protocol.Diagnostic {
+func (d *DiagnosticsProvider) checkDuplicateDefinitions(doc *document.Document, _ *tree_sitter.Node) []protocol.Diagnostic {
+	diagnostics := make([]protocol.Diagnostic, 0)
+	seenNames := make(map[string]ast.Definition)
+
+	for _, def := range doc.GetModel().Definitions() {
+		key := string(def.Type) + ":" + def.Name
+		existing, exists := seenNames[key]
+		if !exists {
+			seenNames[key] = def
+			continue
+		}
+
+		// Create diagnostic for duplicate definition
+		diagnostic := protocol.Diagnostic{
+			Range:    modelRangeToProtocol(def.NameRange),
+			Severity: &[]protocol.DiagnosticSeverity{protocol.DiagnosticSeverityError}[0],
+			Source:   &[]string{"frugal-ls"}[0],
+			Message:  fmt.Sprintf("Duplicate %s definition '%s'", def.Type, def.Name),
+			RelatedInformation: []protocol.DiagnosticRelatedInformation{{
+				Location: protocol.Location{
+					URI:   doc.URI,
+					Range: modelRangeToProtocol(existing.NameRange),
+				},
+				Message: fmt.Sprintf("First definition of '%s' here", def.Name),
+			}},
+		}
+		diagnostics = append(diagnostics, diagnostic)
+	}
+
+	return diagnostics
+}
+
+// checkDuplicateFieldIDs reports field IDs used twice in a struct, parameter list or throws list
+func (d *DiagnosticsProvider) checkDuplicateFieldIDs(doc *document.Document, _ *tree_sitter.Node) []protocol.Diagnostic {
 	diagnostics := make([]protocol.Diagnostic, 0)
-	seenNames := make(map[string]*tree_sitter.Node)
+	model := doc.GetModel()
 
-	d.walkDefinitions(root, doc.Content, func(defType, name string, node *tree_sitter.Node) {
-		if name == "" {
-			return
+	for _, structDef := range model.Structs {
+		diagnostics = append(diagnostics, d.validateSingleFieldList(doc, structDef.Fields, "")...)
+	}
+
+	for _, service := range model.Services {
+		for _, method := range service.Methods {
+			diagnostics = append(diagnostics, d.validateSingleFieldList(doc, method.Params, "parameter list")...)
+			diagnostics = append(diagnostics, d.validateSingleFieldList(doc, method.Throws, "throws list")...)
+		}
+	}
+
+	return diagnostics
+}
+
+// validateSingleFieldList reports duplicate field IDs within a single field list.
+// contextName is added to the message for lists other than struct fields.
+func (d *DiagnosticsProvider) validateSingleFieldList(doc *document.Document, fields []*ast.Field, contextName string) []protocol.Diagnostic {
+	diagnostics := make([]protocol.Diagnostic, 0)
+	seenFieldIds := make(map[int]*ast.Field)
+
+	for _, field := range fields {
+		if !field.HasID || field.ID == 0 {
+			continue // Skip if we couldn't extract field ID
+		}
+
+		existing, exists := seenFieldIds[field.ID]
+		if !exists {
+			seenFieldIds[field.ID] = field
+			continue
+		}
+
+		message := fmt.Sprintf("Duplicate field ID %d", field.ID)
+		if contextName != "" {
+			message += " in " + contextName
+		}
+		diagnostic := protocol.Diagnostic{
+			Range:    modelRangeToProtocol(field.IDRange),
+			Severity: &[]protocol.DiagnosticSeverity{protocol.DiagnosticSeverityError}[0],
+			Source:   &[]string{"frugal-ls"}[0],
+			Message:  message,
+			RelatedInformation: []protocol.DiagnosticRelatedInformation{{
+				Location: protocol.Location{
+					URI:   doc.URI,
+					Range: modelRangeToProtocol(existing.IDRange),
+				},
+				Message: fmt.Sprintf("Field ID %d first used here", field.ID),
+			}},
 		}
+		diagnostics = append(diagnostics, diagnostic)
+	}
+
+	return diagnostics
+}
+
+// checkInvalidFieldIDs reports struct fields whose ID is not positive
+func (d *DiagnosticsProvider) checkInvalidFieldIDs(doc *document.Document, _ *tree_sitter.Node) []protocol.Diagnostic {
+	diagnostics := make([]protocol.Diagnostic, 0)
 
-		key := defType + ":" + name
-		if existingNode, exists := seenNames[key]; exists {
-			// Create diagnostic for duplicate definition
+	for _, structDef := range doc.GetModel().Structs {
+		for _, field := range structDef.Fields {
+			if !field.HasID || field.ID >= 1 {
+				continue
+			}
 			diagnostic := protocol.Diagnostic{
-				Range:    d.nodeToRange(node, doc.Content),
+				Range:    modelRangeToProtocol(field.IDRange),
 				Severity: &[]protocol.DiagnosticSeverity{protocol.DiagnosticSeverityError}[0],
 				Source:   &[]string{"frugal-ls"}[0],
-				Message:  fmt.Sprintf("Duplicate %s definition '%s'", defType, name),
-				RelatedInformation: []protocol.DiagnosticRelatedInformation{{
-					Location: protocol.Location{
-						URI:   doc.URI,
-						Range: d.nodeToRange(existingNode, doc.Content),
-					},
-					Message: fmt.Sprintf("First definition of '%s' here", name),
-				}},
+				Message:  fmt.Sprintf("Field ID must be positive, got %d", field.ID),
 			}
 			diagnostics = append(diagnostics, diagnostic)
-		} else {
-			seenNames[key] = node
 		}
-	})
+	}
 
 	return diagnostics
 }
 
-// checkFieldIdValidation validates field IDs in structs and methods
-func (d *DiagnosticsProvider) checkFieldIdValidation(doc *document.Document, root *tree_sitter.Node) []protocol.Diagnostic {
+// checkReservedFields reports fields that reuse an ID or name retired by a @reserved
+// comment on their struct
+func (d *DiagnosticsProvider) checkReservedFields(doc *document.Document, _ *tree_sitter.Node) []protocol.Diagnostic {
 	diagnostics := make([]protocol.Diagnostic, 0)
 
-	// Find all structs, exceptions, and service methods
-	d.walkNodes(root, func(node *tree_sitter.Node) {
-		nodeType := node.Kind()
-
-		if nodeType == nodeTypeStructDefinition || nodeType == diagnosticsNodeTypeExceptionDefinition {
-			diagnostics = append(diagnostics, d.validateStructFields(doc, node)...)
-		} else if nodeType == nodeTypeFunctionDefinition {
-			diagnostics = append(diagnostics, d.validateMethodFields(doc, node)...)
+	for _, structDef := range doc.GetModel().Structs {
+		for _, field := range structDef.Fields {
+			if reserved := structDef.ReservedID(field.ID); field.HasID && reserved != nil {
+				diagnostics = append(diagnostics, reservedFieldDiagnostic(doc, field.IDRange, reserved,
+					fmt.Sprintf("Field ID %d is reserved in %s", field.ID, structDef.Name)))
+			}
+			if reserved := structDef.ReservedName(field.Name); reserved != nil {
+				diagnostics = append(diagnostics, reservedFieldDiagnostic(doc, field.NameRange, reserved,
+					fmt.Sprintf("Field name '%s' is reserved in %s", field.Name, structDef.Name)))
+			}
 		}
-	})
+	}
 
 	return diagnostics
 }
 
-// validateStructFields checks field IDs in struct/exception definitions
-func (d *DiagnosticsProvider) validateStructFields(doc *document.Document, structNode *tree_sitter.Node) []protocol.Diagnostic {
-	diagnostics := make([]protocol.Diagnostic, 0)
-	seenFieldIds := make(map[int]*tree_sitter.Node)
+// reservedFieldDiagnostic reports a reserved ID or name, pointing at the reservation
+func reservedFieldDiagnostic(doc *document.Document, rng ast.Range, reserved *ast.Reserved, message string) protocol.Diagnostic {
+	return protocol.Diagnostic{
+		Range:    modelRangeToProtocol(rng),
+		Severity: &[]protocol.DiagnosticSeverity{protocol.DiagnosticSeverityError}[0],
+		Source:   &[]string{"frugal-ls"}[0],
+		Message:  message,
+		RelatedInformation: []protocol.DiagnosticRelatedInformation{{
+			Location: protocol.Location{
+				URI:   doc.URI,
+				Range: modelRangeToProtocol(reserved.Range),
+			},
+			Message: "Reserved here",
+		}},
+	}
+}
 
-	// Find struct body
-	structBody := ast.FindNodeByType(structNode, "struct_body")
-	if structBody == nil {
-		return diagnostics
+// checkConstValues reports constants whose value does not match their declared type
+func (d *DiagnosticsProvider) checkConstValues(doc *document.Document, _ *tree_sitter.Node) []protocol.Diagnostic {
+	checker := newValueChecker(doc, d.typeResolver)
+	for _, constDef := range doc.GetModel().Consts {
+		checker.check(constDef.Type, doc.URI, constDef.Value, doc.URI)
 	}
+	return valueDiagnostics(checker.problems)
+}
 
-	// Check each field
-	childCount := structBody.ChildCount()
-	for i := uint(0); i < childCount; i++ {
-		child := structBody.Child(i)
-		if child.Kind() == nodeTypeField {
-			fieldId, fieldIdNode := d.extractFieldId(child, doc.Content)
-			if fieldId == 0 {
-				continue // Skip if we couldn't extract field ID
+// checkDefaultValues reports field defaults that do not match the field's type, and
+// defaults of required fields, which are never used
+func (d *DiagnosticsProvider) checkDefaultValues(doc *document.Document, _ *tree_sitter.Node) []protocol.Diagnostic {
+	checker := newValueChecker(doc, d.typeResolver)
+	var unused []protocol.Diagnostic
+	checkFields := func(fields []*ast.Field) {
+		for _, field := range fields {
+			if field.Default == nil {
+				continue
 			}
-
-			if existingNode, exists := seenFieldIds[fieldId]; exists {
-				diagnostic := protocol.Diagnostic{
-					Range:    d.nodeToRange(fieldIdNode, doc.Content),
-					Severity: &[]protocol.DiagnosticSeverity{protocol.DiagnosticSeverityError}[0],
+			checker.check(field.Type, doc.URI, field.Default, doc.URI)
+			if field.Requiredness == "required" {
+				unused = append(unused, protocol.Diagnostic{
+					Range:    modelRangeToProtocol(field.Default.Range),
+					Severity: &[]protocol.DiagnosticSeverity{protocol.DiagnosticSeverityWarning}[0],
 					Source:   &[]string{"frugal-ls"}[0],
-					Message:  fmt.Sprintf("Duplicate field ID %d", fieldId),
-					RelatedInformation: []protocol.DiagnosticRelatedInformation{{
-						Location: protocol.Location{
-							URI:   doc.URI,
-							Range: d.nodeToRange(existingNode, doc.Content),
-						},
-						Message: fmt.Sprintf("Field ID %d first used here", fieldId),
-					}},
-				}
-				diagnostics = append(diagnostics, diagnostic)
+					Message:  fmt.Sprintf("Default value of required field '%s' is never used", field.Name),
+				})
+			}
+		}
+	}
+
+	model := doc.GetModel()
+	for _, structDef := range model.Structs {
+		checkFields(structDef.Fields)
+	}
+	for _, service := range model.Services {
+		for _, method := range service.Methods {
+			checkFields(method.Params)
+		}
+	}
+
+	return append(valueDiagnostics(checker.problems), unused...)
+}
+
+// checkServiceExtends reports services that extend something other than a known service,
+// extend themselves through a cycle, or redefine an inherited method
+func (d *DiagnosticsProvider) checkServiceExtends(doc *document.Document, _ *tree_sitter.Node) []protocol.Diagnostic {
+	diagnostics := make([]protocol.Diagnostic, 0)
+	addError := func(rng ast.Range, message string, related []protocol.DiagnosticRelatedInformation) {
+		diagnostics = append(diagnostics, protocol.Diagnostic{
+			Range:              modelRangeToProtocol(rng),
+			Severity:           &[]protocol.DiagnosticSeverity{protocol.DiagnosticSeverityError}[0],
+			Source:             &[]string{"frugal-ls"}[0],
+			Message:            message,
+			RelatedInformation: related,
+		})
+	}
+
+	for _, service := range doc.GetModel().Services {
+		if service.Extends == "" {
+			continue
+		}
+
+		ancestors, err := serviceAncestors(doc, d.typeResolver, service)
+		switch {
+		case errors.Is(err, workspace.ErrExtendsCycle):
+			chain := []string{service.Name}
+			for _, ancestor := range ancestors {
+				chain = append(chain, ancestor.Service.Name)
+			}
+			if len(ancestors) > 0 {
+				chain = append(chain, ancestors[len(ancestors)-1].Service.Extends)
 			} else {
-				seenFieldIds[fieldId] = fieldIdNode
+				chain = append(chain, service.Extends)
 			}
+			addError(service.ExtendsRange, "Circular extends chain: "+strings.Join(chain, " -> "), nil)
+		case len(ancestors) > 0:
+			// Problems further up the chain are reported on the service that has them
+		case errors.Is(err, workspace.ErrUndefinedType), errors.Is(err, workspace.ErrUnknownInclude):
+			addError(service.ExtendsRange, fmt.Sprintf("Unknown service '%s'", service.Extends), nil)
+		case errors.Is(err, workspace.ErrNotService):
+			addError(service.ExtendsRange, fmt.Sprintf("Service %s cannot extend '%s', which is not a service", service.Name, service.Extends), nil)
+		}
 
-			// Validate field ID is positive
-			if fieldId < 1 {
-				diagnostic := protocol.Diagnostic{
-					Range:    d.nodeToRange(fieldIdNode, doc.Content),
-					Severity: &[]protocol.DiagnosticSeverity{protocol.DiagnosticSeverityError}[0],
-					Source:   &[]string{"frugal-ls"}[0],
-					Message:  fmt.Sprintf("Field ID must be positive, got %d", fieldId),
+		for _, method := range service.Methods {
+			for _, ancestor := range ancestors {
+				inherited := ancestor.Service.FindMethod(method.Name)
+				if inherited == nil {
+					continue
 				}
-				diagnostics = append(diagnostics, diagnostic)
+				addError(method.NameRange,
+					fmt.Sprintf("Method '%s' redefines a method inherited from %s", method.Name, ancestor.Service.Name),
+					[]protocol.DiagnosticRelatedInformation{{
+						Location: protocol.Location{URI: ancestor.URI, Range: modelRangeToProtocol(inherited.NameRange)},
+						Message:  "Inherited method",
+					}})
+				break
 			}
 		}
 	}
@@ -209,89 +406,155 @@ func (d *DiagnosticsProvider) validateStructFields(doc *document.Document, struc
 	return diagnostics
 }
 
-// validateMethodFields checks field IDs in method parameters and throws
-func (d *DiagnosticsProvider) validateMethodFields(doc *document.Document, methodNode *tree_sitter.Node) []protocol.Diagnostic {
+// checkUnionFields reports union fields marked required or given a default value, since
+// a union holds exactly one field that the sender chooses
+func (d *DiagnosticsProvider) checkUnionFields(doc *document.Document, _ *tree_sitter.Node) []protocol.Diagnostic {
 	diagnostics := make([]protocol.Diagnostic, 0)
+	addError := func(rng ast.Range, message string) {
+		diagnostics = append(diagnostics, protocol.Diagnostic{
+			Range:    modelRangeToProtocol(rng),
+			Severity: &[]protocol.DiagnosticSeverity{protocol.DiagnosticSeverityError}[0],
+			Source:   &[]string{"frugal-ls"}[0],
+			Message:  message,
+		})
+	}
 
-	// Find all field_list nodes and determine their context
-	var foundFieldLists []*tree_sitter.Node
-	d.walkNodes(methodNode, func(node *tree_sitter.Node) {
-		if node.Kind() == "field_list" {
-			foundFieldLists = append(foundFieldLists, node)
+	for _, structDef := range doc.GetModel().Structs {
+		if structDef.Kind != ast.NodeTypeUnion {
+			continue
+		}
+		for _, field := range structDef.Fields {
+			if field.Requiredness == "required" {
+				addError(field.NameRange, fmt.Sprintf("Union field '%s' cannot be required", field.Name))
+			}
+			if field.Default != nil {
+				addError(field.Default.Range, fmt.Sprintf("Union field '%s' cannot have a default value", field.Name))
+			}
 		}
-	})
-
-	// Process each field_list - determine context by position and preceding siblings
-	for _, fieldList := range foundFieldLists {
-		context := d.determineFieldListContext(fieldList)
-		diagnostics = append(diagnostics, d.validateSingleFieldList(doc, fieldList, context)...)
 	}
 
 	return diagnostics
 }
 
-// determineFieldListContext determines if a field_list is parameters or throws
-func (d *DiagnosticsProvider) determineFieldListContext(fieldList *tree_sitter.Node) string {
-	parent := fieldList.Parent()
-	if parent == nil || parent.Kind() != nodeTypeFunctionDefinition {
-		return "parameter list" // Default
-	}
-
-	// Find the position of this field_list and check for throws before it
-	childCount := parent.ChildCount()
-	var fieldListPosition uint = childCount // Initialize to impossible value
-	var throwsPosition uint = childCount    // Initialize to impossible value
+// checkScopePrefixes reports malformed or repeated {variable} placeholders in scope
+// prefixes
+func (d *DiagnosticsProvider) checkScopePrefixes(doc *document.Document, _ *tree_sitter.Node) []protocol.Diagnostic {
+	diagnostics := make([]protocol.Diagnostic, 0)
 
-	// First pass: find positions
-	for i := uint(0); i < childCount; i++ {
-		child := parent.Child(i)
-		if child == fieldList {
-			fieldListPosition = i
-		}
-		if child.Kind() == "throws" {
-			throwsPosition = i
+	for _, scope := range doc.GetModel().Scopes {
+		for _, prefixError := range scope.PrefixErrors {
+			diagnostics = append(diagnostics, protocol.Diagnostic{
+				Range:    modelRangeToProtocol(prefixError.Range),
+				Severity: &[]protocol.DiagnosticSeverity{protocol.DiagnosticSeverityError}[0],
+				Source:   &[]string{"frugal-ls"}[0],
+				Message:  prefixError.Message,
+			})
 		}
 	}
 
-	// If throws comes before this field_list, it's a throws list
-	if throwsPosition < fieldListPosition {
-		return "throws list"
+	return diagnostics
+}
+
+// checkDeprecatedUsages marks references to deprecated declarations, here or in included
+// files, with the deprecated tag so that editors can strike them through
+func (d *DiagnosticsProvider) checkDeprecatedUsages(doc *document.Document, _ *tree_sitter.Node) []protocol.Diagnostic {
+	diagnostics := make([]protocol.Diagnostic, 0)
+
+	for _, usage := range deprecatedUsages(doc, d.typeResolver) {
+		diagnostics = append(diagnostics, protocol.Diagnostic{
+			Range:    modelRangeToProtocol(usage.Range),
+			Severity: &[]protocol.DiagnosticSeverity{protocol.DiagnosticSeverityHint}[0],
+			Source:   &[]string{"frugal-ls"}[0],
+			Message:  usage.message(),
+			Tags:     []protocol.DiagnosticTag{protocol.DiagnosticTagDeprecated},
+		})
 	}
 
-	return "parameter list"
+	return diagnostics
 }
 
-// validateSingleFieldList validates field IDs within a single field list
-func (d *DiagnosticsProvider) validateSingleFieldList(doc *document.Document, fieldListNode *tree_sitter.Node, contextName string) []protocol.Diagnostic {
+// checkAnnotations reports unknown annotation keys, values that do not match the key's
+// type and keys repeated on the same declaration
+func (d *DiagnosticsProvider) checkAnnotations(doc *document.Document, _ *tree_sitter.Node) []protocol.Diagnostic {
 	diagnostics := make([]protocol.Diagnostic, 0)
-	seenFieldIds := make(map[int]*tree_sitter.Node)
+	addDiagnostic := func(rng ast.Range, severity protocol.DiagnosticSeverity, message string) {
+		diagnostics = append(diagnostics, protocol.Diagnostic{
+			Range:    modelRangeToProtocol(rng),
+			Severity: &[]protocol.DiagnosticSeverity{severity}[0],
+			Source:   &[]string{"frugal-ls"}[0],
+			Message:  message,
+		})
+	}
 
-	childCount := fieldListNode.ChildCount()
-	for i := uint(0); i < childCount; i++ {
-		child := fieldListNode.Child(i)
-		if child.Kind() == nodeTypeField {
-			fieldId, fieldIdNode := d.extractFieldId(child, doc.Content)
-			if fieldId == 0 {
+	for _, annotations := range declarationAnnotations(doc.GetModel()) {
+		seen := make(map[string]bool)
+		for _, annotation := range annotations {
+			if seen[annotation.Key] {
+				addDiagnostic(annotation.KeyRange, protocol.DiagnosticSeverityError,
+					fmt.Sprintf("Duplicate annotation '%s'", annotation.Key))
+				continue
+			}
+			seen[annotation.Key] = true
+
+			known, ok := d.annotationSchema.Lookup(annotation.Key)
+			if !ok {
+				addDiagnostic(annotation.KeyRange, protocol.DiagnosticSeverityWarning,
+					fmt.Sprintf("Unknown annotation '%s'", annotation.Key))
 				continue
 			}
+			if message := validateAnnotation(annotation, known); message != "" {
+				rng := annotation.ValueRange
+				if !annotation.HasValue {
+					rng = annotation.KeyRange
+				}
+				addDiagnostic(rng, protocol.DiagnosticSeverityError, message)
+			}
+		}
+	}
+
+	return diagnostics
+}
+
+// checkThrows reports throws lists naming types that are not exceptions, or the same
+// exception twice
+func (d *DiagnosticsProvider) checkThrows(doc *document.Document, _ *tree_sitter.Node) []protocol.Diagnostic {
+	diagnostics := make([]protocol.Diagnostic, 0)
+	checker := newValueChecker(doc, d.typeResolver)
+
+	for _, service := range doc.GetModel().Services {
+		for _, method := range service.Methods {
+			thrown := make(map[string]*ast.Field)
+			for _, field := range method.Throws {
+				declURI, def, ok := thrownDefinition(checker, field.Type, doc.URI)
+				if !ok {
+					continue // Unknown types are reported by FL006
+				}
+				if def == nil || def.Type != ast.NodeTypeException {
+					diagnostics = append(diagnostics, protocol.Diagnostic{
+						Range:    modelRangeToProtocol(field.Type.Range),
+						Severity: &[]protocol.DiagnosticSeverity{protocol.DiagnosticSeverityError}[0],
+						Source:   &[]string{"frugal-ls"}[0],
+						Message:  fmt.Sprintf("Type %s in throws list of %s is not an exception", field.Type.Text, method.Name),
+					})
+					continue
+				}
 
-			if existingNode, exists := seenFieldIds[fieldId]; exists {
-				diagnostic := protocol.Diagnostic{
-					Range:    d.nodeToRange(fieldIdNode, doc.Content),
+				key := declURI + "#" + def.Name
+				first, exists := thrown[key]
+				if !exists {
+					thrown[key] = field
+					continue
+				}
+				diagnostics = append(diagnostics, protocol.Diagnostic{
+					Range:    modelRangeToProtocol(field.Type.Range),
 					Severity: &[]protocol.DiagnosticSeverity{protocol.DiagnosticSeverityError}[0],
 					Source:   &[]string{"frugal-ls"}[0],
-					Message:  fmt.Sprintf("Duplicate field ID %d in %s", fieldId, contextName),
+					Message:  fmt.Sprintf("Exception %s is thrown more than once by %s", field.Type.Text, method.Name),
 					RelatedInformation: []protocol.DiagnosticRelatedInformation{{
-						Location: protocol.Location{
-							URI:   doc.URI,
-							Range: d.nodeToRange(existingNode, doc.Content),
-						},
-						Message: fmt.Sprintf("Field ID %d first used here", fieldId),
+						Location: protocol.Location{URI: doc.URI, Range: modelRangeToProtocol(first.Type.Range)},
+						Message:  "First thrown here",
 					}},
-				}
-				diagnostics = append(diagnostics, diagnostic)
-			} else {
-				seenFieldIds[fieldId] = fieldIdNode
+				})
 			}
 		}
 	}
@@ -299,118 +562,214 @@ func (d *DiagnosticsProvider) validateSingleFieldList(doc *document.Document, fi
 	return diagnostics
 }
 
-// checkUnusedImports checks for unused include statements
-func (d *DiagnosticsProvider) checkUnusedImports(doc *document.Document, root *tree_sitter.Node) []protocol.Diagnostic {
+// thrownDefinition resolves a thrown type through typedefs to its declaration and the
+// document declaring it. The definition is nil for base and container types; ok is false
+// if the type can't be resolved.
+func thrownDefinition(checker *valueChecker, typ *ast.TypeRef, uri string) (string, *ast.Definition, bool) {
+	if typ == nil {
+		return "", nil, false
+	}
+	typ, uri = checker.underlying(typ, uri)
+	if typ == nil {
+		return "", nil, false
+	}
+	if typ.Kind != ast.TypeRefUser {
+		return uri, nil, true
+	}
+
+	declURI, def, ok := checker.lookup(uri, typ.Include, typ.Name)
+	return declURI, def, ok && def != nil
+}
+
+// valueDiagnostics converts value problems to diagnostics
+func valueDiagnostics(problems []valueProblem) []protocol.Diagnostic {
+	diagnostics := make([]protocol.Diagnostic, 0, len(problems))
+	for _, problem := range problems {
+		diagnostics = append(diagnostics, protocol.Diagnostic{
+			Range:    modelRangeToProtocol(problem.Range),
+			Severity: &[]protocol.DiagnosticSeverity{protocol.DiagnosticSeverityError}[0],
+			Source:   &[]string{"frugal-ls"}[0],
+			Message:  problem.Message,
+		})
+	}
+	return diagnostics
+}
+
+// checkUnresolvedIncludes reports includes that do not resolve to an existing file
+func (d *DiagnosticsProvider) checkUnresolvedIncludes(doc *document.Document) []protocol.Diagnostic {
 	diagnostics := make([]protocol.Diagnostic, 0)
+	if d.includeResolver == nil {
+		return diagnostics
+	}
+
+	for _, include := range doc.GetModel().Includes {
+		if _, err := d.includeResolver.ResolveInclude(doc.URI, include.Path); err == nil {
+			continue
+		}
 
-	// For now, just check if includes exist - full cross-file analysis would be more complex
-	// This is a placeholder for future enhancement
+		diagnostic := protocol.Diagnostic{
+			Range:    modelRangeToProtocol(include.PathRange),
+			Severity: &[]protocol.DiagnosticSeverity{protocol.DiagnosticSeverityError}[0],
+			Source:   &[]string{"frugal-ls"}[0],
+			Message:  fmt.Sprintf("Cannot resolve include '%s'", include.Path),
+		}
+		diagnostics = append(diagnostics, diagnostic)
+	}
 
 	return diagnostics
 }
 
-// checkNamingConventions validates naming conventions
-func (d *DiagnosticsProvider) checkNamingConventions(doc *document.Document, root *tree_sitter.Node) []protocol.Diagnostic {
+// checkCircularIncludes reports includes that lead back to the including document
+func (d *DiagnosticsProvider) checkCircularIncludes(doc *document.Document) []protocol.Diagnostic {
 	diagnostics := make([]protocol.Diagnostic, 0)
+	if d.includeResolver == nil {
+		return diagnostics
+	}
 
-	d.walkDefinitions(root, doc.Content, func(defType, name string, node *tree_sitter.Node) {
-		if name == "" {
-			return
+	for _, include := range doc.GetModel().Includes {
+		target, err := d.includeResolver.ResolveInclude(doc.URI, include.Path)
+		if err != nil {
+			continue
 		}
 
+		cycle := d.includeResolver.CyclePath(doc.URI, target)
+		if cycle == nil {
+			continue
+		}
+
+		names := make([]string, len(cycle))
+		for i, uri := range cycle {
+			names[i] = filepath.Base(uri)
+		}
+
+		// Point at the include statement responsible for each step of the cycle
+		var related []protocol.DiagnosticRelatedInformation
+		for i := 0; i < len(cycle)-1; i++ {
+			rng, ok := d.includeResolver.IncludeRange(cycle[i], cycle[i+1])
+			if !ok {
+				continue
+			}
+			related = append(related, protocol.DiagnosticRelatedInformation{
+				Location: protocol.Location{URI: cycle[i], Range: modelRangeToProtocol(rng)},
+				Message:  fmt.Sprintf("%s includes %s", names[i], names[i+1]),
+			})
+		}
+
+		diagnostic := protocol.Diagnostic{
+			Range:              modelRangeToProtocol(include.PathRange),
+			Severity:           &[]protocol.DiagnosticSeverity{protocol.DiagnosticSeverityError}[0],
+			Source:             &[]string{"frugal-ls"}[0],
+			Message:            fmt.Sprintf("Circular include: %s", strings.Join(names, " -> ")),
+			RelatedInformation: related,
+		}
+		diagnostics = append(diagnostics, diagnostic)
+	}
+
+	return diagnostics
+}
+
+// checkNamingConventions validates naming conventions
+func (d *DiagnosticsProvider) checkNamingConventions(doc *document.Document, _ *tree_sitter.Node) []protocol.Diagnostic {
+	diagnostics := make([]protocol.Diagnostic, 0)
+
+	for _, def := range doc.GetModel().Definitions() {
 		var expectedPattern string
 		var severity protocol.DiagnosticSeverity
 
-		switch defType {
-		case diagnosticsNodeTypeService, diagnosticsNodeTypeStruct, diagnosticsNodeTypeException, diagnosticsNodeTypeEnum, diagnosticsNodeTypeScope:
+		switch def.Type {
+		case ast.NodeTypeService, ast.NodeTypeStruct, ast.NodeTypeException, ast.NodeTypeUnion, ast.NodeTypeEnum, ast.NodeTypeScope:
 			// Should be PascalCase
-			if !d.isPascalCase(name) {
+			if !d.isPascalCase(def.Name) {
 				expectedPattern = "PascalCase"
 				severity = protocol.DiagnosticSeverityWarning
 			}
-		case diagnosticsNodeTypeConst:
+		case ast.NodeTypeConst:
 			// Should be UPPER_SNAKE_CASE
-			if !d.isUpperSnakeCase(name) {
+			if !d.isUpperSnakeCase(def.Name) {
 				expectedPattern = "UPPER_SNAKE_CASE"
 				severity = protocol.DiagnosticSeverityWarning
 			}
 		}
 
 		if expectedPattern != "" {
+			defType := string(def.Type)
 			diagnostic := protocol.Diagnostic{
-				Range:    d.nodeToRange(node, doc.Content),
+				Range:    modelRangeToProtocol(def.NameRange),
 				Severity: &severity,
 				Source:   &[]string{"frugal-ls"}[0],
-				Message:  fmt.Sprintf("%s '%s' should follow %s naming convention", strings.ToUpper(defType[:1])+defType[1:], name, expectedPattern),
+				Message:  fmt.Sprintf("%s '%s' should follow %s naming convention", strings.ToUpper(defType[:1])+defType[1:], def.Name, expectedPattern),
 			}
 			diagnostics = append(diagnostics, diagnostic)
 		}
-	})
+	}
+
+	return diagnostics
+}
 
+// checkBreakingChanges reports wire-incompatible changes against the baseline version
+// of the document
+func (d *DiagnosticsProvider) checkBreakingChanges(doc *document.Document, _ *tree_sitter.Node) []protocol.Diagnostic {
+	d.configMutex.RLock()
+	baseline := d.baseline
+	d.configMutex.RUnlock()
+
+	diagnostics := make([]protocol.Diagnostic, 0)
+	if baseline == nil {
+		return diagnostics
+	}
+	base, ok := baseline.File(doc.Path)
+	if !ok {
+		return diagnostics
+	}
+
+	for _, change := range compat.Compare(base, doc.GetModel()) {
+		diagnostics = append(diagnostics, change.Diagnostic())
+	}
 	return diagnostics
 }
 
 // checkTypeReferences validates that referenced types exist
-func (d *DiagnosticsProvider) checkTypeReferences(doc *document.Document, root *tree_sitter.Node) []protocol.Diagnostic {
+func (d *DiagnosticsProvider) checkTypeReferences(doc *document.Document, _ *tree_sitter.Node) []protocol.Diagnostic {
 	diagnostics := make([]protocol.Diagnostic, 0)
 
-	// Collect all defined types
-	definedTypes := d.collectDefinedTypes(root, doc.Content)
+	// Collect all locally defined types
+	definedTypes := d.collectDefinedTypes(doc.GetModel())
 
-	// Check all type references
-	d.walkNodes(root, func(node *tree_sitter.Node) {
-		if node.Kind() == nodeTypeFieldType {
-			d.validateTypeReference(doc, node, definedTypes, &diagnostics)
+	for _, ref := range doc.GetModel().TypeRefs() {
+		if ref.Kind != ast.TypeRefUser || d.isKnownType(doc, ref, definedTypes) {
+			continue
 		}
-	})
+
+		diagnostic := protocol.Diagnostic{
+			Range:    modelRangeToProtocol(ref.Range),
+			Severity: &[]protocol.DiagnosticSeverity{protocol.DiagnosticSeverityError}[0],
+			Source:   &[]string{"frugal-ls"}[0],
+			Message:  fmt.Sprintf("Unknown type '%s'", ref.QualifiedName()),
+		}
+		diagnostics = append(diagnostics, diagnostic)
+	}
 
 	return diagnostics
 }
 
-// Helper methods
+// isKnownType reports whether a user type reference can be bound to a declaration.
+// Include-prefixed types are only checked when a type resolver is available and the
+// included file has been loaded.
+func (d *DiagnosticsProvider) isKnownType(doc *document.Document, ref *ast.TypeRef, definedTypes map[string]bool) bool {
+	if ref.Include == "" {
+		return definedTypes[ref.Name]
+	}
 
-// walkDefinitions walks through all top-level definitions
-func (d *DiagnosticsProvider) walkDefinitions(root *tree_sitter.Node, content []byte, callback func(defType, name string, node *tree_sitter.Node)) {
-	d.walkNodes(root, func(node *tree_sitter.Node) {
-		nodeType := node.Kind()
-		var defType, name string
-		var nameNode *tree_sitter.Node
-
-		switch nodeType {
-		case diagnosticsNodeTypeServiceDefinition:
-			defType = diagnosticsNodeTypeService
-			nameNode = ast.FindNodeByType(node, "identifier")
-		case nodeTypeStructDefinition:
-			defType = diagnosticsNodeTypeStruct
-			nameNode = ast.FindNodeByType(node, "identifier")
-		case diagnosticsNodeTypeExceptionDefinition:
-			defType = diagnosticsNodeTypeException
-			nameNode = ast.FindNodeByType(node, "identifier")
-		case diagnosticsNodeTypeEnumDefinition:
-			defType = diagnosticsNodeTypeEnum
-			nameNode = ast.FindNodeByType(node, "identifier")
-		case diagnosticsNodeTypeScopeDefinition:
-			defType = diagnosticsNodeTypeScope
-			nameNode = ast.FindNodeByType(node, "identifier")
-		case diagnosticsNodeTypeConstDefinition:
-			defType = diagnosticsNodeTypeConst
-			nameNode = ast.FindNodeByType(node, "identifier")
-		case diagnosticsNodeTypeTypedefDefinition:
-			defType = diagnosticsNodeTypeTypedef
-			// For typedef, find the alias name (second identifier)
-			identifiers := d.findAllNodes(node, "identifier")
-			if len(identifiers) > 0 {
-				nameNode = identifiers[len(identifiers)-1] // Last identifier is the alias
-			}
-		}
+	if d.typeResolver == nil {
+		return true
+	}
 
-		if nameNode != nil {
-			name = ast.GetText(nameNode, content)
-			callback(defType, name, nameNode)
-		}
-	})
+	_, err := d.typeResolver.ResolveTypeRef(doc.URI, ref)
+	return err == nil || errors.Is(err, workspace.ErrIncludeNotLoaded)
 }
 
+// Helper methods
+
 // walkNodes recursively walks all nodes in the tree
 func (d *DiagnosticsProvider) walkNodes(node *tree_sitter.Node, callback func(*tree_sitter.Node)) {
 	if node == nil {
@@ -439,28 +798,8 @@ func (d *DiagnosticsProvider) findAllNodes(root *tree_sitter.Node, nodeType stri
 	return nodes
 }
 
-// extractFieldId extracts field ID from a field node
-func (d *DiagnosticsProvider) extractFieldId(fieldNode *tree_sitter.Node, content []byte) (int, *tree_sitter.Node) {
-	fieldIdNode := ast.FindNodeByType(fieldNode, "field_id")
-	if fieldIdNode == nil {
-		return 0, nil
-	}
-
-	integerNode := ast.FindNodeByType(fieldIdNode, "integer")
-	if integerNode == nil {
-		return 0, nil
-	}
-
-	idText := ast.GetText(integerNode, content)
-	if id, err := strconv.Atoi(idText); err == nil {
-		return id, integerNode
-	}
-
-	return 0, nil
-}
-
 // collectDefinedTypes collects all defined type names
-func (d *DiagnosticsProvider) collectDefinedTypes(root *tree_sitter.Node, content []byte) map[string]bool {
+func (d *DiagnosticsProvider) collectDefinedTypes(model *ast.File) map[string]bool {
 	definedTypes := make(map[string]bool)
 
 	// Add built-in types
@@ -473,54 +812,14 @@ func (d *DiagnosticsProvider) collectDefinedTypes(root *tree_sitter.Node, conten
 	}
 
 	// Add user-defined types
-	d.walkDefinitions(root, content, func(defType, name string, node *tree_sitter.Node) {
-		if defType == diagnosticsNodeTypeStruct || defType == diagnosticsNodeTypeException || defType == diagnosticsNodeTypeEnum || defType == diagnosticsNodeTypeTypedef {
-			definedTypes[name] = true
+	for _, def := range model.Definitions() {
+		switch def.Type {
+		case ast.NodeTypeStruct, ast.NodeTypeException, ast.NodeTypeUnion, ast.NodeTypeEnum, ast.NodeTypeTypedef:
+			definedTypes[def.Name] = true
 		}
-	})
-
-	return definedTypes
-}
-
-// validateTypeReference validates a single type reference
-func (d *DiagnosticsProvider) validateTypeReference(doc *document.Document, typeNode *tree_sitter.Node, definedTypes map[string]bool, diagnostics *[]protocol.Diagnostic) {
-	// Extract the base type name
-	typeName := d.extractTypeName(typeNode, doc.Content)
-	if typeName == "" || definedTypes[typeName] {
-		return // Valid type or couldn't extract name
-	}
-
-	// Check if it might be a container type
-	if d.isContainerType(typeNode) {
-		return // Container types have their own validation
-	}
-
-	diagnostic := protocol.Diagnostic{
-		Range:    d.nodeToRange(typeNode, doc.Content),
-		Severity: &[]protocol.DiagnosticSeverity{protocol.DiagnosticSeverityError}[0],
-		Source:   &[]string{"frugal-ls"}[0],
-		Message:  fmt.Sprintf("Unknown type '%s'", typeName),
 	}
-	*diagnostics = append(*diagnostics, diagnostic)
-}
 
-// extractTypeName extracts the main type name from a field_type node
-func (d *DiagnosticsProvider) extractTypeName(typeNode *tree_sitter.Node, content []byte) string {
-	// Look for identifier or base_type
-	if identifier := ast.FindNodeByType(typeNode, "identifier"); identifier != nil {
-		return ast.GetText(identifier, content)
-	}
-
-	if baseType := ast.FindNodeByType(typeNode, "base_type"); baseType != nil {
-		return ast.GetText(baseType, content)
-	}
-
-	return ""
-}
-
-// isContainerType checks if this is a container type (list, set, map)
-func (d *DiagnosticsProvider) isContainerType(typeNode *tree_sitter.Node) bool {
-	return ast.FindNodeByType(typeNode, "container_type") != nil
+	return definedTypes
 }
 
 // nodeToRange converts a tree-sitter node to an LSP range
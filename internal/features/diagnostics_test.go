@@ -1,13 +1,17 @@
 package features
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	protocol "github.com/tliron/glsp/protocol_3_16"
 
+	"frugal-ls/internal/config"
 	"frugal-ls/internal/document"
 	"frugal-ls/internal/parser"
+	"frugal-ls/internal/workspace"
 	"frugal-ls/pkg/ast"
 )
 
@@ -303,6 +307,63 @@ struct User {
 	}
 }
 
+func TestDiagnosticsAnnotatedTypedefs(t *testing.T) {
+	content := `typedef i32 MyInt (deprecated = "use Count")
+typedef i64 OtherInt (deprecated)
+
+struct Counter {
+    1: MyInt value,
+    2: OtherInt other
+}`
+
+	doc := createTestDocumentForDiagnostics(t, "file:///test.frugal", content)
+	defer doc.ParseResult.Close()
+
+	// Annotation keys must not be mistaken for the typedef names
+	var messages []string
+	for _, diagnostic := range NewDiagnosticsProvider().ProvideDiagnostics(doc) {
+		messages = append(messages, diagnostic.Message)
+	}
+	expected := []string{
+		"'MyInt' is deprecated: use Count",
+		"'OtherInt' is deprecated",
+	}
+	if strings.Join(messages, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected %v, got %v", expected, messages)
+	}
+}
+
+func TestDiagnosticsIncludePrefixedTypes(t *testing.T) {
+	common := "struct User {\n    1: i64 id\n}"
+	content := `include "common.frugal"
+
+struct Account {
+    1: common.User owner,
+    2: common.Missing other
+}`
+
+	doc, resolver := openTestWorkspace(t, content, map[string]string{"common.frugal": common})
+
+	// Without a resolver, include-prefixed types are not checked
+	provider := NewDiagnosticsProvider()
+	for _, diagnostic := range provider.ProvideDiagnostics(doc) {
+		if strings.Contains(diagnostic.Message, "Unknown type") {
+			t.Errorf("Unexpected diagnostic without resolver: %s", diagnostic.Message)
+		}
+	}
+
+	provider.SetTypeResolver(resolver)
+	var unknown []string
+	for _, diagnostic := range provider.ProvideDiagnostics(doc) {
+		if strings.Contains(diagnostic.Message, "Unknown type") {
+			unknown = append(unknown, diagnostic.Message)
+		}
+	}
+	if len(unknown) != 1 || unknown[0] != "Unknown type 'common.Missing'" {
+		t.Errorf("Expected only common.Missing to be unknown, got %v", unknown)
+	}
+}
+
 func TestDiagnosticsMethodParametersVsThrowsFieldIds(t *testing.T) {
 	provider := NewDiagnosticsProvider()
 
@@ -391,6 +452,29 @@ func TestDiagnosticsParseErrors(t *testing.T) {
 	}
 }
 
+func TestDiagnosticsLintConfig(t *testing.T) {
+	content := `struct user_info {
+    1: Missing m
+}`
+
+	doc := createTestDocumentForDiagnostics(t, "file:///test.frugal", content)
+	defer doc.ParseResult.Close()
+
+	provider := NewDiagnosticsProvider()
+	provider.SetLintConfig(config.Lint{Rules: map[string]config.Severity{
+		RuleNamingConvention: config.SeverityError,
+		RuleUnknownType:      config.SeverityOff,
+	}})
+
+	diagnostics := provider.ProvideDiagnostics(doc)
+	if len(diagnostics) != 1 {
+		t.Fatalf("Expected only the naming diagnostic, got %+v", diagnostics)
+	}
+	if !strings.Contains(diagnostics[0].Message, "PascalCase") || *diagnostics[0].Severity != protocol.DiagnosticSeverityError {
+		t.Errorf("Expected naming convention error, got %+v", diagnostics[0])
+	}
+}
+
 func TestDiagnosticsNamingConventionHelpers(t *testing.T) {
 	provider := NewDiagnosticsProvider()
 
@@ -513,6 +597,39 @@ func createTestDocumentForDiagnostics(t *testing.T, uri, content string) *docume
 	return doc
 }
 
+// openTestWorkspace writes includes (file name to content) to a temporary directory,
+// opens them and main.frugal in a document manager, and returns the main document
+// and a type resolver over the workspace
+func openTestWorkspace(t *testing.T, main string, includes map[string]string) (*document.Document, *workspace.TypeResolver) {
+	t.Helper()
+
+	dir := t.TempDir()
+	manager, err := document.NewManager()
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	t.Cleanup(func() { manager.Close() })
+
+	mainURI := "file://" + filepath.Join(dir, "main.frugal")
+	files := map[string]string{mainURI: main}
+	for name, content := range includes {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write include: %v", err)
+		}
+		files["file://"+path] = content
+	}
+	for uri, text := range files {
+		if _, err := manager.DidOpen(&protocol.DidOpenTextDocumentParams{
+			TextDocument: protocol.TextDocumentItem{URI: uri, LanguageID: "frugal", Version: 1, Text: text},
+		}); err != nil {
+			t.Fatalf("Failed to open %s: %v", uri, err)
+		}
+	}
+	doc, _ := manager.GetDocument(mainURI)
+	return doc, workspace.NewTypeResolver(workspace.NewIncludeResolver([]string{dir}), manager)
+}
+
 func TestDiagnosticsNeverReturnNil(t *testing.T) {
 	provider := NewDiagnosticsProvider()
 
@@ -546,3 +663,132 @@ func TestDiagnosticsNeverReturnNil(t *testing.T) {
 		t.Error("ProvideDiagnostics should return empty array for empty content, not nil")
 	}
 }
+
+func TestReservedFieldDiagnostics(t *testing.T) {
+	content := `// @reserved 2, 3 "email"
+struct User {
+    1: string name,
+    2: i64 id,
+    4: string email
+}`
+
+	doc := createTestDocumentForDiagnostics(t, "file:///test.frugal", content)
+	defer doc.ParseResult.Close()
+
+	diagnostics := NewDiagnosticsProvider().ProvideDiagnostics(doc)
+	if len(diagnostics) != 2 {
+		t.Fatalf("Expected 2 diagnostics, got %+v", diagnostics)
+	}
+	if diagnostics[0].Message != "Field ID 2 is reserved in User" || diagnostics[0].Code.Value != "FL009" {
+		t.Errorf("Unexpected ID diagnostic: %+v", diagnostics[0])
+	}
+	if diagnostics[1].Message != "Field name 'email' is reserved in User" || diagnostics[1].Range.Start.Line != 4 {
+		t.Errorf("Unexpected name diagnostic: %+v", diagnostics[1])
+	}
+	related := diagnostics[0].RelatedInformation
+	if len(related) != 1 || related[0].Location.Range.Start.Line != 0 {
+		t.Errorf("Expected the reservation as related information, got %+v", related)
+	}
+}
+
+func TestThrowsDiagnostics(t *testing.T) {
+	content := `exception NotFound {
+    1: string message
+}
+
+struct Problem {
+    1: string message
+}
+
+typedef NotFound Missing
+
+service UserService {
+    void get(1: i64 id) throws (1: NotFound notFound, 2: Problem problem, 3: string reason),
+    void remove(1: i64 id) throws (1: NotFound notFound, 2: Missing missing, 3: Unknown unknown)
+}`
+
+	doc := createTestDocumentForDiagnostics(t, "file:///test.frugal", content)
+	defer doc.ParseResult.Close()
+
+	var throws []protocol.Diagnostic
+	for _, diagnostic := range NewDiagnosticsProvider().ProvideDiagnostics(doc) {
+		if diagnostic.Code.Value == "FL013" {
+			throws = append(throws, diagnostic)
+		}
+	}
+	expected := []string{
+		"Type Problem in throws list of get is not an exception",
+		"Type string in throws list of get is not an exception",
+		"Exception Missing is thrown more than once by remove",
+	}
+	if len(throws) != len(expected) {
+		t.Fatalf("Expected %v, got %+v", expected, throws)
+	}
+	for i, diagnostic := range throws {
+		if diagnostic.Message != expected[i] {
+			t.Errorf("Expected %q, got %q", expected[i], diagnostic.Message)
+		}
+	}
+	if related := throws[2].RelatedInformation; len(related) != 1 || related[0].Location.Range.Start.Character != 38 {
+		t.Errorf("Expected the first NotFound as related information, got %+v", related)
+	}
+}
+
+func TestUnionDiagnostics(t *testing.T) {
+	content := `union Value {
+    1: required string text,
+    2: i64 number = 5,
+    3: optional bool flag
+}
+
+struct Holder {
+    1: Value value
+}
+
+const Value ONE = {"text": "a"}
+const Value TWO = {"text": "a", "number": 1}`
+
+	doc := createTestDocumentForDiagnostics(t, "file:///test.frugal", content)
+	defer doc.ParseResult.Close()
+
+	var messages []string
+	for _, diagnostic := range NewDiagnosticsProvider().ProvideDiagnostics(doc) {
+		switch diagnostic.Code.Value {
+		case "FL014", "FL010", "FL006":
+			messages = append(messages, diagnostic.Message)
+		}
+	}
+	expected := []string{
+		"A Value value sets exactly one field, got 2",
+		"Union field 'text' cannot be required",
+		"Union field 'number' cannot have a default value",
+	}
+	if strings.Join(messages, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected %v, got %v", expected, messages)
+	}
+}
+
+func TestScopePrefixDiagnostics(t *testing.T) {
+	content := `scope UserEvents prefix "user.{region}.{region}.}" {
+    Created: string
+}`
+
+	doc := createTestDocumentForDiagnostics(t, "file:///test.frugal", content)
+	defer doc.ParseResult.Close()
+
+	var prefixDiagnostics []protocol.Diagnostic
+	for _, diagnostic := range NewDiagnosticsProvider().ProvideDiagnostics(doc) {
+		if diagnostic.Code.Value == "FL015" {
+			prefixDiagnostics = append(prefixDiagnostics, diagnostic)
+		}
+	}
+	if len(prefixDiagnostics) != 2 {
+		t.Fatalf("Expected 2 prefix diagnostics, got %+v", prefixDiagnostics)
+	}
+	if prefixDiagnostics[0].Message != "Duplicate variable 'region' in scope prefix" || prefixDiagnostics[0].Range.Start.Character != 39 {
+		t.Errorf("Unexpected duplicate diagnostic: %+v", prefixDiagnostics[0])
+	}
+	if prefixDiagnostics[1].Message != "Unmatched '}' in scope prefix" {
+		t.Errorf("Unexpected brace diagnostic: %+v", prefixDiagnostics[1])
+	}
+}
@@ -0,0 +1,90 @@
+package features
+
+import (
+	"fmt"
+	"strings"
+
+	"frugal-ls/pkg/ast"
+)
+
+// definitionDoc returns the doc comment of a top-level definition, or nil
+func definitionDoc(model *ast.File, def *ast.Definition) *ast.DocComment {
+	switch def.Type {
+	case ast.NodeTypeStruct, ast.NodeTypeException, ast.NodeTypeUnion:
+		if structDef := model.FindStruct(def.Name); structDef != nil {
+			return structDef.Doc
+		}
+	case ast.NodeTypeEnum:
+		if enum := model.FindEnum(def.Name); enum != nil {
+			return enum.Doc
+		}
+	case ast.NodeTypeTypedef:
+		if typedef := model.FindTypedef(def.Name); typedef != nil {
+			return typedef.Doc
+		}
+	case ast.NodeTypeService:
+		if service := model.FindService(def.Name); service != nil {
+			return service.Doc
+		}
+	case ast.NodeTypeConst:
+		if constDef := model.FindConst(def.Name); constDef != nil {
+			return constDef.Doc
+		}
+	case ast.NodeTypeScope:
+		if scope := model.FindScope(def.Name); scope != nil {
+			return scope.Doc
+		}
+	}
+	return nil
+}
+
+// formatDocComment renders a doc comment as Markdown, preceded by a blank line, or ""
+// if there is none. @param and @throws tags are listed under headings; @deprecated is
+// left to formatDeprecation.
+func formatDocComment(doc *ast.DocComment) string {
+	if doc == nil {
+		return ""
+	}
+
+	var sections []string
+	if doc.Text != "" {
+		sections = append(sections, doc.Text)
+	}
+
+	var params, throws, returns, others []string
+	for _, tag := range doc.Tags {
+		switch tag.Name {
+		case "param":
+			params = append(params, formatDocTagItem(tag))
+		case "throws", "exception":
+			throws = append(throws, formatDocTagItem(tag))
+		case "deprecated":
+			// Rendered by formatDeprecation
+		case "return", "returns":
+			returns = append(returns, fmt.Sprintf("**Returns**: %s", tag.Text))
+		default:
+			others = append(others, strings.TrimSpace(fmt.Sprintf("*@%s* %s", tag.Name, tag.Text)))
+		}
+	}
+	if len(params) > 0 {
+		sections = append(sections, "**Parameters:**\n"+strings.Join(params, "\n"))
+	}
+	if len(throws) > 0 {
+		sections = append(sections, "**Throws:**\n"+strings.Join(throws, "\n"))
+	}
+	sections = append(sections, returns...)
+	sections = append(sections, others...)
+
+	if len(sections) == 0 {
+		return ""
+	}
+	return "\n\n" + strings.Join(sections, "\n\n")
+}
+
+// formatDocTagItem renders a @param or @throws tag as a list item
+func formatDocTagItem(tag *ast.DocTag) string {
+	if tag.Text == "" {
+		return fmt.Sprintf("- `%s`", tag.Target)
+	}
+	return fmt.Sprintf("- `%s`: %s", tag.Target, tag.Text)
+}
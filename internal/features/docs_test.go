@@ -0,0 +1,38 @@
+package features
+
+import (
+	"testing"
+
+	"frugal-ls/pkg/ast"
+)
+
+func TestFormatDocComment(t *testing.T) {
+	tests := map[string]struct {
+		doc      *ast.DocComment
+		expected string
+	}{
+		"none":        {nil, ""},
+		"description": {&ast.DocComment{Text: "A user."}, "\n\nA user."},
+		"tags": {&ast.DocComment{
+			Text: "Looks up a user.",
+			Tags: []*ast.DocTag{
+				{Name: "return", Text: "the user"},
+				{Name: "param", Target: "id", Text: "the user's ID"},
+				{Name: "throws", Target: "NotFound"},
+				{Name: "param", Target: "region", Text: "where to look"},
+				{Name: "deprecated", Text: "use find"},
+				{Name: "since", Text: "1.2"},
+			},
+		}, "\n\nLooks up a user.\n\n" +
+			"**Parameters:**\n- `id`: the user's ID\n- `region`: where to look\n\n" +
+			"**Throws:**\n- `NotFound`\n\n" +
+			"**Returns**: the user\n\n" +
+			"*@since* 1.2"},
+		"only deprecated": {&ast.DocComment{Tags: []*ast.DocTag{{Name: "deprecated"}}}, ""},
+	}
+	for name, tt := range tests {
+		if got := formatDocComment(tt.doc); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", name, tt.expected, got)
+		}
+	}
+}
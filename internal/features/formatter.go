@@ -49,24 +49,29 @@ func NewFrugalFormatter(options protocol.FormattingOptions) *FrugalFormatter {
 	}
 
 	// Apply LSP formatting options
-	if tabSize, ok := options["tabSize"]; ok {
-		if ts, ok := tabSize.(float64); ok {
-			formatter.indentSize = int(ts)
-		} else if ts, ok := tabSize.(int); ok {
-			formatter.indentSize = ts
-		}
+	if tabSize, ok := intOption(options, protocol.FormattingOptionTabSize); ok {
+		formatter.indentSize = tabSize
 	}
 
-	if insertSpaces, ok := options["insertSpaces"]; ok {
-		if is, ok := insertSpaces.(bool); ok {
-			formatter.insertSpaces = is
-			formatter.useSpaces = is
-		}
+	if insertSpaces, ok := options[protocol.FormattingOptionInsertSpaces].(bool); ok {
+		formatter.insertSpaces = insertSpaces
+		formatter.useSpaces = insertSpaces
 	}
 
 	return formatter
 }
 
+// intOption reads an integer option, which is a float64 when decoded from JSON
+func intOption(options protocol.FormattingOptions, key string) (int, bool) {
+	switch value := options[key].(type) {
+	case int:
+		return value, true
+	case float64:
+		return int(value), true
+	}
+	return 0, false
+}
+
 // FormatDocument formats the entire Frugal document using AST
 func (f *FrugalFormatter) FormatDocument(doc *document.Document) (string, error) {
 	if doc.ParseResult == nil || doc.ParseResult.GetRootNode() == nil {
@@ -123,7 +128,9 @@ func (f *FrugalFormatter) formatNode(node *tree_sitter.Node, source []byte, inde
 	case diagnosticsNodeTypeScopeDefinition:
 		return f.formatScope(node, source, indentLevel)
 	case nodeTypeStructDefinition:
-		return f.formatStruct(node, source, indentLevel)
+		return f.formatStruct(node, source, indentLevel, diagnosticsNodeTypeStruct)
+	case diagnosticsNodeTypeUnionDefinition:
+		return f.formatStruct(node, source, indentLevel, diagnosticsNodeTypeUnion)
 	case diagnosticsNodeTypeEnumDefinition:
 		return f.formatEnum(node, source, indentLevel)
 	case diagnosticsNodeTypeExceptionDefinition:
@@ -278,8 +285,8 @@ func (f *FrugalFormatter) shouldAddSectionBreak(nodeType string) bool {
 		return true
 	case diagnosticsNodeTypeServiceDefinition, diagnosticsNodeTypeScopeDefinition:
 		return true
-	case nodeTypeStructDefinition, diagnosticsNodeTypeExceptionDefinition:
-		return false // Group structs and exceptions together
+	case nodeTypeStructDefinition, diagnosticsNodeTypeExceptionDefinition, diagnosticsNodeTypeUnionDefinition:
+		return false // Group structs, exceptions and unions together
 	case diagnosticsNodeTypeEnumDefinition:
 		return true // Separate enums from other definitions
 	case diagnosticsNodeTypeConstDefinition, diagnosticsNodeTypeTypedefDefinition:
@@ -347,7 +354,11 @@ func (f *FrugalFormatter) formatService(node *tree_sitter.Node, source []byte, i
 	bodyIndent := f.getIndent(indentLevel + 1)
 
 	var result strings.Builder
-	result.WriteString(fmt.Sprintf("%sservice %s {", indent, serviceName))
+	result.WriteString(fmt.Sprintf("%sservice %s", indent, serviceName))
+	if extends := f.extractExtends(node, source); extends != "" {
+		result.WriteString(" extends " + extends)
+	}
+	result.WriteString(" {")
 
 	// Format service methods
 	serviceBody := ast.FindNodeByType(node, "service_body")
@@ -367,6 +378,7 @@ func (f *FrugalFormatter) formatService(node *tree_sitter.Node, source []byte, i
 	}
 
 	result.WriteString("}")
+	result.WriteString(f.formatAnnotations(node, source))
 	return result.String()
 }
 
@@ -524,11 +536,12 @@ func (f *FrugalFormatter) formatScope(node *tree_sitter.Node, source []byte, ind
 	}
 
 	result.WriteString("}")
+	result.WriteString(f.formatAnnotations(node, source))
 	return result.String()
 }
 
-// formatStruct formats struct definitions
-func (f *FrugalFormatter) formatStruct(node *tree_sitter.Node, source []byte, indentLevel int) string {
+// formatStruct formats struct and union definitions, which share a body syntax
+func (f *FrugalFormatter) formatStruct(node *tree_sitter.Node, source []byte, indentLevel int, keyword string) string {
 	// Check if struct contains comments - if so, use conservative formatting
 	if f.nodeContainsComments(node, source) {
 		return f.formatConservatively(node, source, indentLevel)
@@ -543,7 +556,7 @@ func (f *FrugalFormatter) formatStruct(node *tree_sitter.Node, source []byte, in
 	bodyIndent := f.getIndent(indentLevel + 1)
 
 	var result strings.Builder
-	result.WriteString(fmt.Sprintf("%sstruct %s {", indent, structName))
+	result.WriteString(fmt.Sprintf("%s%s %s {", indent, keyword, structName))
 
 	// Format struct fields
 	structBody := ast.FindNodeByType(node, "struct_body")
@@ -569,6 +582,7 @@ func (f *FrugalFormatter) formatStruct(node *tree_sitter.Node, source []byte, in
 	}
 
 	result.WriteString("}")
+	result.WriteString(f.formatAnnotations(node, source))
 	return result.String()
 }
 
@@ -609,6 +623,7 @@ func (f *FrugalFormatter) formatEnum(node *tree_sitter.Node, source []byte, inde
 	}
 
 	result.WriteString("}")
+	result.WriteString(f.formatAnnotations(node, source))
 	return result.String()
 }
 
@@ -654,6 +669,7 @@ func (f *FrugalFormatter) formatException(node *tree_sitter.Node, source []byte,
 	}
 
 	result.WriteString("}")
+	result.WriteString(f.formatAnnotations(node, source))
 	return result.String()
 }
 
@@ -699,7 +715,7 @@ func (f *FrugalFormatter) formatTypedef(node *tree_sitter.Node, source []byte, i
 	}
 
 	if baseType != "" && aliasName != "" {
-		return fmt.Sprintf("%stypedef %s %s", indent, baseType, aliasName)
+		return fmt.Sprintf("%stypedef %s %s%s", indent, baseType, aliasName, f.formatAnnotations(node, source))
 	}
 
 	// Fallback to regex
@@ -714,6 +730,40 @@ func (f *FrugalFormatter) formatTypedef(node *tree_sitter.Node, source []byte, i
 	return indent + nodeText
 }
 
+// formatAnnotations formats the annotation list attached to a declaration as
+// ` (key = "value", key)`, or "" if it has none
+func (f *FrugalFormatter) formatAnnotations(node *tree_sitter.Node, source []byte) string {
+	var annotationNode *tree_sitter.Node
+	childCount := node.ChildCount()
+	for i := uint(0); i < childCount; i++ {
+		if child := node.Child(i); child.Kind() == "annotation" {
+			annotationNode = child
+		}
+	}
+	if annotationNode == nil {
+		return ""
+	}
+	list := ast.FindNodeByType(annotationNode, "annotation_list")
+	if list == nil {
+		return " " + strings.TrimSpace(ast.GetText(annotationNode, source))
+	}
+
+	var entries []string
+	listCount := list.ChildCount()
+	for i := uint(0); i < listCount; i++ {
+		child := list.Child(i)
+		switch child.Kind() {
+		case nodeTypeIdentifier:
+			entries = append(entries, ast.GetText(child, source))
+		case "literal_string":
+			if len(entries) > 0 {
+				entries[len(entries)-1] += " = " + ast.GetText(child, source)
+			}
+		}
+	}
+	return " (" + strings.Join(entries, ", ") + ")"
+}
+
 // formatGenericNode provides default formatting for unrecognized nodes
 func (f *FrugalFormatter) formatGenericNode(node *tree_sitter.Node, source []byte, indentLevel int) string {
 	indent := f.getIndent(indentLevel)
@@ -732,6 +782,23 @@ func (f *FrugalFormatter) extractIdentifier(node *tree_sitter.Node, source []byt
 	return ""
 }
 
+// extractExtends extracts the name of the service a service extends, if any
+func (f *FrugalFormatter) extractExtends(node *tree_sitter.Node, source []byte) string {
+	identifiers := 0
+	childCount := node.ChildCount()
+	for i := uint(0); i < childCount; i++ {
+		child := node.Child(i)
+		if child.Kind() != nodeTypeIdentifier {
+			continue
+		}
+		identifiers++
+		if identifiers == 2 {
+			return ast.GetText(child, source)
+		}
+	}
+	return ""
+}
+
 // extractScopePrefix extracts the prefix from a scope definition
 func (f *FrugalFormatter) extractScopePrefix(node *tree_sitter.Node, source []byte) string {
 	// Look for scope_prefix child node first
@@ -71,6 +71,51 @@ func TestFormatterStruct(t *testing.T) {
 	}
 }
 
+func TestFormatterUnion(t *testing.T) {
+	unformatted := `union Value{
+1:string text,
+2:i64 number
+}`
+
+	expected := `union Value {
+    1: string text,
+    2: i64 number
+}`
+
+	result := normalizeWhitespace(testFormat(t, unformatted))
+	expected = normalizeWhitespace(expected)
+
+	if result != expected {
+		t.Errorf("Union formatting failed.\nExpected:\n%s\nGot:\n%s", expected, result)
+	}
+}
+
+func TestFormatterAnnotations(t *testing.T) {
+	unformatted := `typedef i64 UserID (go.name="ID")
+service Users extends Base{
+void ping() (deprecated="no")
+}(go.name = "UserService")
+struct User{
+1:string id (go.tag="json:\"id\"";deprecated)
+}  ( java.name = "Account" )`
+
+	expected := `typedef i64 UserID (go.name = "ID")
+service Users extends Base {
+    void ping() (deprecated="no")
+} (go.name = "UserService")
+
+struct User {
+    1: string id (go.tag="json:\"id\"";deprecated)
+} (java.name = "Account")`
+
+	result := normalizeWhitespace(testFormat(t, unformatted))
+	expected = normalizeWhitespace(expected)
+
+	if result != expected {
+		t.Errorf("Annotation formatting failed.\nExpected:\n%s\nGot:\n%s", expected, result)
+	}
+}
+
 func TestFormatterEnum(t *testing.T) {
 	unformatted := `enum Status{
 ACTIVE=1,
@@ -251,6 +296,22 @@ func TestFormatterFieldAlignment(t *testing.T) {
 	t.Logf("Field alignment result:\n%s", strings.Join(fieldLines, "\n"))
 }
 
+func TestNewFrugalFormatterOptions(t *testing.T) {
+	formatter := NewFrugalFormatter(protocol.FormattingOptions{
+		"tabSize":      float64(2),
+		"insertSpaces": false,
+	})
+
+	if formatter.indentSize != 2 || formatter.useSpaces {
+		t.Errorf("Expected options to override defaults, got %+v", formatter)
+	}
+
+	defaults := NewFrugalFormatter(protocol.FormattingOptions{})
+	if defaults.indentSize != 4 || defaults.maxLineLength != 100 || !defaults.alignFields || !defaults.sortImports {
+		t.Errorf("Expected defaults without options, got %+v", defaults)
+	}
+}
+
 func TestFormatterMultiLineComments(t *testing.T) {
 	tests := []struct {
 		name     string
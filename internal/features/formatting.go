@@ -1,27 +1,46 @@
 package features
 
 import (
+	"regexp"
+	"sort"
 	"strings"
+	"sync"
 
 	protocol "github.com/tliron/glsp/protocol_3_16"
 
+	"frugal-ls/internal/config"
 	"frugal-ls/internal/document"
 )
 
 // FormattingProvider handles document formatting for Frugal files
-type FormattingProvider struct{}
+type FormattingProvider struct {
+	format config.Format
+	mutex  sync.RWMutex
+}
 
 // NewFormattingProvider creates a new formatting provider
 func NewFormattingProvider() *FormattingProvider {
 	return &FormattingProvider{}
 }
 
+// SetFormat sets formatter settings that take precedence over the request's options
+func (f *FormattingProvider) SetFormat(format config.Format) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.format = format
+}
+
 // ProvideDocumentFormatting formats an entire document
 func (f *FormattingProvider) ProvideDocumentFormatting(doc *document.Document, options protocol.FormattingOptions) ([]protocol.TextEdit, error) {
 	if !doc.IsValidFrugalFile() {
 		return nil, nil
 	}
 
+	f.mutex.RLock()
+	options = f.format.Apply(options)
+	f.mutex.RUnlock()
+
 	// Use conservative indentation-only formatting
 	formattedContent := f.formatDocumentConservatively(doc.Content, options)
 
@@ -70,10 +89,11 @@ func (f *FormattingProvider) getIndentString(options protocol.FormattingOptions)
 	return "\t"
 }
 
-// isOpeningBrace checks if a line contains an opening brace that increases indentation
+// isOpeningBrace checks if a line contains an opening brace, or ends with the opening
+// parenthesis of a wrapped parameter list, that increases indentation
 func (f *FormattingProvider) isOpeningBrace(line string) bool {
 	line = strings.TrimSpace(line)
-	return strings.HasSuffix(line, "{") ||
+	return strings.HasSuffix(line, "{") || strings.HasSuffix(line, "(") ||
 		strings.Contains(line, "service ") && strings.HasSuffix(line, "{") ||
 		strings.Contains(line, "scope ") && strings.HasSuffix(line, "{") ||
 		strings.Contains(line, "struct ") && strings.HasSuffix(line, "{") ||
@@ -81,10 +101,11 @@ func (f *FormattingProvider) isOpeningBrace(line string) bool {
 		strings.Contains(line, "exception ") && strings.HasSuffix(line, "{")
 }
 
-// isClosingBrace checks if a line contains a closing brace that decreases indentation
+// isClosingBrace checks if a line contains a closing brace or parenthesis that decreases
+// indentation
 func (f *FormattingProvider) isClosingBrace(line string) bool {
 	line = strings.TrimSpace(line)
-	return line == "}" || strings.HasPrefix(line, "}")
+	return strings.HasPrefix(line, "}") || strings.HasPrefix(line, ")")
 }
 
 // formatDocumentConservatively applies proper indentation and basic spacing normalization
@@ -94,6 +115,11 @@ func (f *FormattingProvider) formatDocumentConservatively(source []byte, options
 
 	indentLevel := 0
 	indentString := f.getIndentString(options)
+	tabSize, ok := intOption(options, protocol.FormattingOptionTabSize)
+	if !ok || tabSize <= 0 {
+		tabSize = 4
+	}
+	maxLineLength, _ := intOption(options, config.FormattingOptionMaxLineLength)
 
 	for _, line := range lines {
 		trimmedLine := strings.TrimSpace(line)
@@ -127,8 +153,21 @@ func (f *FormattingProvider) formatDocumentConservatively(source []byte, options
 
 		// Apply proper indentation and basic spacing normalization
 		normalizedLine := f.normalizeSpacing(trimmedLine)
-		formattedLine := strings.Repeat(indentString, indentLevel) + normalizedLine
-		formattedLines = append(formattedLines, formattedLine)
+		indent := strings.Repeat(indentString, indentLevel)
+		formattedLine := indent + normalizedLine
+		var wrapped []string
+		if maxLineLength > 0 && indentLevel > 0 && lineWidth(formattedLine, tabSize) > maxLineLength {
+			wrapped = wrapParameters(normalizedLine)
+		}
+		if wrapped == nil {
+			formattedLines = append(formattedLines, formattedLine)
+		} else {
+			formattedLines = append(formattedLines, indent+wrapped[0])
+			for _, param := range wrapped[1 : len(wrapped)-1] {
+				formattedLines = append(formattedLines, indent+indentString+param)
+			}
+			formattedLines = append(formattedLines, indent+wrapped[len(wrapped)-1])
+		}
 
 		// Increase indent level for opening braces
 		if f.isOpeningBrace(trimmedLine) {
@@ -136,9 +175,167 @@ func (f *FormattingProvider) formatDocumentConservatively(source []byte, options
 		}
 	}
 
+	if alignFields, _ := options[config.FormattingOptionAlignFields].(bool); alignFields {
+		alignColumns(formattedLines)
+	}
+	if sortImports, _ := options[config.FormattingOptionSortImports].(bool); sortImports {
+		sortIncludes(formattedLines)
+	}
+
 	return strings.Join(formattedLines, "\n")
 }
 
+// lineWidth returns the display width of a line with tabs expanded to tabSize columns
+func lineWidth(line string, tabSize int) int {
+	return len(line) + strings.Count(line, "\t")*(tabSize-1)
+}
+
+// wrapParameters splits a method's parameter list one parameter per line. It returns
+// the line up to the opening parenthesis, each parameter, and the rest of the line from
+// the closing parenthesis, or nil if the line has no parameter list. Annotations, which
+// are separated from what they annotate by a space, are left alone.
+func wrapParameters(line string) []string {
+	open := strings.IndexByte(line, '(')
+	if open <= 0 || !isIdentifierByte(line[open-1]) {
+		return nil
+	}
+
+	var params []string
+	depth, start := 0, open+1
+	var quote byte
+	for i := open + 1; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(' || c == '<' || c == '[' || c == '{':
+			depth++
+		case (c == ')' || c == '>' || c == ']' || c == '}') && depth > 0:
+			depth--
+		case c == ',' && depth == 0:
+			params = append(params, strings.TrimSpace(line[start:i])+",")
+			start = i + 1
+		case c == ')':
+			if last := strings.TrimSpace(line[start:i]); last != "" {
+				params = append(params, last)
+			}
+			if len(params) == 0 {
+				return nil
+			}
+			return append(append([]string{line[:open+1]}, params...), line[i:])
+		}
+	}
+	return nil
+}
+
+// isIdentifierByte reports whether c can appear in an identifier
+func isIdentifierByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// fieldLinePattern matches a field's ID and requiredness, followed by its type and name
+var fieldLinePattern = regexp.MustCompile(`^(-?\d+:) ((?:required |optional )?)(.+)$`)
+
+// enumValueLinePattern matches an enum value with an explicit value
+var enumValueLinePattern = regexp.MustCompile(`^([A-Za-z_]\w*) = (.+)$`)
+
+// alignedColumns is a line split into the columns alignColumns pads. Only the first two
+// are padded; the last holds the rest of the line.
+type alignedColumns struct {
+	kind    string
+	indent  string
+	columns [3]string
+}
+
+// splitColumns splits a field or enum value line into columns, or returns false for
+// other lines. Top-level lines are never aligned.
+func splitColumns(line string) (alignedColumns, bool) {
+	trimmed := strings.TrimLeft(line, " \t")
+	indent := line[:len(line)-len(trimmed)]
+	if indent == "" {
+		return alignedColumns{}, false
+	}
+
+	if m := fieldLinePattern.FindStringSubmatch(trimmed); m != nil {
+		// The type ends at the first space outside angle brackets
+		depth := 0
+		for i := 0; i < len(m[3]); i++ {
+			switch m[3][i] {
+			case '<':
+				depth++
+			case '>':
+				depth--
+			case ' ':
+				if depth == 0 && i+1 < len(m[3]) && isIdentifierByte(m[3][i+1]) {
+					return alignedColumns{kind: "field", indent: indent, columns: [3]string{m[1], m[2] + m[3][:i], m[3][i+1:]}}, true
+				}
+			}
+		}
+		return alignedColumns{}, false
+	}
+	if m := enumValueLinePattern.FindStringSubmatch(trimmed); m != nil {
+		return alignedColumns{kind: "enum", indent: indent, columns: [3]string{m[1], "=", m[2]}}, true
+	}
+	return alignedColumns{}, false
+}
+
+// alignColumns pads each run of consecutive fields, or of enum values, that share an
+// indentation so that their types, names and values line up
+func alignColumns(lines []string) {
+	for start := 0; start < len(lines); {
+		first, ok := splitColumns(lines[start])
+		if !ok {
+			start++
+			continue
+		}
+
+		run := []alignedColumns{first}
+		for end := start + 1; end < len(lines); end++ {
+			next, ok := splitColumns(lines[end])
+			if !ok || next.kind != first.kind || next.indent != first.indent {
+				break
+			}
+			run = append(run, next)
+		}
+
+		var widths [2]int
+		for _, line := range run {
+			for i := range widths {
+				widths[i] = max(widths[i], len(line.columns[i]))
+			}
+		}
+		for i, line := range run {
+			lines[start+i] = line.indent +
+				line.columns[0] + strings.Repeat(" ", widths[0]-len(line.columns[0])) + " " +
+				line.columns[1] + strings.Repeat(" ", widths[1]-len(line.columns[1])) + " " +
+				line.columns[2]
+		}
+		start += len(run)
+	}
+}
+
+// sortIncludes sorts each run of consecutive include lines
+func sortIncludes(lines []string) {
+	for start := 0; start < len(lines); {
+		end := start
+		for end < len(lines) && strings.HasPrefix(lines[end], "include ") {
+			end++
+		}
+		if end == start {
+			start++
+			continue
+		}
+		sort.Strings(lines[start:end])
+		start = end
+	}
+}
+
 // normalizeSpacing normalizes spacing in a line (convert tabs to spaces, fix multiple spaces)
 func (f *FormattingProvider) normalizeSpacing(line string) string {
 	// Replace tabs with spaces
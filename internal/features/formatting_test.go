@@ -5,6 +5,7 @@ import (
 
 	protocol "github.com/tliron/glsp/protocol_3_16"
 
+	"frugal-ls/internal/config"
 	"frugal-ls/internal/document"
 )
 
@@ -345,6 +346,147 @@ func TestProvideDocumentRangeFormatting(t *testing.T) {
 	}
 }
 
+func TestProvideDocumentFormattingConfiguredOptions(t *testing.T) {
+	content := `include "z.frugal"
+include "a.frugal"
+
+struct User {
+1: required string name,
+10: map<string, i32> counts
+}
+
+enum Status {
+ACTIVE = 1
+DELETED = 2
+}
+
+service Users {
+User find(1: string name, 2: map<string, i32> filters) throws (1: NotFound nf)
+}`
+
+	testCases := []struct {
+		name     string
+		format   config.Format
+		expected string
+	}{
+		{
+			name:   "defaults",
+			format: config.Format{},
+			expected: `include "z.frugal"
+include "a.frugal"
+
+struct User {
+    1: required string name,
+    10: map<string, i32> counts
+}
+
+enum Status {
+    ACTIVE = 1
+    DELETED = 2
+}
+
+service Users {
+    User find(1: string name, 2: map<string, i32> filters) throws (1: NotFound nf)
+}`,
+		},
+		{
+			name:   "maxLineLength",
+			format: config.Format{MaxLineLength: &[]int{60}[0]},
+			expected: `include "z.frugal"
+include "a.frugal"
+
+struct User {
+    1: required string name,
+    10: map<string, i32> counts
+}
+
+enum Status {
+    ACTIVE = 1
+    DELETED = 2
+}
+
+service Users {
+    User find(
+        1: string name,
+        2: map<string, i32> filters
+    ) throws (1: NotFound nf)
+}`,
+		},
+		{
+			name:   "alignFields",
+			format: config.Format{AlignFields: &[]bool{true}[0]},
+			expected: `include "z.frugal"
+include "a.frugal"
+
+struct User {
+    1:  required string  name,
+    10: map<string, i32> counts
+}
+
+enum Status {
+    ACTIVE  = 1
+    DELETED = 2
+}
+
+service Users {
+    User find(1: string name, 2: map<string, i32> filters) throws (1: NotFound nf)
+}`,
+		},
+		{
+			name:   "sortImports",
+			format: config.Format{SortImports: &[]bool{true}[0]},
+			expected: `include "a.frugal"
+include "z.frugal"
+
+struct User {
+    1: required string name,
+    10: map<string, i32> counts
+}
+
+enum Status {
+    ACTIVE = 1
+    DELETED = 2
+}
+
+service Users {
+    User find(1: string name, 2: map<string, i32> filters) throws (1: NotFound nf)
+}`,
+		},
+	}
+
+	options := protocol.FormattingOptions{
+		"tabSize":      4,
+		"insertSpaces": true,
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc := &document.Document{
+				URI:     "file:///test.frugal",
+				Path:    "/test.frugal",
+				Content: []byte(content),
+				Version: 1,
+			}
+
+			provider := NewFormattingProvider()
+			provider.SetFormat(tc.format)
+			edits, err := provider.ProvideDocumentFormatting(doc, options)
+			if err != nil || len(edits) != 1 {
+				t.Fatalf("Expected 1 edit, got %v, %v", edits, err)
+			}
+			if edits[0].NewText != tc.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tc.expected, edits[0].NewText)
+			}
+
+			// Formatting the result again must not change it
+			doc.Content = []byte(edits[0].NewText)
+			if again, _ := provider.ProvideDocumentFormatting(doc, options); len(again) != 0 {
+				t.Errorf("Expected formatting to be stable, got:\n%s", again[0].NewText)
+			}
+		})
+	}
+}
+
 func TestFormattingProviderEdgeCases(t *testing.T) {
 	testCases := []struct {
 		name     string
@@ -86,7 +86,7 @@ func (p *DocumentHighlightProvider) getHighlightKind(doc *document.Document, r p
 	context := p.getSymbolContext(node)
 
 	switch context {
-	case diagnosticsNodeTypeStruct, diagnosticsNodeTypeService, diagnosticsNodeTypeEnum, diagnosticsNodeTypeException, diagnosticsNodeTypeScope:
+	case diagnosticsNodeTypeStruct, diagnosticsNodeTypeService, diagnosticsNodeTypeEnum, diagnosticsNodeTypeException, diagnosticsNodeTypeUnion, diagnosticsNodeTypeScope:
 		// Type definitions
 		if p.isDeclaration(node) {
 			kind := protocol.DocumentHighlightKindWrite
@@ -127,7 +127,7 @@ func (p *DocumentHighlightProvider) isDeclaration(node *tree_sitter.Node) bool {
 	// Check various declaration contexts
 	switch parent.Kind() {
 	case nodeTypeStructDefinition, diagnosticsNodeTypeServiceDefinition, diagnosticsNodeTypeEnumDefinition,
-		diagnosticsNodeTypeExceptionDefinition, diagnosticsNodeTypeScopeDefinition, nodeTypeFunctionDefinition:
+		diagnosticsNodeTypeExceptionDefinition, diagnosticsNodeTypeUnionDefinition, diagnosticsNodeTypeScopeDefinition, nodeTypeFunctionDefinition:
 		// If the identifier is the first child after the keyword, it's likely a declaration
 		childCount := parent.ChildCount()
 		for i := uint(0); i < childCount; i++ {
@@ -2,23 +2,38 @@ package features
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	protocol "github.com/tliron/glsp/protocol_3_16"
 	tree_sitter "github.com/tree-sitter/go-tree-sitter"
 
 	"frugal-ls/internal/document"
+	"frugal-ls/internal/workspace"
 	"frugal-ls/pkg/ast"
 )
 
 // HoverProvider handles hover information for Frugal symbols
-type HoverProvider struct{}
+type HoverProvider struct {
+	typeResolver     *workspace.TypeResolver
+	annotationSchema *AnnotationSchema
+}
 
 // NewHoverProvider creates a new hover provider
 func NewHoverProvider() *HoverProvider {
 	return &HoverProvider{}
 }
 
+// SetTypeResolver enables evaluating constants that refer to included files
+func (h *HoverProvider) SetTypeResolver(resolver *workspace.TypeResolver) {
+	h.typeResolver = resolver
+}
+
+// SetAnnotationSchema sets the annotation keys described when hovering annotations
+func (h *HoverProvider) SetAnnotationSchema(schema *AnnotationSchema) {
+	h.annotationSchema = schema
+}
+
 // ProvideHover provides hover information for a given position
 func (h *HoverProvider) ProvideHover(doc *document.Document, position protocol.Position) (*protocol.Hover, error) {
 	if doc.ParseResult == nil || doc.ParseResult.GetRootNode() == nil {
@@ -66,6 +81,21 @@ func (h *HoverProvider) ProvideHover(doc *document.Document, position protocol.P
 	}, nil
 }
 
+// ProvideDeclarationHover provides hover information for a symbol declared in another document
+func (h *HoverProvider) ProvideDeclarationHover(declDoc *document.Document, name string) *protocol.Hover {
+	symbol := h.findSymbolByName(name, declDoc)
+	if symbol == nil {
+		return nil
+	}
+
+	return &protocol.Hover{
+		Contents: protocol.MarkupContent{
+			Kind:  protocol.MarkupKindMarkdown,
+			Value: h.formatSymbolInfo(symbol, declDoc, filepath.Base(declDoc.Path)),
+		},
+	}
+}
+
 // getHoverInfo extracts hover information for a given node
 //
 //nolint:gocognit // Hover info extraction requires handling many different node types
@@ -80,15 +110,24 @@ func (h *HoverProvider) getHoverInfo(node *tree_sitter.Node, doc *document.Docum
 	switch nodeType {
 	case nodeTypeIdentifier:
 		// Check if this identifier is in a special context (method, field, etc.)
-		if methodInfo := h.getMethodInfo(node, doc); methodInfo != "" {
+		if annotationInfo := h.getAnnotationInfo(node, nodeText); annotationInfo != "" {
+			content.WriteString(annotationInfo)
+			found = true
+		} else if methodInfo := h.getMethodInfo(node, doc); methodInfo != "" {
 			content.WriteString(methodInfo)
 			found = true
 		} else if fieldInfo := h.getFieldInfo(node, doc); fieldInfo != "" {
 			content.WriteString(fieldInfo)
 			found = true
+		} else if eventInfo := h.getEventInfo(node, doc); eventInfo != "" {
+			content.WriteString(eventInfo)
+			found = true
+		} else if enumValueInfo := h.getEnumValueInfo(node, doc); enumValueInfo != "" {
+			content.WriteString(enumValueInfo)
+			found = true
 		} else if symbolInfo := h.findSymbolByName(nodeText, doc); symbolInfo != nil {
 			// Find the symbol this identifier refers to
-			content.WriteString(h.formatSymbolInfo(symbolInfo, doc))
+			content.WriteString(h.formatSymbolInfo(symbolInfo, doc, ""))
 			found = true
 		}
 
@@ -96,7 +135,9 @@ func (h *HoverProvider) getHoverInfo(node *tree_sitter.Node, doc *document.Docum
 		if serviceName := h.extractServiceName(node, doc.Content); serviceName != "" {
 			content.WriteString(fmt.Sprintf("**Service**: `%s`\n\n", serviceName))
 			content.WriteString("Defines a service with RPC methods.\n\n")
-			content.WriteString(h.getServiceMethods(node, doc.Content))
+			service := doc.GetModel().FindService(serviceName)
+			content.WriteString(h.getServiceMethods(service))
+			content.WriteString(h.getInheritedMethods(doc, service))
 			found = true
 		}
 
@@ -104,7 +145,7 @@ func (h *HoverProvider) getHoverInfo(node *tree_sitter.Node, doc *document.Docum
 		if scopeName := h.extractScopeName(node, doc.Content); scopeName != "" {
 			content.WriteString(fmt.Sprintf("**Scope**: `%s`\n\n", scopeName))
 			content.WriteString("Defines a pub/sub scope for event messaging.\n\n")
-			content.WriteString(h.getScopeEvents(node, doc.Content))
+			content.WriteString(h.getScopeEvents(doc.GetModel().FindScope(scopeName)))
 			found = true
 		}
 
@@ -112,7 +153,15 @@ func (h *HoverProvider) getHoverInfo(node *tree_sitter.Node, doc *document.Docum
 		if structName := h.extractStructName(node, doc.Content); structName != "" {
 			content.WriteString(fmt.Sprintf("**Struct**: `%s`\n\n", structName))
 			content.WriteString("Data structure definition.\n\n")
-			content.WriteString(h.getStructFields(node, doc.Content))
+			content.WriteString(h.getStructFields(doc.GetModel().FindStruct(structName)))
+			found = true
+		}
+
+	case diagnosticsNodeTypeUnionDefinition:
+		if unionName := h.extractStructName(node, doc.Content); unionName != "" {
+			content.WriteString(fmt.Sprintf("**Union**: `%s`\n\n", unionName))
+			content.WriteString("Union type; exactly one field is set.\n\n")
+			content.WriteString(h.getStructFields(doc.GetModel().FindStruct(unionName)))
 			found = true
 		}
 
@@ -120,13 +169,18 @@ func (h *HoverProvider) getHoverInfo(node *tree_sitter.Node, doc *document.Docum
 		if enumName := h.extractEnumName(node, doc.Content); enumName != "" {
 			content.WriteString(fmt.Sprintf("**Enum**: `%s`\n\n", enumName))
 			content.WriteString("Enumeration type definition.\n\n")
-			content.WriteString(h.getEnumValues(node, doc.Content))
+			content.WriteString(h.getEnumValues(doc.GetModel().FindEnum(enumName)))
 			found = true
 		}
 
 	case diagnosticsNodeTypeConstDefinition:
 		if constInfo := h.extractConstInfo(node, doc.Content); constInfo != "" {
 			content.WriteString(fmt.Sprintf("**Constant**: %s\n\n", constInfo))
+			for _, constDef := range doc.GetModel().Consts {
+				if constDef.Range == ast.NodeRange(node) {
+					content.WriteString(h.getConstValue(doc, constDef.Name))
+				}
+			}
 			found = true
 		}
 
@@ -174,8 +228,9 @@ func (h *HoverProvider) findSymbolByName(name string, doc *document.Document) *a
 	return nil
 }
 
-// formatSymbolInfo formats hover information for a symbol
-func (h *HoverProvider) formatSymbolInfo(symbol *ast.Symbol, doc *document.Document) string {
+// formatSymbolInfo formats hover information for a symbol. A non-empty file names the
+// document the symbol is declared in when it differs from the hovered one.
+func (h *HoverProvider) formatSymbolInfo(symbol *ast.Symbol, doc *document.Document, file string) string {
 	var content strings.Builder
 
 	// Symbol type and name
@@ -201,10 +256,38 @@ func (h *HoverProvider) formatSymbolInfo(symbol *ast.Symbol, doc *document.Docum
 	case ast.NodeTypeException:
 		content.WriteString(fmt.Sprintf("**Exception**: `%s`\n\n", symbol.Name))
 		content.WriteString("Exception type definition.")
+	case ast.NodeTypeUnion:
+		content.WriteString(fmt.Sprintf("**Union**: `%s`\n\n", symbol.Name))
+		content.WriteString("Union type; exactly one field is set.")
+	}
+
+	// Add members from the document model
+	model := doc.GetModel()
+	if def := model.FindDefinition(symbol.Name); def != nil {
+		content.WriteString(formatDocComment(definitionDoc(model, def)))
+		content.WriteString(formatDeprecation(definitionDeprecation(model, def)))
+	}
+	switch symbol.Type {
+	case ast.NodeTypeService:
+		service := model.FindService(symbol.Name)
+		content.WriteString("\n\n" + h.getServiceMethods(service))
+		content.WriteString(h.getInheritedMethods(doc, service))
+	case ast.NodeTypeScope:
+		content.WriteString("\n\n" + h.getScopeEvents(model.FindScope(symbol.Name)))
+	case ast.NodeTypeStruct, ast.NodeTypeException, ast.NodeTypeUnion:
+		content.WriteString("\n\n" + h.getStructFields(model.FindStruct(symbol.Name)))
+	case ast.NodeTypeEnum:
+		content.WriteString("\n\n" + h.getEnumValues(model.FindEnum(symbol.Name)))
+	case ast.NodeTypeConst:
+		content.WriteString("\n\n" + h.getConstValue(doc, symbol.Name))
 	}
 
 	// Add location information
-	content.WriteString(fmt.Sprintf("\n\n*Defined at line %d, column %d*", symbol.Line+1, symbol.Column+1))
+	content.WriteString(fmt.Sprintf("\n\n*Defined at line %d, column %d", symbol.Line+1, symbol.Column+1))
+	if file != "" {
+		content.WriteString(fmt.Sprintf(" in `%s`", file))
+	}
+	content.WriteString("*")
 
 	return content.String()
 }
@@ -286,120 +369,242 @@ func (h *HoverProvider) extractTypedefInfo(node *tree_sitter.Node, source []byte
 	return fmt.Sprintf("`%s` → `%s`", aliasName, baseType)
 }
 
+// getConstValue describes a constant's evaluated value, with references to other
+// constants and enum values resolved
+func (h *HoverProvider) getConstValue(doc *document.Document, name string) string {
+	constDef := doc.GetModel().FindConst(name)
+	if constDef == nil || constDef.Value == nil {
+		return ""
+	}
+
+	value := newValueChecker(doc, h.typeResolver).evaluate(constDef.Value, doc.URI)
+	return fmt.Sprintf("**Value**: `%s`", value)
+}
+
 // getServiceMethods gets method information for a service
-func (h *HoverProvider) getServiceMethods(node *tree_sitter.Node, source []byte) string {
+func (h *HoverProvider) getServiceMethods(service *ast.Service) string {
+	if service == nil || len(service.Methods) == 0 {
+		return "No methods defined."
+	}
+
 	var content strings.Builder
 	content.WriteString("**Methods:**\n")
+	for _, method := range service.Methods {
+		content.WriteString(fmt.Sprintf("- `%s`\n", formatMethodSignature(method)))
+	}
+	return content.String()
+}
 
-	// Find service body and extract methods
-	serviceBody := ast.FindNodeByType(node, "service_body")
-	if serviceBody == nil {
-		return "No methods found."
+// getInheritedMethods lists the methods a service inherits, grouped by the service
+// declaring them
+func (h *HoverProvider) getInheritedMethods(doc *document.Document, service *ast.Service) string {
+	if service == nil || service.Extends == "" {
+		return ""
 	}
 
-	methodCount := 0
-	childCount := serviceBody.ChildCount()
-	for i := uint(0); i < childCount; i++ {
-		child := serviceBody.Child(i)
-		if child.Kind() == "method" || strings.Contains(child.Kind(), "method") {
-			methodText := ast.GetText(child, source)
-			content.WriteString(fmt.Sprintf("- `%s`\n", strings.TrimSpace(methodText)))
-			methodCount++
+	ancestors, _ := serviceAncestors(doc, h.typeResolver, service)
+	methods := inheritedMethods(ancestors, service)
+
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("\n**Extends**: `%s`\n", service.Extends))
+	for i, inherited := range methods {
+		if i == 0 || methods[i-1].from.Service != inherited.from.Service {
+			content.WriteString(fmt.Sprintf("\n**Inherited from %s:**\n", inherited.from.Service.Name))
 		}
+		content.WriteString(fmt.Sprintf("- `%s`\n", formatMethodSignature(inherited.method)))
 	}
+	return content.String()
+}
 
-	if methodCount == 0 {
-		return "No methods defined."
+// getScopeEvents gets event information for a scope, with the topic each event is
+// published on
+func (h *HoverProvider) getScopeEvents(scope *ast.Scope) string {
+	if scope == nil {
+		return "No events defined."
+	}
+
+	var content strings.Builder
+	if scope.Prefix != "" {
+		content.WriteString(fmt.Sprintf("**Prefix**: `%s`", scope.Prefix))
+		if variables := scope.PrefixVariables(); len(variables) > 0 {
+			content.WriteString(" (variables: `" + strings.Join(variables, "`, `") + "`)")
+		}
+		content.WriteString("\n\n")
+	}
+	if len(scope.Events) == 0 {
+		content.WriteString("No events defined.")
+		return content.String()
 	}
 
+	content.WriteString("**Events:**\n")
+	for _, event := range scope.Events {
+		content.WriteString(fmt.Sprintf("- `%s` on `%s`\n", formatEventSignature(event), scope.Topic(event.Name)))
+	}
 	return content.String()
 }
 
-// getScopeEvents gets event information for a scope
-func (h *HoverProvider) getScopeEvents(node *tree_sitter.Node, source []byte) string {
-	var content strings.Builder
-	content.WriteString("**Events:**\n")
+// getEventInfo describes the scope event named by an identifier, with its topic
+func (h *HoverProvider) getEventInfo(node *tree_sitter.Node, doc *document.Document) string {
+	if parent := node.Parent(); parent == nil || parent.Kind() != "scope_operation" {
+		return ""
+	}
 
-	// Find scope body and extract events
-	scopeBody := ast.FindNodeByType(node, "scope_body")
-	if scopeBody == nil {
-		return "No events found."
+	for _, scope := range doc.GetModel().Scopes {
+		for _, event := range scope.Events {
+			if event.NameRange == ast.NodeRange(node) {
+				return fmt.Sprintf("**Event**: `%s`\n\nPublished by scope `%s` on topic `%s`",
+					formatEventSignature(event), scope.Name, scope.Topic(event.Name)) + formatDocComment(event.Doc)
+			}
+		}
 	}
+	return ""
+}
 
-	eventCount := 0
-	childCount := scopeBody.ChildCount()
-	for i := uint(0); i < childCount; i++ {
-		child := scopeBody.Child(i)
-		eventText := ast.GetText(child, source)
-		if strings.TrimSpace(eventText) != "" && !strings.HasPrefix(strings.TrimSpace(eventText), "//") {
-			content.WriteString(fmt.Sprintf("- `%s`\n", strings.TrimSpace(eventText)))
-			eventCount++
+// getEnumValueInfo returns information about an enum value if the identifier names one
+func (h *HoverProvider) getEnumValueInfo(node *tree_sitter.Node, doc *document.Document) string {
+	for _, enum := range doc.GetModel().Enums {
+		for _, value := range enum.Values {
+			if value.NameRange == ast.NodeRange(node) {
+				return fmt.Sprintf("**Enum Value**: `%s.%s = %d`", enum.Name, value.Name, value.Value) +
+					formatDocComment(value.Doc) + formatDeprecation(value.Deprecated)
+			}
 		}
 	}
+	return ""
+}
 
-	if eventCount == 0 {
-		return "No events defined."
+// formatMemberDocs renders the doc comment and deprecation of the field or method
+// declared by node, or "" if it has neither
+func (h *HoverProvider) formatMemberDocs(doc *document.Document, node *tree_sitter.Node) string {
+	nodeRange := ast.NodeRange(node)
+	findField := func(fields []*ast.Field) *ast.Field {
+		for _, field := range fields {
+			if field.Range == nodeRange {
+				return field
+			}
+		}
+		return nil
 	}
 
-	return content.String()
+	model := doc.GetModel()
+	for _, structDef := range model.Structs {
+		if field := findField(structDef.Fields); field != nil {
+			return formatDocComment(field.Doc) + formatDeprecation(field.Deprecated)
+		}
+	}
+	for _, service := range model.Services {
+		for _, method := range service.Methods {
+			if method.Range == nodeRange {
+				return formatDocComment(method.Doc) + formatDeprecation(method.Deprecated)
+			}
+			if field := findField(method.Params); field != nil {
+				return formatDocComment(field.Doc) + formatDeprecation(field.Deprecated)
+			}
+			if field := findField(method.Throws); field != nil {
+				return formatDocComment(field.Doc) + formatDeprecation(field.Deprecated)
+			}
+		}
+	}
+	return ""
 }
 
-// getStructFields gets field information for a struct
-func (h *HoverProvider) getStructFields(node *tree_sitter.Node, source []byte) string {
-	var content strings.Builder
-	content.WriteString("**Fields:**\n")
-
-	// Find struct body and extract fields
-	structBody := ast.FindNodeByType(node, "struct_body")
-	if structBody == nil {
-		return "No fields found."
+// getAnnotationInfo describes the annotation key an identifier names
+func (h *HoverProvider) getAnnotationInfo(node *tree_sitter.Node, key string) string {
+	if parent := node.Parent(); parent == nil || parent.Kind() != "annotation_list" {
+		return ""
+	}
+	annotation, ok := h.annotationSchema.Lookup(key)
+	if !ok {
+		return fmt.Sprintf("**Annotation**: `%s`\n\nUnknown annotation.", key)
 	}
+	return formatAnnotationInfo(key, annotation)
+}
 
-	fieldCount := 0
-	childCount := structBody.ChildCount()
-	for i := uint(0); i < childCount; i++ {
-		child := structBody.Child(i)
-		if child.Kind() == nodeTypeField || strings.Contains(child.Kind(), "field") {
-			fieldText := ast.GetText(child, source)
-			content.WriteString(fmt.Sprintf("- `%s`\n", strings.TrimSpace(fieldText)))
-			fieldCount++
-		}
+// formatEventSignature renders an event the way it is declared
+func formatEventSignature(event *ast.Event) string {
+	eventType := ""
+	if event.Type != nil {
+		eventType = event.Type.Text
 	}
+	return fmt.Sprintf("%s: %s", event.Name, eventType)
+}
 
-	if fieldCount == 0 {
+// getStructFields gets field information for a struct
+func (h *HoverProvider) getStructFields(structDef *ast.Struct) string {
+	if structDef == nil || len(structDef.Fields) == 0 {
 		return "No fields defined."
 	}
 
+	var content strings.Builder
+	content.WriteString("**Fields:**\n")
+	for _, field := range structDef.Fields {
+		content.WriteString(fmt.Sprintf("- `%s`\n", formatFieldSignature(field)))
+	}
 	return content.String()
 }
 
 // getEnumValues gets value information for an enum
-func (h *HoverProvider) getEnumValues(node *tree_sitter.Node, source []byte) string {
+func (h *HoverProvider) getEnumValues(enum *ast.Enum) string {
+	if enum == nil || len(enum.Values) == 0 {
+		return "No values defined."
+	}
+
 	var content strings.Builder
 	content.WriteString("**Values:**\n")
-
-	// Find enum body and extract values
-	enumBody := ast.FindNodeByType(node, "enum_body")
-	if enumBody == nil {
-		return "No values found."
+	for _, value := range enum.Values {
+		content.WriteString(fmt.Sprintf("- `%s = %d`\n", value.Name, value.Value))
 	}
+	return content.String()
+}
 
-	valueCount := 0
-	childCount := enumBody.ChildCount()
-	for i := uint(0); i < childCount; i++ {
-		child := enumBody.Child(i)
-		valueText := ast.GetText(child, source)
-		if strings.TrimSpace(valueText) != "" && !strings.HasPrefix(strings.TrimSpace(valueText), "//") {
-			content.WriteString(fmt.Sprintf("- `%s`\n", strings.TrimSpace(valueText)))
-			valueCount++
-		}
+// formatFieldSignature renders a field the way it is declared
+func formatFieldSignature(field *ast.Field) string {
+	var parts []string
+	if field.HasID {
+		parts = append(parts, fmt.Sprintf("%d:", field.ID))
 	}
-
-	if valueCount == 0 {
-		return "No values defined."
+	if field.Requiredness != "" {
+		parts = append(parts, field.Requiredness)
+	}
+	if field.Type != nil {
+		parts = append(parts, field.Type.Text)
 	}
+	parts = append(parts, field.Name)
+	if field.Default != nil {
+		parts = append(parts, "=", field.Default.Text)
+	}
+	return strings.Join(parts, " ")
+}
 
-	return content.String()
+// formatMethodSignature renders a method signature from the model
+func formatMethodSignature(method *ast.Method) string {
+	var signature strings.Builder
+	if method.Oneway {
+		signature.WriteString("oneway ")
+	}
+	if method.ReturnType != nil {
+		signature.WriteString(method.ReturnType.Text)
+		signature.WriteString(" ")
+	}
+	signature.WriteString(method.Name)
+	signature.WriteString("(")
+	signature.WriteString(joinFieldSignatures(method.Params))
+	signature.WriteString(")")
+	if len(method.Throws) > 0 {
+		signature.WriteString(" throws (")
+		signature.WriteString(joinFieldSignatures(method.Throws))
+		signature.WriteString(")")
+	}
+	return signature.String()
+}
+
+// joinFieldSignatures renders a comma separated field list
+func joinFieldSignatures(fields []*ast.Field) string {
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		parts = append(parts, formatFieldSignature(field))
+	}
+	return strings.Join(parts, ", ")
 }
 
 // getTypeInfo returns information about Frugal types
@@ -436,6 +641,7 @@ func (h *HoverProvider) getKeywordInfo(keyword string) string {
 		"struct":    "**Keyword**: `struct`\n\nDefines a data structure with named fields.",
 		"enum":      "**Keyword**: `enum`\n\nDefines an enumeration type with named values.",
 		"exception": "**Keyword**: `exception`\n\nDefines an exception type that can be thrown.",
+		"union":     "**Keyword**: `union`\n\nDefines a type holding exactly one of its fields.",
 		"service":   "**Keyword**: `service`\n\nDefines an RPC service with methods.",
 		"scope":     "**Keyword**: `scope`\n\nDefines a pub/sub scope for event messaging (Frugal extension).",
 		"oneway":    "**Keyword**: `oneway`\n\nMethod modifier indicating no response is expected.",
@@ -461,7 +667,7 @@ func (h *HoverProvider) getMethodInfo(node *tree_sitter.Node, doc *document.Docu
 
 		// Check if we're in a function/method declaration
 		if nodeType == "function_declaration" || nodeType == "method_declaration" || nodeType == nodeTypeFunctionDefinition {
-			return h.formatMethodDeclaration(current, doc.Content)
+			return h.formatMethodDeclaration(current, doc.Content) + h.formatMemberDocs(doc, current)
 		}
 
 		// If we find a service body, we might be in a method
@@ -487,7 +693,7 @@ func (h *HoverProvider) getFieldInfo(node *tree_sitter.Node, doc *document.Docum
 
 		// Check if we're in a field declaration
 		if nodeType == "field_declaration" || nodeType == nodeTypeField {
-			return h.formatFieldDeclaration(current, doc.Content)
+			return h.formatFieldDeclaration(current, doc.Content) + h.formatMemberDocs(doc, current)
 		}
 
 		// If we find a struct body, we might be in a field
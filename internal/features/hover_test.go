@@ -420,3 +420,217 @@ func TestHoverContentFormat(t *testing.T) {
 		t.Error("Expected hover contents to be MarkupContent")
 	}
 }
+
+func TestProvideHoverForUnion(t *testing.T) {
+	content := `union Value {
+    1: string text,
+    2: i64 number
+}`
+
+	doc, err := createTestDocumentForHover("file:///test.frugal", content)
+	if err != nil {
+		t.Fatalf("Failed to create document: %v", err)
+	}
+	defer doc.ParseResult.Close()
+
+	hover, err := NewHoverProvider().ProvideHover(doc, protocol.Position{Line: 0, Character: 8})
+	if err != nil || hover == nil {
+		t.Fatalf("Expected hover for union, got %v (%v)", hover, err)
+	}
+
+	text := hover.Contents.(protocol.MarkupContent).Value
+	for _, want := range []string{"**Union**: `Value`", "- `1: string text`", "- `2: i64 number`"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected hover to contain %q, got:\n%s", want, text)
+		}
+	}
+}
+
+func TestProvideDeclarationHover(t *testing.T) {
+	content := "struct User {\n    1: i64 id\n}"
+
+	doc, err := createTestDocumentForHover("file:///common.frugal", content)
+	if err != nil {
+		t.Fatalf("Failed to create document: %v", err)
+	}
+	defer doc.ParseResult.Close()
+
+	hover := NewHoverProvider().ProvideDeclarationHover(doc, "User")
+	if hover == nil {
+		t.Fatal("Expected hover for declaration")
+	}
+
+	text := hover.Contents.(protocol.MarkupContent).Value
+	if want := "*Defined at line 1, column 1 in `common.frugal`*"; !strings.HasSuffix(text, want) {
+		t.Errorf("Expected hover to end with %q, got:\n%s", want, text)
+	}
+}
+
+func TestHoverScopeTopics(t *testing.T) {
+	content := `scope UserEvents prefix "user.{region}" {
+    Created: string
+}`
+
+	doc, err := createTestDocumentForHover("file:///test.frugal", content)
+	if err != nil {
+		t.Fatalf("Failed to create document: %v", err)
+	}
+	defer doc.ParseResult.Close()
+
+	provider := NewHoverProvider()
+	hover, err := provider.ProvideHover(doc, protocol.Position{Line: 0, Character: 8})
+	if err != nil || hover == nil {
+		t.Fatalf("Expected hover for scope, got %v (%v)", hover, err)
+	}
+	text := hover.Contents.(protocol.MarkupContent).Value
+	for _, want := range []string{
+		"**Prefix**: `user.{region}` (variables: `region`)",
+		"- `Created: string` on `user.{region}.UserEvents.Created`",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected scope hover to contain %q, got:\n%s", want, text)
+		}
+	}
+
+	hover, err = provider.ProvideHover(doc, protocol.Position{Line: 1, Character: 6})
+	if err != nil || hover == nil {
+		t.Fatalf("Expected hover for event, got %v (%v)", hover, err)
+	}
+	text = hover.Contents.(protocol.MarkupContent).Value
+	if !strings.Contains(text, "on topic `user.{region}.UserEvents.Created`") {
+		t.Errorf("Expected event hover to show its topic, got:\n%s", text)
+	}
+}
+
+func TestHoverAnnotation(t *testing.T) {
+	content := `struct User {
+    1: string id (go.tag = "json:\"id\"", owner = "accounts")
+}`
+
+	doc, err := createTestDocumentForHover("file:///test.frugal", content)
+	if err != nil {
+		t.Fatalf("Failed to create document: %v", err)
+	}
+	defer doc.ParseResult.Close()
+
+	provider := NewHoverProvider()
+	hover, err := provider.ProvideHover(doc, protocol.Position{Line: 1, Character: 20})
+	if err != nil || hover == nil {
+		t.Fatalf("Expected hover for annotation, got %v (%v)", hover, err)
+	}
+	text := hover.Contents.(protocol.MarkupContent).Value
+	for _, want := range []string{"**Annotation**: `go.tag`", "Struct tag added to the generated Go field", "**Value**: string"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected annotation hover to contain %q, got:\n%s", want, text)
+		}
+	}
+
+	hover, err = provider.ProvideHover(doc, protocol.Position{Line: 1, Character: 44})
+	if err != nil || hover == nil {
+		t.Fatalf("Expected hover for unknown annotation, got %v (%v)", hover, err)
+	}
+	if text := hover.Contents.(protocol.MarkupContent).Value; !strings.Contains(text, "Unknown annotation") {
+		t.Errorf("Expected unknown annotation hover, got:\n%s", text)
+	}
+}
+
+func TestHoverDeprecation(t *testing.T) {
+	content := `// @deprecated Use Account instead.
+struct User {
+    1: string email (deprecated = "use contacts")
+}
+
+enum Status {
+    LEGACY (deprecated)
+}
+
+service Users {
+    /** @deprecated */
+    void ping()
+}`
+
+	doc, err := createTestDocumentForHover("file:///test.frugal", content)
+	if err != nil {
+		t.Fatalf("Failed to create document: %v", err)
+	}
+	defer doc.ParseResult.Close()
+
+	tests := map[string]struct {
+		position protocol.Position
+		want     string
+	}{
+		"struct":     {protocol.Position{Line: 1, Character: 8}, "**Deprecated**: Use Account instead."},
+		"field":      {protocol.Position{Line: 2, Character: 16}, "**Deprecated**: use contacts"},
+		"enum value": {protocol.Position{Line: 6, Character: 6}, "**Enum Value**: `Status.LEGACY = 0`\n\n**Deprecated**"},
+		"method":     {protocol.Position{Line: 11, Character: 10}, "**Deprecated**"},
+	}
+
+	provider := NewHoverProvider()
+	for name, tt := range tests {
+		hover, err := provider.ProvideHover(doc, tt.position)
+		if err != nil || hover == nil {
+			t.Errorf("%s: expected hover, got %v (%v)", name, hover, err)
+			continue
+		}
+		if text := hover.Contents.(protocol.MarkupContent).Value; !strings.Contains(text, tt.want) {
+			t.Errorf("%s: expected hover to contain %q, got:\n%s", name, tt.want, text)
+		}
+	}
+}
+
+func TestHoverDocComments(t *testing.T) {
+	content := `// A user account.
+struct User {
+    /** The user's email. */
+    1: string email
+}
+
+enum Status {
+    // Can sign in
+    ACTIVE
+}
+
+service Users {
+    /**
+     * Looks up a user.
+     * @param id the user's ID
+     * @throws NotFound if there is no such user
+     */
+    User get(1: string id) throws (1: NotFound missing)
+}
+
+scope Changes {
+    // The user after the change
+    Updated: User
+}`
+
+	doc, err := createTestDocumentForHover("file:///test.frugal", content)
+	if err != nil {
+		t.Fatalf("Failed to create document: %v", err)
+	}
+	defer doc.ParseResult.Close()
+
+	tests := map[string]struct {
+		position protocol.Position
+		want     string
+	}{
+		"struct":     {protocol.Position{Line: 1, Character: 8}, "Data structure definition.\n\nA user account."},
+		"field":      {protocol.Position{Line: 3, Character: 16}, "\n\nThe user's email."},
+		"enum value": {protocol.Position{Line: 8, Character: 6}, "**Enum Value**: `Status.ACTIVE = 0`\n\nCan sign in"},
+		"method": {protocol.Position{Line: 17, Character: 10},
+			"Looks up a user.\n\n**Parameters:**\n- `id`: the user's ID\n\n**Throws:**\n- `NotFound`: if there is no such user"},
+		"event": {protocol.Position{Line: 22, Character: 6}, "\n\nThe user after the change"},
+	}
+
+	provider := NewHoverProvider()
+	for name, tt := range tests {
+		hover, err := provider.ProvideHover(doc, tt.position)
+		if err != nil || hover == nil {
+			t.Errorf("%s: expected hover, got %v (%v)", name, hover, err)
+			continue
+		}
+		if text := hover.Contents.(protocol.MarkupContent).Value; !strings.Contains(text, tt.want) {
+			t.Errorf("%s: expected hover to contain %q, got:\n%s", name, tt.want, text)
+		}
+	}
+}
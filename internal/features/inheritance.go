@@ -0,0 +1,70 @@
+package features
+
+import (
+	"fmt"
+	"strings"
+
+	"frugal-ls/internal/document"
+	"frugal-ls/internal/workspace"
+	"frugal-ls/pkg/ast"
+)
+
+// serviceAncestors returns the services a service in doc inherits from, nearest first,
+// and the reason the chain could not be followed further, if any. Without a type
+// resolver only services declared in doc are followed.
+func serviceAncestors(doc *document.Document, resolver *workspace.TypeResolver, service *ast.Service) ([]workspace.ServiceAncestor, error) {
+	if resolver != nil {
+		return resolver.Ancestors(doc.URI, service)
+	}
+
+	model := doc.GetModel()
+	var ancestors []workspace.ServiceAncestor
+	visited := map[string]bool{service.Name: true}
+	for service.Extends != "" {
+		if strings.Contains(service.Extends, ".") {
+			return ancestors, fmt.Errorf("%w: %s", workspace.ErrIncludeNotLoaded, service.Extends)
+		}
+
+		def := model.FindDefinition(service.Extends)
+		switch {
+		case def == nil:
+			return ancestors, fmt.Errorf("%w: %s", workspace.ErrUndefinedType, service.Extends)
+		case def.Type != ast.NodeTypeService:
+			return ancestors, fmt.Errorf("%w: %s is a %s", workspace.ErrNotService, def.Name, def.Type)
+		case visited[def.Name]:
+			return ancestors, fmt.Errorf("%w: %s", workspace.ErrExtendsCycle, def.Name)
+		}
+		visited[def.Name] = true
+
+		service = model.FindService(def.Name)
+		ancestors = append(ancestors, workspace.ServiceAncestor{URI: doc.URI, Service: service})
+	}
+
+	return ancestors, nil
+}
+
+// inheritedMethod is a method a service inherits, with the service that declares it
+type inheritedMethod struct {
+	method *ast.Method
+	from   workspace.ServiceAncestor
+}
+
+// inheritedMethods returns the methods a service inherits, nearest ancestor first. A
+// method redefined closer to the service hides the ancestor's.
+func inheritedMethods(ancestors []workspace.ServiceAncestor, service *ast.Service) []inheritedMethod {
+	seen := make(map[string]bool)
+	for _, method := range service.Methods {
+		seen[method.Name] = true
+	}
+
+	var methods []inheritedMethod
+	for _, ancestor := range ancestors {
+		for _, method := range ancestor.Service.Methods {
+			if !seen[method.Name] {
+				seen[method.Name] = true
+				methods = append(methods, inheritedMethod{method: method, from: ancestor})
+			}
+		}
+	}
+	return methods
+}
@@ -0,0 +1,153 @@
+package features
+
+import (
+	"strings"
+	"testing"
+
+	protocol "github.com/tliron/glsp/protocol_3_16"
+
+	"frugal-ls/internal/document"
+	"frugal-ls/internal/workspace"
+)
+
+const inheritanceBase = `service BaseService {
+    void ping(),
+    string version()
+}`
+
+const inheritanceMain = `include "base.frugal"
+
+service UserService extends base.BaseService {
+    string getName(1: i64 id),
+    void ping()
+}
+
+service AdminService extends UserService {
+    void ban(1: i64 id)
+}`
+
+// openInheritanceWorkspace opens base.frugal and main.frugal in a temporary workspace
+// and returns the main document and a resolver over both
+func openInheritanceWorkspace(t *testing.T) (*document.Document, *workspace.TypeResolver) {
+	t.Helper()
+	return openTestWorkspace(t, inheritanceMain, map[string]string{"base.frugal": inheritanceBase})
+}
+
+func TestServiceExtendsDiagnostics(t *testing.T) {
+	content := `struct Plain {
+    1: string name
+}
+
+service Missing extends Unknown {}
+
+service NotAService extends Plain {}
+
+service A extends B {}
+
+service B extends A {}
+
+service Base {
+    void ping()
+}
+
+service Child extends Base {
+    void ping(),
+    void pong()
+}`
+
+	doc := createTestDocumentForDiagnostics(t, "file:///test.frugal", content)
+	defer doc.ParseResult.Close()
+
+	var messages []string
+	for _, diagnostic := range NewDiagnosticsProvider().ProvideDiagnostics(doc) {
+		if diagnostic.Code.Value == "FL012" {
+			messages = append(messages, diagnostic.Message)
+		}
+	}
+	expected := []string{
+		"Unknown service 'Unknown'",
+		"Service NotAService cannot extend 'Plain', which is not a service",
+		"Circular extends chain: A -> B -> A",
+		"Circular extends chain: B -> A -> B",
+		"Method 'ping' redefines a method inherited from Base",
+	}
+	if strings.Join(messages, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected %v, got %v", expected, messages)
+	}
+}
+
+func TestServiceExtendsAcrossIncludes(t *testing.T) {
+	doc, resolver := openInheritanceWorkspace(t)
+
+	provider := NewDiagnosticsProvider()
+	provider.SetTypeResolver(resolver)
+	var redefined []protocol.Diagnostic
+	for _, diagnostic := range provider.ProvideDiagnostics(doc) {
+		if diagnostic.Code.Value == "FL012" {
+			redefined = append(redefined, diagnostic)
+		}
+	}
+	if len(redefined) != 1 || redefined[0].Message != "Method 'ping' redefines a method inherited from BaseService" {
+		t.Fatalf("Expected one redefinition of ping, got %v", redefined)
+	}
+	related := redefined[0].RelatedInformation
+	if len(related) != 1 || !strings.HasSuffix(related[0].Location.URI, "base.frugal") || related[0].Location.Range.Start.Line != 1 {
+		t.Errorf("Expected related information at ping in base.frugal, got %v", related)
+	}
+}
+
+func TestHoverShowsInheritedMethods(t *testing.T) {
+	doc, resolver := openInheritanceWorkspace(t)
+
+	provider := NewHoverProvider()
+	provider.SetTypeResolver(resolver)
+	hover, err := provider.ProvideHover(doc, protocol.Position{Line: 7, Character: 10})
+	if err != nil || hover == nil {
+		t.Fatalf("Expected hover for AdminService, got %v (%v)", hover, err)
+	}
+
+	text := hover.Contents.(protocol.MarkupContent).Value
+	for _, want := range []string{
+		"**Extends**: `UserService`",
+		"**Inherited from UserService:**\n- `string getName(1: i64 id)`\n- `void ping()`",
+		"**Inherited from BaseService:**\n- `string version()`",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected hover to contain %q, got:\n%s", want, text)
+		}
+	}
+}
+
+func TestDocumentSymbolsShowInheritedMethods(t *testing.T) {
+	doc, resolver := openInheritanceWorkspace(t)
+
+	provider := NewDocumentSymbolProvider()
+	provider.SetTypeResolver(resolver)
+	symbols, err := provider.ProvideDocumentSymbols(doc)
+	if err != nil {
+		t.Fatalf("ProvideDocumentSymbols failed: %v", err)
+	}
+
+	var admin *protocol.DocumentSymbol
+	for i := range symbols {
+		if symbols[i].Name == "AdminService" {
+			admin = &symbols[i]
+		}
+	}
+	if admin == nil {
+		t.Fatal("Expected AdminService symbol")
+	}
+
+	var outline []string
+	for _, child := range admin.Children {
+		entry := child.Name
+		for _, inherited := range child.Children {
+			entry += " " + inherited.Name
+		}
+		outline = append(outline, entry)
+	}
+	expected := []string{"ban", "UserService getName ping", "BaseService version"}
+	if strings.Join(outline, ", ") != strings.Join(expected, ", ") {
+		t.Errorf("Expected children %v, got %v", expected, outline)
+	}
+}
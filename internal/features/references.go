@@ -219,6 +219,8 @@ func (p *ReferencesProvider) getSymbolContext(node *tree_sitter.Node) string {
 		return "enum"
 	case diagnosticsNodeTypeExceptionDefinition:
 		return "exception"
+	case diagnosticsNodeTypeUnionDefinition:
+		return diagnosticsNodeTypeUnion
 	case diagnosticsNodeTypeScopeDefinition:
 		return "scope"
 	case nodeTypeFunctionDefinition:
@@ -9,6 +9,7 @@ import (
 	tree_sitter "github.com/tree-sitter/go-tree-sitter"
 
 	"frugal-ls/internal/document"
+	"frugal-ls/internal/workspace"
 	"frugal-ls/pkg/ast"
 )
 
@@ -74,7 +75,7 @@ func (r *RenameProvider) Rename(doc *document.Document, position protocol.Positi
 	}
 
 	// Check for naming conflicts
-	if err := r.checkConflicts(symbolInfo, newName, allDocuments); err != nil {
+	if err := r.checkConflicts(symbolInfo, newName, doc.URI, allDocuments); err != nil {
 		return nil, err
 	}
 
@@ -101,6 +102,30 @@ func (r *RenameProvider) Rename(doc *document.Document, position protocol.Positi
 	return workspaceEdit, nil
 }
 
+// RenameReferences renames a top-level declaration using references bound by the workspace type resolver
+func (r *RenameProvider) RenameReferences(decl *workspace.Declaration, references []workspace.Reference, newName string, allDocuments map[string]*document.Document) (*protocol.WorkspaceEdit, error) {
+	if err := r.validateNewName(newName); err != nil {
+		return nil, err
+	}
+
+	if err := r.checkConflicts(&SymbolInfo{Name: decl.Name, Kind: "type_reference"}, newName, decl.URI, allDocuments); err != nil {
+		return nil, err
+	}
+
+	// Only the name part of include-prefixed references is replaced
+	changes := make(map[string][]protocol.TextEdit)
+	for _, ref := range references {
+		changes[ref.URI] = append(changes[ref.URI], protocol.TextEdit{
+			Range:   modelRangeToProtocol(ref.NameRange),
+			NewText: newName,
+		})
+	}
+
+	return &protocol.WorkspaceEdit{
+		Changes: changes,
+	}, nil
+}
+
 // SymbolInfo represents information about a symbol found at a position
 type SymbolInfo struct {
 	Name    string
@@ -237,6 +262,13 @@ func (r *RenameProvider) extractIdentifierInfo(node *tree_sitter.Node, source []
 			Range:   symbolRange,
 			Context: "definition",
 		}
+	case "union_definition":
+		return &SymbolInfo{
+			Name:    name,
+			Kind:    "union",
+			Range:   symbolRange,
+			Context: "definition",
+		}
 	case "scope_definition":
 		return &SymbolInfo{
 			Name:    name,
@@ -348,7 +380,7 @@ func (r *RenameProvider) isRenameable(symbol *SymbolInfo) bool {
 	// Keywords cannot be renamed
 	keywords := map[string]bool{
 		"include": true, "namespace": true, "service": true, "scope": true,
-		"struct": true, "enum": true, "exception": true, "const": true,
+		"struct": true, "union": true, "enum": true, "exception": true, "const": true,
 		"typedef": true, "throws": true, "extends": true, "oneway": true,
 		"required": true, "optional": true, "prefix": true,
 	}
@@ -372,7 +404,7 @@ func (r *RenameProvider) validateNewName(newName string) error {
 	// Check if it's a reserved keyword
 	keywords := map[string]bool{
 		"include": true, "namespace": true, "service": true, "scope": true,
-		"struct": true, "enum": true, "exception": true, "const": true,
+		"struct": true, "union": true, "enum": true, "exception": true, "const": true,
 		"typedef": true, "throws": true, "extends": true, "oneway": true,
 		"required": true, "optional": true, "prefix": true,
 		"bool": true, "byte": true, "i8": true, "i16": true, "i32": true, "i64": true,
@@ -387,22 +419,26 @@ func (r *RenameProvider) validateNewName(newName string) error {
 	return nil
 }
 
-// checkConflicts checks for naming conflicts in the target scope
-func (r *RenameProvider) checkConflicts(symbol *SymbolInfo, newName string, allDocuments map[string]*document.Document) error {
-	// For now, do basic conflict detection
-	// In a more sophisticated implementation, we would check:
-	// 1. Same-scope naming conflicts (e.g., two structs with same name)
-	// 2. Cross-file dependencies and conflicts
-	// 3. Scope-specific rules (e.g., field names within a struct)
-
+// checkConflicts checks for naming conflicts in the target scope. Top-level names must
+// be unique in the declaring file only: other files refer to them with an include
+// prefix, so a definition of the same name in an included or including file is never
+// ambiguous.
+func (r *RenameProvider) checkConflicts(symbol *SymbolInfo, newName, declURI string, allDocuments map[string]*document.Document) error {
 	// Basic check: ensure we're not renaming to the same name
 	if symbol.Name == newName {
 		return fmt.Errorf("new name '%s' is the same as current name", newName)
 	}
 
-	// TODO: Implement more sophisticated conflict detection
-	// This would require analyzing the symbol's scope and checking for existing symbols
-	// with the new name in the same scope.
+	switch symbol.Kind {
+	case "struct", "union", "service", "enum", "exception", "scope", "constant", "typedef", "type_reference":
+		doc, exists := allDocuments[declURI]
+		if !exists || !doc.IsValidFrugalFile() {
+			break
+		}
+		if existing := doc.GetModel().FindDefinition(newName); existing != nil {
+			return fmt.Errorf("'%s' is already defined as a %s in %s", newName, existing.Type, declURI)
+		}
+	}
 
 	return nil
 }
@@ -3,6 +3,8 @@ package features
 import (
 	"testing"
 
+	"frugal-ls/internal/document"
+
 	protocol "github.com/tliron/glsp/protocol_3_16"
 )
 
@@ -112,18 +114,49 @@ func TestRenameProviderCheckConflicts(t *testing.T) {
 
 	// Test basic conflict detection (same name)
 	symbol := &SymbolInfo{Name: "User", Kind: "struct"}
-	err := provider.checkConflicts(symbol, "User", nil)
+	err := provider.checkConflicts(symbol, "User", "file:///test.frugal", nil)
 	if err == nil {
 		t.Error("Expected error when renaming to same name")
 	}
 
 	// Test valid rename (different name)
-	err = provider.checkConflicts(symbol, "Person", nil)
+	err = provider.checkConflicts(symbol, "Person", "file:///test.frugal", nil)
 	if err != nil {
 		t.Errorf("Expected no error for valid rename, got: %v", err)
 	}
 }
 
+func TestRenameProviderCheckConflictsAcrossIncludes(t *testing.T) {
+	mainURI := "file:///main.frugal"
+	commonURI := "file:///common.frugal"
+	allDocuments := map[string]*document.Document{
+		mainURI:   createTestDocumentForDiagnostics(t, mainURI, "include \"common.frugal\"\n\nstruct User {\n    1: i64 id\n}\n\nstruct Profile {\n    1: i64 id\n}"),
+		commonURI: createTestDocumentForDiagnostics(t, commonURI, "struct Account {\n    1: i64 id\n}"),
+	}
+	for _, doc := range allDocuments {
+		defer doc.ParseResult.Close()
+	}
+
+	provider := NewRenameProvider()
+
+	// Names in an included file are referenced as common.Account, so they don't clash
+	user := &SymbolInfo{Name: "User", Kind: "struct"}
+	if err := provider.checkConflicts(user, "Account", mainURI, allDocuments); err != nil {
+		t.Errorf("Expected no conflict with a definition in an included file, got: %v", err)
+	}
+
+	// Nor do names in a file that includes the declaring one
+	account := &SymbolInfo{Name: "Account", Kind: "struct"}
+	if err := provider.checkConflicts(account, "User", commonURI, allDocuments); err != nil {
+		t.Errorf("Expected no conflict with a definition in an including file, got: %v", err)
+	}
+
+	// A definition in the declaring file is
+	if err := provider.checkConflicts(user, "Profile", mainURI, allDocuments); err == nil {
+		t.Error("Expected a conflict with a definition in the declaring file")
+	}
+}
+
 func TestSymbolInfoCreation(t *testing.T) {
 	// Test creating SymbolInfo with different properties
 	testCases := []struct {
@@ -0,0 +1,305 @@
+package features
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	protocol "github.com/tliron/glsp/protocol_3_16"
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	"frugal-ls/internal/document"
+)
+
+// ruleDocumentationURL is the page documenting every rule, with one anchor per rule ID
+const ruleDocumentationURL = "https://github.com/charliestrawn/frugal-ls/blob/main/docs/rules.md"
+
+// Names of the built-in lint rules, which may be used in place of their IDs
+const (
+	RuleDuplicateFieldID    = "duplicate-field-id"
+	RuleInvalidFieldID      = "invalid-field-id"
+	RuleDuplicateDefinition = "duplicate-definition"
+	RuleUnresolvedInclude   = "unresolved-include"
+	RuleCircularInclude     = "circular-include"
+	RuleUnknownType         = "unknown-type"
+	RuleNamingConvention    = "naming-convention"
+	RuleBreakingChange      = "breaking-change"
+	RuleReservedField       = "reserved-field"
+	RuleConstType           = "const-type"
+	RuleDefaultValue        = "default-value"
+	RuleServiceExtends      = "service-extends"
+	RuleThrowsType          = "throws-type"
+	RuleUnionField          = "union-field"
+	RuleScopePrefix         = "scope-prefix"
+	RuleAnnotation          = "annotation"
+	RuleDeprecated          = "deprecated"
+)
+
+// IDs of rules whose diagnostics have quick fixes
+const (
+	ruleIDUnresolvedInclude = "FL004"
+	ruleIDThrowsType        = "FL013"
+)
+
+// RuleCheck reports a rule's violations in a document. The provider fills in the code,
+// documentation link and configured severity.
+type RuleCheck func(d *DiagnosticsProvider, doc *document.Document, root *tree_sitter.Node) []protocol.Diagnostic
+
+// Rule is a lint rule run by the diagnostics provider
+type Rule struct {
+	// ID is the stable identifier reported as the diagnostic code, e.g. FL001
+	ID string
+	// Name is a readable alias for the ID, e.g. duplicate-field-id
+	Name        string
+	Description string
+	// DefaultSeverity applies to diagnostics that do not set their own severity when
+	// the rule is not configured
+	DefaultSeverity protocol.DiagnosticSeverity
+	Check           RuleCheck
+}
+
+// DocumentationURL returns the link to the rule's documentation
+func (r *Rule) DocumentationURL() string {
+	return ruleDocumentationURL + "#" + strings.ToLower(r.ID)
+}
+
+var (
+	rules      []*Rule
+	rulesMutex sync.RWMutex
+)
+
+// RegisterRule adds a rule to the registry. It panics if the ID or name is already
+// registered.
+func RegisterRule(rule *Rule) {
+	rulesMutex.Lock()
+	defer rulesMutex.Unlock()
+
+	for _, existing := range rules {
+		if existing.ID == rule.ID || existing.Name == rule.Name {
+			panic(fmt.Sprintf("lint rule %s (%s) registered twice", rule.ID, rule.Name))
+		}
+	}
+	rules = append(rules, rule)
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+}
+
+// Rules returns the registered rules ordered by ID
+func Rules() []*Rule {
+	rulesMutex.RLock()
+	defer rulesMutex.RUnlock()
+
+	return append([]*Rule(nil), rules...)
+}
+
+// LookupRule returns the rule with the given ID or name, or nil
+func LookupRule(idOrName string) *Rule {
+	rulesMutex.RLock()
+	defer rulesMutex.RUnlock()
+
+	for _, rule := range rules {
+		if strings.EqualFold(rule.ID, idOrName) || rule.Name == idOrName {
+			return rule
+		}
+	}
+	return nil
+}
+
+func init() {
+	for _, rule := range []*Rule{
+		{
+			ID:              "FL001",
+			Name:            RuleDuplicateFieldID,
+			Description:     "Field IDs must be unique within a struct, parameter list or throws list",
+			DefaultSeverity: protocol.DiagnosticSeverityError,
+			Check:           (*DiagnosticsProvider).checkDuplicateFieldIDs,
+		},
+		{
+			ID:              "FL002",
+			Name:            RuleInvalidFieldID,
+			Description:     "Field IDs must be positive",
+			DefaultSeverity: protocol.DiagnosticSeverityError,
+			Check:           (*DiagnosticsProvider).checkInvalidFieldIDs,
+		},
+		{
+			ID:              "FL003",
+			Name:            RuleDuplicateDefinition,
+			Description:     "Definitions of the same kind must have unique names",
+			DefaultSeverity: protocol.DiagnosticSeverityError,
+			Check:           (*DiagnosticsProvider).checkDuplicateDefinitions,
+		},
+		{
+			ID:              ruleIDUnresolvedInclude,
+			Name:            RuleUnresolvedInclude,
+			Description:     "Included files must exist",
+			DefaultSeverity: protocol.DiagnosticSeverityError,
+			Check: func(d *DiagnosticsProvider, doc *document.Document, _ *tree_sitter.Node) []protocol.Diagnostic {
+				return d.checkUnresolvedIncludes(doc)
+			},
+		},
+		{
+			ID:              "FL005",
+			Name:            RuleCircularInclude,
+			Description:     "Files must not include themselves, directly or indirectly",
+			DefaultSeverity: protocol.DiagnosticSeverityError,
+			Check: func(d *DiagnosticsProvider, doc *document.Document, _ *tree_sitter.Node) []protocol.Diagnostic {
+				return d.checkCircularIncludes(doc)
+			},
+		},
+		{
+			ID:              "FL006",
+			Name:            RuleUnknownType,
+			Description:     "Referenced types must be defined in the file or an included file",
+			DefaultSeverity: protocol.DiagnosticSeverityError,
+			Check:           (*DiagnosticsProvider).checkTypeReferences,
+		},
+		{
+			ID:              "FL007",
+			Name:            RuleNamingConvention,
+			Description:     "Types use PascalCase and constants use UPPER_SNAKE_CASE",
+			DefaultSeverity: protocol.DiagnosticSeverityWarning,
+			Check:           (*DiagnosticsProvider).checkNamingConventions,
+		},
+		{
+			ID:              "FL008",
+			Name:            RuleBreakingChange,
+			Description:     "Changes against the configured compat baseline must stay wire compatible",
+			DefaultSeverity: protocol.DiagnosticSeverityWarning,
+			Check:           (*DiagnosticsProvider).checkBreakingChanges,
+		},
+		{
+			ID:              "FL009",
+			Name:            RuleReservedField,
+			Description:     "Fields must not reuse IDs or names retired with a @reserved comment",
+			DefaultSeverity: protocol.DiagnosticSeverityError,
+			Check:           (*DiagnosticsProvider).checkReservedFields,
+		},
+		{
+			ID:              "FL010",
+			Name:            RuleConstType,
+			Description:     "Constant values must match their declared type",
+			DefaultSeverity: protocol.DiagnosticSeverityError,
+			Check:           (*DiagnosticsProvider).checkConstValues,
+		},
+		{
+			ID:              "FL011",
+			Name:            RuleDefaultValue,
+			Description:     "Field default values must match the field's type and be reachable",
+			DefaultSeverity: protocol.DiagnosticSeverityError,
+			Check:           (*DiagnosticsProvider).checkDefaultValues,
+		},
+		{
+			ID:              "FL012",
+			Name:            RuleServiceExtends,
+			Description:     "Services must extend a known service without cycles or redefining inherited methods",
+			DefaultSeverity: protocol.DiagnosticSeverityError,
+			Check:           (*DiagnosticsProvider).checkServiceExtends,
+		},
+		{
+			ID:              ruleIDThrowsType,
+			Name:            RuleThrowsType,
+			Description:     "Methods may only throw exceptions, each at most once",
+			DefaultSeverity: protocol.DiagnosticSeverityError,
+			Check:           (*DiagnosticsProvider).checkThrows,
+		},
+		{
+			ID:              "FL014",
+			Name:            RuleUnionField,
+			Description:     "Union fields cannot be required or have default values",
+			DefaultSeverity: protocol.DiagnosticSeverityError,
+			Check:           (*DiagnosticsProvider).checkUnionFields,
+		},
+		{
+			ID:              "FL015",
+			Name:            RuleScopePrefix,
+			Description:     "Scope prefix variables must be well-formed {name} placeholders with unique names",
+			DefaultSeverity: protocol.DiagnosticSeverityError,
+			Check:           (*DiagnosticsProvider).checkScopePrefixes,
+		},
+		{
+			ID:              "FL016",
+			Name:            RuleAnnotation,
+			Description:     "Annotations must be known keys with values of the expected type, each used once per declaration",
+			DefaultSeverity: protocol.DiagnosticSeverityWarning,
+			Check:           (*DiagnosticsProvider).checkAnnotations,
+		},
+		{
+			ID:              "FL017",
+			Name:            RuleDeprecated,
+			Description:     "References to deprecated types, fields and enum values are marked as deprecated",
+			DefaultSeverity: protocol.DiagnosticSeverityHint,
+			Check:           (*DiagnosticsProvider).checkDeprecatedUsages,
+		},
+	} {
+		RegisterRule(rule)
+	}
+}
+
+// suppressionPattern matches frugal-ls:disable directives, optionally followed by the
+// rule IDs or names they apply to
+var suppressionPattern = regexp.MustCompile(`frugal-ls:disable\b([^\n*]*)`)
+
+// fileSuppressions holds the rules disabled by comments in a document
+type fileSuppressions struct {
+	all   bool
+	rules map[string]bool
+}
+
+// findSuppressions reads the frugal-ls:disable comments in a document. A directive
+// without rules disables every rule for the file.
+func (d *DiagnosticsProvider) findSuppressions(doc *document.Document, root *tree_sitter.Node) fileSuppressions {
+	suppressions := fileSuppressions{rules: make(map[string]bool)}
+
+	for _, comment := range d.findAllNodes(root, "comment") {
+		text := comment.Utf8Text(doc.Content)
+		for _, match := range suppressionPattern.FindAllStringSubmatch(text, -1) {
+			names := strings.FieldsFunc(match[1], func(r rune) bool {
+				return r == ',' || r == ' ' || r == '\t' || r == '\r'
+			})
+			if len(names) == 0 {
+				suppressions.all = true
+			}
+			for _, name := range names {
+				if rule := LookupRule(name); rule != nil {
+					suppressions.rules[rule.ID] = true
+				}
+			}
+		}
+	}
+
+	return suppressions
+}
+
+// disables reports whether the suppressions turn off a rule
+func (s fileSuppressions) disables(rule *Rule) bool {
+	return s.all || s.rules[rule.ID]
+}
+
+// runRule runs a rule unless it is disabled and labels its diagnostics with the rule's
+// code, documentation link and severity
+func (d *DiagnosticsProvider) runRule(rule *Rule, doc *document.Document, root *tree_sitter.Node) []protocol.Diagnostic {
+	d.configMutex.RLock()
+	severity, configured, enabled := d.lint.RuleSeverity(rule.ID, rule.Name)
+	d.configMutex.RUnlock()
+	if !enabled {
+		return nil
+	}
+
+	diagnostics := rule.Check(d, doc, root)
+	for i := range diagnostics {
+		diagnostic := &diagnostics[i]
+		switch {
+		case configured:
+			diagnostic.Severity = &[]protocol.DiagnosticSeverity{severity}[0]
+		case diagnostic.Severity == nil:
+			diagnostic.Severity = &[]protocol.DiagnosticSeverity{rule.DefaultSeverity}[0]
+		}
+		diagnostic.Code = &protocol.IntegerOrString{Value: rule.ID}
+		diagnostic.CodeDescription = &protocol.CodeDescription{HRef: rule.DocumentationURL()}
+		if diagnostic.Source == nil {
+			diagnostic.Source = &[]string{"frugal-ls"}[0]
+		}
+	}
+	return diagnostics
+}
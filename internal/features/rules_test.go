@@ -0,0 +1,152 @@
+package features
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	protocol "github.com/tliron/glsp/protocol_3_16"
+
+	"frugal-ls/internal/compat"
+	"frugal-ls/internal/config"
+)
+
+func TestRuleRegistry(t *testing.T) {
+	registered := Rules()
+	if len(registered) < 7 {
+		t.Fatalf("Expected the built-in rules to be registered, got %d", len(registered))
+	}
+
+	for i, rule := range registered {
+		if i > 0 && registered[i-1].ID >= rule.ID {
+			t.Errorf("Expected rules ordered by ID, got %s before %s", registered[i-1].ID, rule.ID)
+		}
+		if rule.Name == "" || rule.Description == "" || rule.Check == nil {
+			t.Errorf("Rule %s is incomplete: %+v", rule.ID, rule)
+		}
+	}
+
+	rule := LookupRule(RuleDuplicateFieldID)
+	if rule == nil || rule.ID != "FL001" || LookupRule("fl001") != rule {
+		t.Fatalf("Expected FL001 to be found by name and ID, got %+v", rule)
+	}
+	if !strings.HasSuffix(rule.DocumentationURL(), "rules.md#fl001") {
+		t.Errorf("Unexpected documentation URL %s", rule.DocumentationURL())
+	}
+	if LookupRule("FL999") != nil {
+		t.Error("Expected unknown rules not to be found")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected registering a duplicate rule ID to panic")
+		}
+	}()
+	RegisterRule(&Rule{ID: "FL001", Name: "another-rule"})
+}
+
+func TestRuleDiagnosticCodes(t *testing.T) {
+	content := `struct User {
+    1: i64 id,
+    1: string name
+}`
+
+	doc := createTestDocumentForDiagnostics(t, "file:///test.frugal", content)
+	defer doc.ParseResult.Close()
+
+	diagnostics := NewDiagnosticsProvider().ProvideDiagnostics(doc)
+	if len(diagnostics) != 1 {
+		t.Fatalf("Expected 1 diagnostic, got %+v", diagnostics)
+	}
+
+	diagnostic := diagnostics[0]
+	if diagnostic.Code == nil || diagnostic.Code.Value != "FL001" {
+		t.Errorf("Expected code FL001, got %+v", diagnostic.Code)
+	}
+	if diagnostic.CodeDescription == nil || !strings.HasSuffix(diagnostic.CodeDescription.HRef, "#fl001") {
+		t.Errorf("Expected a documentation link, got %+v", diagnostic.CodeDescription)
+	}
+}
+
+func TestRuleSuppressionComments(t *testing.T) {
+	source := `struct user_info {
+    1: Missing m,
+    1: i32 dup
+}`
+
+	tests := []struct {
+		name      string
+		directive string
+		expected  []string
+	}{
+		{"none", "", []string{"FL001", "FL006", "FL007"}},
+		{"by id", "// frugal-ls:disable FL006\n", []string{"FL001", "FL007"}},
+		{"by name and id", "// frugal-ls:disable naming-convention, FL001\n", []string{"FL006"}},
+		{"block comment", "/* frugal-ls:disable FL007 */\n", []string{"FL001", "FL006"}},
+		{"all rules", "// frugal-ls:disable\n", nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			doc := createTestDocumentForDiagnostics(t, "file:///test.frugal", test.directive+source)
+			defer doc.ParseResult.Close()
+
+			var codes []string
+			for _, diagnostic := range NewDiagnosticsProvider().ProvideDiagnostics(doc) {
+				codes = append(codes, diagnostic.Code.Value.(string))
+			}
+			if strings.Join(codes, ",") != strings.Join(test.expected, ",") {
+				t.Errorf("Expected codes %v, got %v", test.expected, codes)
+			}
+		})
+	}
+}
+
+func TestRuleConfigByID(t *testing.T) {
+	content := `struct user_info {
+    1: i32 id
+}`
+
+	doc := createTestDocumentForDiagnostics(t, "file:///test.frugal", content)
+	defer doc.ParseResult.Close()
+
+	provider := NewDiagnosticsProvider()
+	provider.SetLintConfig(config.Lint{Rules: map[string]config.Severity{"FL007": config.SeverityHint}})
+
+	diagnostics := provider.ProvideDiagnostics(doc)
+	if len(diagnostics) != 1 || *diagnostics[0].Severity != protocol.DiagnosticSeverityHint {
+		t.Errorf("Expected the naming diagnostic as a hint, got %+v", diagnostics)
+	}
+}
+
+func TestRuleBreakingChanges(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "user.frugal"), []byte("struct User {\n    1: string name\n}"), 0o644); err != nil {
+		t.Fatalf("Failed to write baseline: %v", err)
+	}
+	snapshot, err := compat.LoadDir(dir, nil)
+	if err != nil {
+		t.Fatalf("Failed to load baseline: %v", err)
+	}
+
+	doc := createTestDocumentForDiagnostics(t, "file:///workspace/user.frugal", "struct User {\n    1: i64 name\n}")
+	defer doc.ParseResult.Close()
+
+	provider := NewDiagnosticsProvider()
+	if diagnostics := provider.ProvideDiagnostics(doc); len(diagnostics) != 0 {
+		t.Fatalf("Expected no diagnostics without a baseline, got %+v", diagnostics)
+	}
+
+	provider.SetBaseline(&compat.Baseline{Root: "/workspace", Snapshot: snapshot})
+	diagnostics := provider.ProvideDiagnostics(doc)
+	if len(diagnostics) != 1 || diagnostics[0].Code.Value != "FL008" {
+		t.Fatalf("Expected a breaking change diagnostic, got %+v", diagnostics)
+	}
+	if diagnostics[0].Data != compat.KindFieldTypeChanged {
+		t.Errorf("Expected the change kind in the diagnostic data, got %v", diagnostics[0].Data)
+	}
+	if *diagnostics[0].Severity != protocol.DiagnosticSeverityWarning || !strings.Contains(diagnostics[0].Message, "changed from string to i64") {
+		t.Errorf("Unexpected diagnostic %+v", diagnostics[0])
+	}
+}
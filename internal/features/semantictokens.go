@@ -7,17 +7,25 @@ import (
 	tree_sitter "github.com/tree-sitter/go-tree-sitter"
 
 	"frugal-ls/internal/document"
+	"frugal-ls/internal/workspace"
 	"frugal-ls/pkg/ast"
 )
 
 // SemanticTokensProvider provides semantic token highlighting for Frugal files
-type SemanticTokensProvider struct{}
+type SemanticTokensProvider struct {
+	typeResolver *workspace.TypeResolver
+}
 
 // NewSemanticTokensProvider creates a new semantic tokens provider
 func NewSemanticTokensProvider() *SemanticTokensProvider {
 	return &SemanticTokensProvider{}
 }
 
+// SetTypeResolver enables marking references to deprecated declarations in included files
+func (s *SemanticTokensProvider) SetTypeResolver(resolver *workspace.TypeResolver) {
+	s.typeResolver = resolver
+}
+
 // Token represents a semantic token with position and classification
 type Token struct {
 	Line      uint32
@@ -100,6 +108,8 @@ func (s *SemanticTokensProvider) ProvideSemanticTokens(doc *document.Document) (
 
 	// Walk the AST and collect tokens
 	s.walkNodeForTokens(root, doc.Content, &tokens)
+	tokens = append(tokens, s.prefixTokens(doc.GetModel())...)
+	s.markDeprecated(tokens, doc)
 
 	// Sort tokens by position
 	sort.Slice(tokens, func(i, j int) bool {
@@ -134,7 +144,7 @@ func (s *SemanticTokensProvider) walkNodeForTokens(node *tree_sitter.Node, sourc
 
 	// Classify different node types
 	switch nodeType {
-	case "include", "namespace", "service", "scope", "struct", "enum", "exception",
+	case "include", "namespace", "service", "scope", "struct", "union", "enum", "exception",
 		"const", "typedef", "throws", "extends", "oneway", "required", "optional", "prefix":
 		// Keywords
 		*tokens = append(*tokens, Token{
@@ -215,6 +225,65 @@ func (s *SemanticTokensProvider) walkNodeForTokens(node *tree_sitter.Node, sourc
 	}
 }
 
+// prefixTokens splits scope prefixes into string tokens for their literal text and
+// parameter tokens for their {variable} placeholders
+func (s *SemanticTokensProvider) prefixTokens(model *ast.File) []Token {
+	var tokens []Token
+	add := func(line, start, end int, tokenType uint32) {
+		if end > start {
+			tokens = append(tokens, Token{
+				Line:      uint32(line),
+				Character: uint32(start),
+				Length:    uint32(end - start),
+				TokenType: tokenType,
+			})
+		}
+	}
+
+	for _, scope := range model.Scopes {
+		// Identifier prefixes have no placeholders and are tokenized as identifiers
+		if scope.PrefixRange.End.Column-scope.PrefixRange.Start.Column == len(scope.Prefix) {
+			continue
+		}
+		line := scope.PrefixRange.Start.Line
+
+		// Quotes around a string prefix are part of the string
+		column := scope.PrefixRange.Start.Column
+		for _, segment := range scope.PrefixSegments {
+			tokenType := TokenTypeString
+			if segment.Variable {
+				add(line, column, segment.Range.Start.Column, TokenTypeString)
+				column = segment.Range.Start.Column
+				tokenType = TokenTypeParameter
+			}
+			add(line, column, segment.Range.End.Column, tokenType)
+			column = segment.Range.End.Column
+		}
+		add(line, column, scope.PrefixRange.End.Column, TokenTypeString)
+	}
+
+	return tokens
+}
+
+// markDeprecated adds the deprecated modifier to the names of deprecated declarations
+// and to references to them
+func (s *SemanticTokensProvider) markDeprecated(tokens []Token, doc *document.Document) {
+	ranges := deprecatedDeclarations(doc.GetModel())
+	for _, usage := range deprecatedUsages(doc, s.typeResolver) {
+		ranges = append(ranges, usage.Range)
+	}
+
+	for i := range tokens {
+		token := &tokens[i]
+		for _, rng := range ranges {
+			if rng.Start.Line == int(token.Line) && rng.Start.Column < int(token.Character+token.Length) && int(token.Character) < rng.End.Column {
+				token.Modifiers |= 1 << TokenModifierDeprecated
+				break
+			}
+		}
+	}
+}
+
 // classifyIdentifier determines the token type and modifiers for an identifier based on context
 func (s *SemanticTokensProvider) classifyIdentifier(node *tree_sitter.Node, source []byte) (uint32, uint32) {
 	parent := node.Parent()
@@ -229,8 +298,8 @@ func (s *SemanticTokensProvider) classifyIdentifier(node *tree_sitter.Node, sour
 		// Service name declaration
 		return TokenTypeClass, 1 << TokenModifierDeclaration
 
-	case "struct_definition", "exception_definition":
-		// Struct/exception name declaration
+	case "struct_definition", "exception_definition", "union_definition":
+		// Struct/exception/union name declaration
 		return TokenTypeClass, 1 << TokenModifierDeclaration
 
 	case "enum_definition":
@@ -265,6 +334,10 @@ func (s *SemanticTokensProvider) classifyIdentifier(node *tree_sitter.Node, sour
 		// Namespace value
 		return TokenTypeNamespace, 0
 
+	case "annotation_list":
+		// Annotation key
+		return TokenTypeDecorator, 0
+
 	default:
 		// Check if it's a type reference
 		if s.isTypeReference(node, parent) {
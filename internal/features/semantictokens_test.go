@@ -1,6 +1,7 @@
 package features
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
@@ -213,3 +214,90 @@ func createTestDocumentForSemanticTokens(uri, content string) (*document.Documen
 
 	return doc, nil
 }
+
+func TestSemanticTokensScopePrefix(t *testing.T) {
+	content := `scope UserEvents prefix "user.{region}" {
+    Created: string
+}`
+
+	doc, err := createTestDocumentForSemanticTokens("file:///test.frugal", content)
+	if err != nil {
+		t.Fatalf("Failed to create document: %v", err)
+	}
+	defer doc.ParseResult.Close()
+
+	var prefix []string
+	for _, token := range NewSemanticTokensProvider().prefixTokens(doc.GetModel()) {
+		prefix = append(prefix, fmt.Sprintf("%d+%d:%d", token.Character, token.Length, token.TokenType))
+	}
+	expected := []string{"24+6:1", "30+8:12", "38+1:1"}
+	if strings.Join(prefix, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected prefix tokens %v, got %v", expected, prefix)
+	}
+}
+
+func TestSemanticTokensAnnotationKeys(t *testing.T) {
+	content := `struct User {
+    1: string id (go.tag = "json")
+}`
+
+	doc, err := createTestDocumentForSemanticTokens("file:///test.frugal", content)
+	if err != nil {
+		t.Fatalf("Failed to create document: %v", err)
+	}
+	defer doc.ParseResult.Close()
+
+	var tokens []Token
+	NewSemanticTokensProvider().walkNodeForTokens(doc.ParseResult.GetRootNode(), doc.Content, &tokens)
+	for _, token := range tokens {
+		if token.Line == 1 && token.Character == 18 {
+			if token.TokenType != TokenTypeDecorator || token.Length != 6 {
+				t.Errorf("Expected go.tag to be a decorator token, got %+v", token)
+			}
+			return
+		}
+	}
+	t.Error("Expected a token for the annotation key")
+}
+
+func TestSemanticTokensDeprecated(t *testing.T) {
+	content := `struct User {
+    1: string email (deprecated)
+}
+
+struct Account {
+    1: Legacy legacy
+}
+
+// @deprecated
+struct Legacy {}`
+
+	doc, err := createTestDocumentForSemanticTokens("file:///test.frugal", content)
+	if err != nil {
+		t.Fatalf("Failed to create document: %v", err)
+	}
+	defer doc.ParseResult.Close()
+
+	result, err := NewSemanticTokensProvider().ProvideSemanticTokens(doc)
+	if err != nil {
+		t.Fatalf("ProvideSemanticTokens failed: %v", err)
+	}
+
+	// Decode the relative positions of the tokens marked deprecated
+	var deprecated []string
+	var line, character uint32
+	for i := 0; i+4 < len(result.Data); i += 5 {
+		if result.Data[i] > 0 {
+			character = 0
+		}
+		line += result.Data[i]
+		character += result.Data[i+1]
+		if result.Data[i+4]&(1<<TokenModifierDeprecated) != 0 {
+			deprecated = append(deprecated, fmt.Sprintf("%d:%d", line, character))
+		}
+	}
+	expected := []string{"1:14", "5:7", "9:7"}
+	if strings.Join(deprecated, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected deprecated tokens at %v, got %v", expected, deprecated)
+	}
+}
@@ -4,20 +4,28 @@ import (
 	"strings"
 
 	protocol "github.com/tliron/glsp/protocol_3_16"
-	tree_sitter "github.com/tree-sitter/go-tree-sitter"
 
 	"frugal-ls/internal/document"
+	"frugal-ls/internal/workspace"
 	"frugal-ls/pkg/ast"
 )
 
 // DocumentSymbolProvider handles document symbol outline for Frugal files
-type DocumentSymbolProvider struct{}
+type DocumentSymbolProvider struct {
+	typeResolver *workspace.TypeResolver
+}
 
 // NewDocumentSymbolProvider creates a new document symbol provider
 func NewDocumentSymbolProvider() *DocumentSymbolProvider {
 	return &DocumentSymbolProvider{}
 }
 
+// SetTypeResolver sets the resolver used to list methods inherited from services in
+// included files
+func (d *DocumentSymbolProvider) SetTypeResolver(resolver *workspace.TypeResolver) {
+	d.typeResolver = resolver
+}
+
 // ProvideDocumentSymbols provides a hierarchical outline of symbols in the document
 func (d *DocumentSymbolProvider) ProvideDocumentSymbols(doc *document.Document) ([]protocol.DocumentSymbol, error) {
 	symbols := doc.GetSymbols()
@@ -134,7 +142,7 @@ func (d *DocumentSymbolProvider) getSymbolKind(nodeType ast.NodeType) protocol.S
 		return protocol.SymbolKindClass
 	case ast.NodeTypeScope:
 		return protocol.SymbolKindClass
-	case ast.NodeTypeStruct:
+	case ast.NodeTypeStruct, ast.NodeTypeUnion:
 		return protocol.SymbolKindStruct
 	case ast.NodeTypeEnum:
 		return protocol.SymbolKindEnum
@@ -170,6 +178,8 @@ func (d *DocumentSymbolProvider) getSymbolDetail(symbol ast.Symbol, doc *documen
 		return "Type Alias"
 	case ast.NodeTypeException:
 		return "Exception"
+	case ast.NodeTypeUnion:
+		return "Union"
 	case ast.NodeTypeInclude:
 		return "Include"
 	case ast.NodeTypeNamespace:
@@ -183,350 +193,93 @@ func (d *DocumentSymbolProvider) getSymbolDetail(symbol ast.Symbol, doc *documen
 func (d *DocumentSymbolProvider) getSymbolChildren(symbol ast.Symbol, doc *document.Document) []protocol.DocumentSymbol {
 	var children []protocol.DocumentSymbol
 
-	if symbol.Node == nil {
-		return children
-	}
+	model := doc.GetModel()
 
 	switch symbol.Type {
 	case ast.NodeTypeService:
-		children = d.extractServiceMethods(symbol.Node, doc)
-	case ast.NodeTypeScope:
-		children = d.extractScopeEvents(symbol.Node, doc)
-	case ast.NodeTypeStruct, ast.NodeTypeException:
-		children = d.extractStructFields(symbol.Node, doc)
-	case ast.NodeTypeEnum:
-		children = d.extractEnumValues(symbol.Node, doc)
-	}
-
-	return children
-}
-
-// extractServiceMethods extracts method symbols from a service
-func (d *DocumentSymbolProvider) extractServiceMethods(serviceNode *tree_sitter.Node, doc *document.Document) []protocol.DocumentSymbol {
-	var methods []protocol.DocumentSymbol
-
-	// Find service body
-	serviceBody := ast.FindNodeByType(serviceNode, "service_body")
-	if serviceBody == nil {
-		return methods
-	}
-
-	// Extract methods from service body
-	childCount := serviceBody.ChildCount()
-	for i := uint(0); i < childCount; i++ {
-		child := serviceBody.Child(i)
-		if d.isMethodNode(child) {
-			method := d.extractMethodSymbol(child, doc)
-			if method != nil {
-				methods = append(methods, *method)
+		if service := model.FindService(symbol.Name); service != nil {
+			for _, method := range service.Methods {
+				children = append(children, d.newChildSymbol(method.Name, protocol.SymbolKindMethod, "Method", method.Range, method.NameRange))
 			}
+			children = append(children, d.getInheritedSymbols(doc, service)...)
 		}
-	}
-
-	return methods
-}
-
-// extractScopeEvents extracts event symbols from a scope
-func (d *DocumentSymbolProvider) extractScopeEvents(scopeNode *tree_sitter.Node, doc *document.Document) []protocol.DocumentSymbol {
-	var events []protocol.DocumentSymbol
-
-	// Find scope body
-	scopeBody := ast.FindNodeByType(scopeNode, "scope_body")
-	if scopeBody == nil {
-		return events
-	}
-
-	// Extract events from scope body
-	childCount := scopeBody.ChildCount()
-	for i := uint(0); i < childCount; i++ {
-		child := scopeBody.Child(i)
-		if d.isEventNode(child) {
-			event := d.extractEventSymbol(child, doc)
-			if event != nil {
-				events = append(events, *event)
+	case ast.NodeTypeScope:
+		for _, scope := range model.Scopes {
+			if scope.Name != symbol.Name {
+				continue
+			}
+			for _, segment := range scope.PrefixSegments {
+				if segment.Variable {
+					children = append(children, d.newChildSymbol(segment.Text, protocol.SymbolKindVariable, "Prefix variable", segment.Range, segment.Range))
+				}
+			}
+			for _, event := range scope.Events {
+				children = append(children, d.newChildSymbol(event.Name, protocol.SymbolKindEvent, "Event", event.Range, event.NameRange))
 			}
 		}
-	}
-
-	return events
-}
-
-// extractStructFields extracts field symbols from a struct
-func (d *DocumentSymbolProvider) extractStructFields(structNode *tree_sitter.Node, doc *document.Document) []protocol.DocumentSymbol {
-	var fields []protocol.DocumentSymbol
-
-	// Find struct body
-	structBody := ast.FindNodeByType(structNode, "struct_body")
-	if structBody == nil {
-		return fields
-	}
-
-	// Extract fields from struct body
-	childCount := structBody.ChildCount()
-	for i := uint(0); i < childCount; i++ {
-		child := structBody.Child(i)
-		if d.isFieldNode(child) {
-			field := d.extractFieldSymbol(child, doc)
-			if field != nil {
-				fields = append(fields, *field)
+	case ast.NodeTypeStruct, ast.NodeTypeException, ast.NodeTypeUnion:
+		if structDef := model.FindStruct(symbol.Name); structDef != nil {
+			for _, field := range structDef.Fields {
+				children = append(children, d.newChildSymbol(field.Name, protocol.SymbolKindField, "Field", field.Range, field.NameRange))
 			}
 		}
-	}
-
-	return fields
-}
-
-// extractEnumValues extracts value symbols from an enum
-func (d *DocumentSymbolProvider) extractEnumValues(enumNode *tree_sitter.Node, doc *document.Document) []protocol.DocumentSymbol {
-	var values []protocol.DocumentSymbol
-
-	// Find enum body
-	enumBody := ast.FindNodeByType(enumNode, "enum_body")
-	if enumBody == nil {
-		return values
-	}
-
-	// Extract values from enum body
-	childCount := enumBody.ChildCount()
-	for i := uint(0); i < childCount; i++ {
-		child := enumBody.Child(i)
-		if d.isEnumValueNode(child) {
-			value := d.extractEnumValueSymbol(child, doc)
-			if value != nil {
-				values = append(values, *value)
+	case ast.NodeTypeEnum:
+		if enum := model.FindEnum(symbol.Name); enum != nil {
+			for _, value := range enum.Values {
+				children = append(children, d.newChildSymbol(value.Name, protocol.SymbolKindEnumMember, "Enum Value", value.Range, value.NameRange))
 			}
 		}
 	}
 
-	return values
-}
-
-// Helper methods to check node types
-func (d *DocumentSymbolProvider) isMethodNode(node *tree_sitter.Node) bool {
-	kind := node.Kind()
-	return kind == "method" || kind == "function" ||
-		(kind == nodeTypeIdentifier && d.looksLikeMethod(node))
-}
-
-func (d *DocumentSymbolProvider) isEventNode(node *tree_sitter.Node) bool {
-	kind := node.Kind()
-	return kind == "event" || kind == nodeTypeIdentifier
-}
-
-func (d *DocumentSymbolProvider) isFieldNode(node *tree_sitter.Node) bool {
-	kind := node.Kind()
-	return kind == "field" || kind == "struct_field" ||
-		(kind == nodeTypeIdentifier && d.looksLikeField(node))
-}
-
-func (d *DocumentSymbolProvider) isEnumValueNode(node *tree_sitter.Node) bool {
-	kind := node.Kind()
-	return kind == "enum_value" || kind == "identifier"
+	return children
 }
 
-// Helper methods to extract specific symbol types
-func (d *DocumentSymbolProvider) extractMethodSymbol(node *tree_sitter.Node, doc *document.Document) *protocol.DocumentSymbol {
-	// Get method name and signature
-	methodText := ast.GetText(node, doc.Content)
-	if methodText == "" {
+// getInheritedSymbols groups the methods a service inherits under one symbol per
+// ancestor. The symbols span the extends clause, since the methods live elsewhere.
+func (d *DocumentSymbolProvider) getInheritedSymbols(doc *document.Document, service *ast.Service) []protocol.DocumentSymbol {
+	if service.Extends == "" {
 		return nil
 	}
 
-	// Try to extract method name
-	var methodName string
-	nameNode := ast.FindNodeByType(node, "identifier")
-	if nameNode != nil {
-		methodName = ast.GetText(nameNode, doc.Content)
-	}
-
-	if methodName == "" {
-		// Fallback: try to parse method name from text
-		parts := strings.Fields(strings.TrimSpace(methodText))
-		if len(parts) > 0 {
-			methodName = parts[0]
+	ancestors, _ := serviceAncestors(doc, d.typeResolver, service)
+	var groups []protocol.DocumentSymbol
+	for _, inherited := range inheritedMethods(ancestors, service) {
+		if len(groups) == 0 || groups[len(groups)-1].Name != inherited.from.Service.Name {
+			groups = append(groups, d.newChildSymbol(inherited.from.Service.Name, protocol.SymbolKindInterface,
+				"Inherited", service.ExtendsRange, service.ExtendsRange))
 		}
+		group := &groups[len(groups)-1]
+		group.Children = append(group.Children, d.newChildSymbol(inherited.method.Name, protocol.SymbolKindMethod,
+			"Inherited method", service.ExtendsRange, service.ExtendsRange))
 	}
-
-	if methodName == "" {
-		return nil
-	}
-
-	return &protocol.DocumentSymbol{
-		Name: methodName,
-		Kind: protocol.SymbolKindMethod,
-		Range: protocol.Range{
-			Start: protocol.Position{
-				Line:      uint32(node.StartPosition().Row),
-				Character: uint32(node.StartPosition().Column),
-			},
-			End: protocol.Position{
-				Line:      uint32(node.EndPosition().Row),
-				Character: uint32(node.EndPosition().Column),
-			},
-		},
-		SelectionRange: protocol.Range{
-			Start: protocol.Position{
-				Line:      uint32(node.StartPosition().Row),
-				Character: uint32(node.StartPosition().Column),
-			},
-			End: protocol.Position{
-				Line:      uint32(node.StartPosition().Row),
-				Character: uint32(node.StartPosition().Column) + uint32(len(methodName)),
-			},
-		},
-		Detail: &[]string{"Method"}[0],
-	}
+	return groups
 }
 
-func (d *DocumentSymbolProvider) extractEventSymbol(node *tree_sitter.Node, doc *document.Document) *protocol.DocumentSymbol {
-	eventText := ast.GetText(node, doc.Content)
-	if eventText == "" {
-		return nil
-	}
-
-	// Parse event name from "EventName: Type" format
-	parts := strings.Split(strings.TrimSpace(eventText), ":")
-	if len(parts) < 1 {
-		return nil
-	}
-
-	eventName := strings.TrimSpace(parts[0])
-	if eventName == "" {
-		return nil
-	}
-
-	return &protocol.DocumentSymbol{
-		Name: eventName,
-		Kind: protocol.SymbolKindEvent,
-		Range: protocol.Range{
-			Start: protocol.Position{
-				Line:      uint32(node.StartPosition().Row),
-				Character: uint32(node.StartPosition().Column),
-			},
-			End: protocol.Position{
-				Line:      uint32(node.EndPosition().Row),
-				Character: uint32(node.EndPosition().Column),
-			},
-		},
-		SelectionRange: protocol.Range{
-			Start: protocol.Position{
-				Line:      uint32(node.StartPosition().Row),
-				Character: uint32(node.StartPosition().Column),
-			},
-			End: protocol.Position{
-				Line:      uint32(node.StartPosition().Row),
-				Character: uint32(node.StartPosition().Column) + uint32(len(eventName)),
-			},
-		},
-		Detail: &[]string{"Event"}[0],
+// newChildSymbol creates a document symbol for a member of a structured type
+func (d *DocumentSymbolProvider) newChildSymbol(name string, kind protocol.SymbolKind, detail string, fullRange, nameRange ast.Range) protocol.DocumentSymbol {
+	return protocol.DocumentSymbol{
+		Name:           name,
+		Kind:           kind,
+		Range:          modelRangeToProtocol(fullRange),
+		SelectionRange: modelRangeToProtocol(nameRange),
+		Detail:         &detail,
 	}
 }
 
-func (d *DocumentSymbolProvider) extractFieldSymbol(node *tree_sitter.Node, doc *document.Document) *protocol.DocumentSymbol {
-	fieldText := ast.GetText(node, doc.Content)
-	if fieldText == "" {
-		return nil
-	}
-
-	// Try to extract field name from various formats
-	// "1: required string name"
-	// "2: optional i32 id"
-	var fieldName string
-	nameNode := ast.FindNodeByType(node, "identifier")
-	if nameNode != nil {
-		fieldName = ast.GetText(nameNode, doc.Content)
-	}
-
-	if fieldName == "" {
-		// Fallback: try to parse field name from text
-		parts := strings.Fields(strings.TrimSpace(fieldText))
-		if len(parts) > 0 {
-			fieldName = parts[len(parts)-1] // Usually the last part
-		}
-	}
-
-	if fieldName == "" {
-		return nil
-	}
-
-	return &protocol.DocumentSymbol{
-		Name: fieldName,
-		Kind: protocol.SymbolKindField,
-		Range: protocol.Range{
-			Start: protocol.Position{
-				Line:      uint32(node.StartPosition().Row),
-				Character: uint32(node.StartPosition().Column),
-			},
-			End: protocol.Position{
-				Line:      uint32(node.EndPosition().Row),
-				Character: uint32(node.EndPosition().Column),
-			},
-		},
-		SelectionRange: protocol.Range{
-			Start: protocol.Position{
-				Line:      uint32(node.StartPosition().Row),
-				Character: uint32(node.StartPosition().Column),
-			},
-			End: protocol.Position{
-				Line:      uint32(node.StartPosition().Row),
-				Character: uint32(node.StartPosition().Column) + uint32(len(fieldName)),
-			},
-		},
-		Detail: &[]string{"Field"}[0],
-	}
-}
-
-func (d *DocumentSymbolProvider) extractEnumValueSymbol(node *tree_sitter.Node, doc *document.Document) *protocol.DocumentSymbol {
-	valueText := ast.GetText(node, doc.Content)
-	if valueText == "" {
-		return nil
-	}
-
-	// Parse enum value from "VALUE = 1" format
-	parts := strings.Split(strings.TrimSpace(valueText), "=")
-	valueName := strings.TrimSpace(parts[0])
-	if valueName == "" {
-		return nil
-	}
-
-	return &protocol.DocumentSymbol{
-		Name: valueName,
-		Kind: protocol.SymbolKindEnumMember,
-		Range: protocol.Range{
-			Start: protocol.Position{
-				Line:      uint32(node.StartPosition().Row),
-				Character: uint32(node.StartPosition().Column),
-			},
-			End: protocol.Position{
-				Line:      uint32(node.EndPosition().Row),
-				Character: uint32(node.EndPosition().Column),
-			},
+// modelRangeToProtocol converts a model range to an LSP range
+func modelRangeToProtocol(r ast.Range) protocol.Range {
+	return protocol.Range{
+		Start: protocol.Position{
+			Line:      uint32(r.Start.Line),
+			Character: uint32(r.Start.Column),
 		},
-		SelectionRange: protocol.Range{
-			Start: protocol.Position{
-				Line:      uint32(node.StartPosition().Row),
-				Character: uint32(node.StartPosition().Column),
-			},
-			End: protocol.Position{
-				Line:      uint32(node.StartPosition().Row),
-				Character: uint32(node.StartPosition().Column) + uint32(len(valueName)),
-			},
+		End: protocol.Position{
+			Line:      uint32(r.End.Line),
+			Character: uint32(r.End.Column),
 		},
-		Detail: &[]string{"Enum Value"}[0],
 	}
 }
 
-// Helper methods for heuristics
-func (d *DocumentSymbolProvider) looksLikeMethod(node *tree_sitter.Node) bool {
-	// Heuristic: check if the node text contains parentheses (method signature)
-	return strings.Contains(ast.GetText(node, []byte{}), "(")
-}
-
-func (d *DocumentSymbolProvider) looksLikeField(node *tree_sitter.Node) bool {
-	// Heuristic: check if the node is part of a field definition
-	return strings.Contains(ast.GetText(node, []byte{}), ":")
-}
-
 // containsIgnoreCase checks if s contains substr (case insensitive)
 func containsIgnoreCase(s, substr string) bool {
 	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
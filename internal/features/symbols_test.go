@@ -301,6 +301,7 @@ func TestConvertToDocumentSymbolAllTypes(t *testing.T) {
 		{ast.NodeTypeService, protocol.SymbolKindClass},
 		{ast.NodeTypeEnum, protocol.SymbolKindEnum},
 		{ast.NodeTypeException, protocol.SymbolKindClass},
+		{ast.NodeTypeUnion, protocol.SymbolKindStruct},
 		{ast.NodeTypeConst, protocol.SymbolKindConstant},
 		{ast.NodeTypeTypedef, protocol.SymbolKindTypeParameter},
 		{ast.NodeTypeScope, protocol.SymbolKindClass},
@@ -383,3 +384,27 @@ func TestSymbolRangeCalculation(t *testing.T) {
 		t.Errorf("Expected selection range line 5, got %d", docSymbol.SelectionRange.Start.Line)
 	}
 }
+
+func TestDocumentSymbolsUnion(t *testing.T) {
+	content := `union Value {
+    1: string text,
+    2: i64 number
+}`
+
+	doc, err := createTestDocumentForHover("file:///test.frugal", content)
+	if err != nil {
+		t.Fatalf("Failed to create document: %v", err)
+	}
+	defer doc.ParseResult.Close()
+
+	symbols, err := NewDocumentSymbolProvider().ProvideDocumentSymbols(doc)
+	if err != nil {
+		t.Fatalf("ProvideDocumentSymbols failed: %v", err)
+	}
+	if len(symbols) != 1 || symbols[0].Name != "Value" || *symbols[0].Detail != "Union" {
+		t.Fatalf("Expected union symbol Value, got %+v", symbols)
+	}
+	if children := symbols[0].Children; len(children) != 2 || children[0].Name != "text" || children[1].Name != "number" {
+		t.Errorf("Expected union fields as children, got %+v", children)
+	}
+}
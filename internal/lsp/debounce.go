@@ -0,0 +1,92 @@
+package lsp
+
+import (
+	"sync"
+	"time"
+
+	"github.com/tliron/glsp"
+)
+
+// diagnosticsDebouncer coalesces documents scheduled for diagnostics in quick succession
+// and publishes them once edits have been quiet for the configured delay
+type diagnosticsDebouncer struct {
+	delay   time.Duration
+	publish func(context *glsp.Context, uris []string)
+
+	mutex   sync.Mutex
+	pending map[string]bool
+	context *glsp.Context
+	timer   *time.Timer
+	// stopped is set once the server shuts down, after which nothing is published
+	stopped bool
+}
+
+// newDiagnosticsDebouncer creates a debouncer that calls publish after delay
+func newDiagnosticsDebouncer(delay time.Duration, publish func(context *glsp.Context, uris []string)) *diagnosticsDebouncer {
+	return &diagnosticsDebouncer{
+		delay:   delay,
+		publish: publish,
+		pending: make(map[string]bool),
+	}
+}
+
+// Schedule adds documents to the pending set and restarts the quiet period
+func (d *diagnosticsDebouncer) Schedule(context *glsp.Context, uris ...string) {
+	if len(uris) == 0 {
+		return
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.stopped {
+		return
+	}
+	for _, uri := range uris {
+		d.pending[uri] = true
+	}
+	d.context = context
+
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.timer = time.AfterFunc(d.delay, d.Flush)
+}
+
+// Flush publishes all pending documents immediately. It does nothing once stopped.
+func (d *diagnosticsDebouncer) Flush() {
+	d.mutex.Lock()
+	if d.stopped {
+		d.mutex.Unlock()
+		return
+	}
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	uris := make([]string, 0, len(d.pending))
+	for uri := range d.pending {
+		uris = append(uris, uri)
+	}
+	d.pending = make(map[string]bool)
+	context := d.context
+	d.mutex.Unlock()
+
+	if len(uris) > 0 {
+		d.publish(context, uris)
+	}
+}
+
+// Stop discards pending documents without publishing them and ignores documents
+// scheduled later. It is safe to call more than once.
+func (d *diagnosticsDebouncer) Stop() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.stopped = true
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	d.pending = make(map[string]bool)
+}
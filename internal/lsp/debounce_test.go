@@ -0,0 +1,64 @@
+package lsp
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tliron/glsp"
+)
+
+func TestDiagnosticsDebouncerCoalesces(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]string
+	done := make(chan struct{}, 1)
+
+	debouncer := newDiagnosticsDebouncer(20*time.Millisecond, func(context *glsp.Context, uris []string) {
+		mu.Lock()
+		defer mu.Unlock()
+		sort.Strings(uris)
+		batches = append(batches, uris)
+		done <- struct{}{}
+	})
+
+	context := &glsp.Context{}
+	for i := 0; i < 5; i++ {
+		debouncer.Schedule(context, "file:///a.frugal", "file:///b.frugal")
+	}
+	debouncer.Schedule(context, "file:///c.frugal")
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for debounced publish")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(batches) != 1 {
+		t.Fatalf("Expected a single batch, got %d", len(batches))
+	}
+	if len(batches[0]) != 3 {
+		t.Errorf("Expected 3 unique documents, got %v", batches[0])
+	}
+}
+
+func TestDiagnosticsDebouncerStop(t *testing.T) {
+	published := false
+	debouncer := newDiagnosticsDebouncer(time.Hour, func(context *glsp.Context, uris []string) {
+		published = true
+	})
+
+	debouncer.Schedule(nil, "file:///a.frugal")
+	debouncer.Stop()
+	debouncer.Flush()
+
+	// Edits arriving after shutdown are ignored
+	debouncer.Schedule(nil, "file:///b.frugal")
+	debouncer.Flush()
+
+	if published {
+		t.Error("Expected stopped debouncer not to publish")
+	}
+}
@@ -0,0 +1,245 @@
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sort"
+
+	"github.com/tliron/glsp"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+
+	"frugal-ls/internal/document"
+)
+
+// Pull diagnostics were added in LSP 3.17, which the protocol package predates, so the
+// messages and capabilities are declared here.
+const (
+	MethodTextDocumentDiagnostic     = "textDocument/diagnostic"
+	MethodWorkspaceDiagnostic        = "workspace/diagnostic"
+	ServerWorkspaceDiagnosticRefresh = "workspace/diagnostic/refresh"
+
+	DocumentDiagnosticReportKindFull      = "full"
+	DocumentDiagnosticReportKindUnchanged = "unchanged"
+)
+
+// DiagnosticOptions advertises pull diagnostics support
+type DiagnosticOptions struct {
+	Identifier            *string `json:"identifier,omitempty"`
+	InterFileDependencies bool    `json:"interFileDependencies"`
+	WorkspaceDiagnostics  bool    `json:"workspaceDiagnostics"`
+}
+
+// DocumentDiagnosticParams are the parameters of a textDocument/diagnostic request
+type DocumentDiagnosticParams struct {
+	TextDocument     protocol.TextDocumentIdentifier `json:"textDocument"`
+	Identifier       *string                         `json:"identifier,omitempty"`
+	PreviousResultID *string                         `json:"previousResultId,omitempty"`
+}
+
+// DocumentDiagnosticReport is a full or unchanged diagnostics report for a document.
+// Items is nil in unchanged reports.
+type DocumentDiagnosticReport struct {
+	Kind     string                 `json:"kind"`
+	ResultID string                 `json:"resultId"`
+	Items    *[]protocol.Diagnostic `json:"items,omitempty"`
+}
+
+// PreviousResultID is a result ID the client holds for a document
+type PreviousResultID struct {
+	URI   protocol.DocumentUri `json:"uri"`
+	Value string               `json:"value"`
+}
+
+// WorkspaceDiagnosticParams are the parameters of a workspace/diagnostic request
+type WorkspaceDiagnosticParams struct {
+	Identifier        *string            `json:"identifier,omitempty"`
+	PreviousResultIDs []PreviousResultID `json:"previousResultIds"`
+}
+
+// WorkspaceDocumentDiagnosticReport is a document report within a workspace report.
+// Version is nil for documents that are not open in the editor.
+type WorkspaceDocumentDiagnosticReport struct {
+	DocumentDiagnosticReport
+	URI     protocol.DocumentUri `json:"uri"`
+	Version *int32               `json:"version"`
+}
+
+// WorkspaceDiagnosticReport is the result of a workspace/diagnostic request
+type WorkspaceDiagnosticReport struct {
+	Items []WorkspaceDocumentDiagnosticReport `json:"items"`
+}
+
+// serverCapabilities extends the protocol capabilities with pull diagnostics
+type serverCapabilities struct {
+	protocol.ServerCapabilities
+	DiagnosticProvider *DiagnosticOptions `json:"diagnosticProvider,omitempty"`
+}
+
+// initializeResult is an InitializeResult carrying the extended capabilities
+type initializeResult struct {
+	Capabilities serverCapabilities                   `json:"capabilities"`
+	ServerInfo   *protocol.InitializeResultServerInfo `json:"serverInfo,omitempty"`
+}
+
+// diagnosticClientCapabilities holds the 3.17 client capabilities the protocol
+// package drops when decoding InitializeParams
+type diagnosticClientCapabilities struct {
+	Capabilities struct {
+		TextDocument *struct {
+			Diagnostic *json.RawMessage `json:"diagnostic"`
+		} `json:"textDocument"`
+		Workspace *struct {
+			Diagnostics *struct {
+				RefreshSupport bool `json:"refreshSupport"`
+			} `json:"diagnostics"`
+		} `json:"workspace"`
+	} `json:"capabilities"`
+}
+
+// handler routes pull diagnostic requests to the server and everything else to the
+// protocol handler
+type handler struct {
+	*protocol.Handler
+	server *Server
+}
+
+// Handle implements glsp.Handler
+func (h *handler) Handle(context *glsp.Context) (r any, validMethod bool, validParams bool, err error) {
+	switch context.Method {
+	case MethodTextDocumentDiagnostic:
+		if !h.IsInitialized() {
+			return nil, true, true, fmt.Errorf("server not initialized")
+		}
+		var params DocumentDiagnosticParams
+		if err = json.Unmarshal(context.Params, &params); err != nil {
+			return nil, true, false, err
+		}
+		r, err = h.server.textDocumentDiagnostic(context, &params)
+		return r, true, true, err
+
+	case MethodWorkspaceDiagnostic:
+		if !h.IsInitialized() {
+			return nil, true, true, fmt.Errorf("server not initialized")
+		}
+		var params WorkspaceDiagnosticParams
+		if err = json.Unmarshal(context.Params, &params); err != nil {
+			return nil, true, false, err
+		}
+		r, err = h.server.workspaceDiagnostic(context, &params)
+		return r, true, true, err
+	}
+
+	return h.Handler.Handle(context)
+}
+
+// readDiagnosticClientCapabilities enables pull diagnostics if the client supports them
+func (s *Server) readDiagnosticClientCapabilities(raw json.RawMessage) {
+	var params diagnosticClientCapabilities
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return
+	}
+
+	caps := params.Capabilities
+	s.pullDiagnostics = caps.TextDocument != nil && caps.TextDocument.Diagnostic != nil
+	s.diagnosticRefreshSupport = caps.Workspace != nil && caps.Workspace.Diagnostics != nil &&
+		caps.Workspace.Diagnostics.RefreshSupport
+}
+
+// textDocumentDiagnostic handles textDocument/diagnostic requests
+func (s *Server) textDocumentDiagnostic(context *glsp.Context, params *DocumentDiagnosticParams) (*DocumentDiagnosticReport, error) {
+	doc, exists := s.docManager.GetDocument(params.TextDocument.URI)
+	if !exists {
+		return nil, fmt.Errorf("document not found: %s", params.TextDocument.URI)
+	}
+
+	previous := ""
+	if params.PreviousResultID != nil {
+		previous = *params.PreviousResultID
+	}
+
+	report := diagnosticReport(doc, previous)
+	return &report, nil
+}
+
+// workspaceDiagnostic handles workspace/diagnostic requests, reporting on every
+// Frugal file known to the server whether or not it is open
+func (s *Server) workspaceDiagnostic(context *glsp.Context, params *WorkspaceDiagnosticParams) (*WorkspaceDiagnosticReport, error) {
+	previous := make(map[string]string, len(params.PreviousResultIDs))
+	for _, id := range params.PreviousResultIDs {
+		previous[id.URI] = id.Value
+	}
+
+	documents := s.docManager.GetAllDocuments()
+	uris := make([]string, 0, len(documents))
+	for uri, doc := range documents {
+		if doc.IsValidFrugalFile() {
+			uris = append(uris, uri)
+		}
+	}
+	sort.Strings(uris)
+
+	result := &WorkspaceDiagnosticReport{
+		Items: make([]WorkspaceDocumentDiagnosticReport, 0, len(uris)),
+	}
+	for _, uri := range uris {
+		doc := documents[uri]
+		item := WorkspaceDocumentDiagnosticReport{
+			DocumentDiagnosticReport: diagnosticReport(doc, previous[uri]),
+			URI:                      uri,
+		}
+		if s.docManager.IsOpen(uri) {
+			version := doc.Version
+			item.Version = &version
+		}
+		result.Items = append(result.Items, item)
+	}
+
+	return result, nil
+}
+
+// requestDiagnosticRefresh asks the client to pull diagnostics again, for changes that
+// affect documents other than the one being edited
+func (s *Server) requestDiagnosticRefresh(context *glsp.Context) {
+	if !s.diagnosticRefreshSupport || context == nil {
+		return
+	}
+	// The refresh is a request to the client, so it must not block the caller
+	go context.Call(ServerWorkspaceDiagnosticRefresh, nil, nil)
+}
+
+// diagnosticReport computes a document's diagnostics and returns an unchanged report
+// if they match the client's previous result
+func diagnosticReport(doc *document.Document, previousResultID string) DocumentDiagnosticReport {
+	diagnostics := doc.GetDiagnostics()
+	if diagnostics == nil {
+		diagnostics = []protocol.Diagnostic{}
+	}
+	resultID := diagnosticsResultID(diagnostics)
+
+	if previousResultID == resultID {
+		return DocumentDiagnosticReport{
+			Kind:     DocumentDiagnosticReportKindUnchanged,
+			ResultID: resultID,
+		}
+	}
+
+	return DocumentDiagnosticReport{
+		Kind:     DocumentDiagnosticReportKindFull,
+		ResultID: resultID,
+		Items:    &diagnostics,
+	}
+}
+
+// diagnosticsResultID derives a result ID from the diagnostics themselves, so that
+// changes in other files that affect a document also change its result ID
+func diagnosticsResultID(diagnostics []protocol.Diagnostic) string {
+	data, err := json.Marshal(diagnostics)
+	if err != nil {
+		return ""
+	}
+
+	hash := fnv.New64a()
+	hash.Write(data)
+	return fmt.Sprintf("%016x", hash.Sum64())
+}
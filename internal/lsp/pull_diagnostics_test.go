@@ -0,0 +1,137 @@
+package lsp
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tliron/glsp"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+
+	"frugal-ls/internal/document"
+	"frugal-ls/internal/parser"
+)
+
+// handle sends a request through the handler and returns the JSON encoded result
+func handle(t *testing.T, h *handler, context *glsp.Context, method string, params any) string {
+	t.Helper()
+
+	raw, err := json.Marshal(params)
+	if err != nil {
+		t.Fatalf("Failed to encode %s params: %v", method, err)
+	}
+	context.Method = method
+	context.Params = raw
+
+	result, validMethod, validParams, err := h.Handle(context)
+	if !validMethod || !validParams || err != nil {
+		t.Fatalf("%s failed: method=%v params=%v err=%v", method, validMethod, validParams, err)
+	}
+
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("Failed to encode %s result: %v", method, err)
+	}
+	return string(encoded)
+}
+
+func TestPullDiagnostics(t *testing.T) {
+	server, err := NewServer()
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	dir := t.TempDir()
+	mainURI := "file://" + filepath.Join(dir, "main.frugal")
+	diskPath := filepath.Join(dir, "disk.frugal")
+	if err := os.WriteFile(diskPath, []byte("struct Disk {\n    1: Missing m\n}"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	pushed := 0
+	context := &glsp.Context{
+		Notify: func(method string, params any) {
+			if method == protocol.ServerTextDocumentPublishDiagnostics {
+				pushed++
+			}
+		},
+	}
+	h := &handler{
+		Handler: &protocol.Handler{
+			Initialize:          server.initialize,
+			TextDocumentDidOpen: server.textDocumentDidOpen,
+		},
+		server: server,
+	}
+
+	result := handle(t, h, context, protocol.MethodInitialize, map[string]any{
+		"clientInfo": map[string]any{"name": "test"},
+		"capabilities": map[string]any{
+			"textDocument": map[string]any{"diagnostic": map[string]any{}},
+		},
+	})
+	if !strings.Contains(result, `"diagnosticProvider":{"interFileDependencies":true,"workspaceDiagnostics":true}`) {
+		t.Errorf("Expected diagnostic provider capability, got %s", result)
+	}
+	if !server.pullDiagnostics {
+		t.Fatal("Expected pull diagnostics to be enabled")
+	}
+
+	handle(t, h, context, protocol.MethodTextDocumentDidOpen, protocol.DidOpenTextDocumentParams{
+		TextDocument: protocol.TextDocumentItem{URI: mainURI, LanguageID: "frugal", Version: 3, Text: "struct Main {\n    1: Unknown u\n}"},
+	})
+	if pushed != 0 {
+		t.Errorf("Expected no pushed diagnostics in pull mode, got %d", pushed)
+	}
+
+	var report DocumentDiagnosticReport
+	raw := handle(t, h, context, MethodTextDocumentDiagnostic, DocumentDiagnosticParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: mainURI},
+	})
+	if err := json.Unmarshal([]byte(raw), &report); err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+	if report.Kind != DocumentDiagnosticReportKindFull || report.Items == nil || !hasDiagnostic(*report.Items, "Unknown type") {
+		t.Fatalf("Expected full report with unknown type, got %s", raw)
+	}
+
+	raw = handle(t, h, context, MethodTextDocumentDiagnostic, DocumentDiagnosticParams{
+		TextDocument:     protocol.TextDocumentIdentifier{URI: mainURI},
+		PreviousResultID: &report.ResultID,
+	})
+	if raw != `{"kind":"unchanged","resultId":"`+report.ResultID+`"}` {
+		t.Errorf("Expected unchanged report, got %s", raw)
+	}
+
+	p, err := parser.NewParser()
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer p.Close()
+	doc, err := document.LoadFile(p, "file://"+diskPath, diskPath)
+	if err != nil {
+		t.Fatalf("Failed to load file: %v", err)
+	}
+	server.trackWorkspaceDocument(context, doc)
+
+	var workspaceReport WorkspaceDiagnosticReport
+	raw = handle(t, h, context, MethodWorkspaceDiagnostic, WorkspaceDiagnosticParams{
+		PreviousResultIDs: []PreviousResultID{{URI: mainURI, Value: report.ResultID}},
+	})
+	if err := json.Unmarshal([]byte(raw), &workspaceReport); err != nil {
+		t.Fatalf("Failed to decode workspace report: %v", err)
+	}
+	if len(workspaceReport.Items) != 2 {
+		t.Fatalf("Expected reports for 2 files, got %s", raw)
+	}
+
+	disk, main := workspaceReport.Items[0], workspaceReport.Items[1]
+	if disk.Kind != DocumentDiagnosticReportKindFull || disk.Version != nil || !hasDiagnostic(*disk.Items, "Unknown type 'Missing'") {
+		t.Errorf("Expected full report for unopened file, got %+v", disk)
+	}
+	if main.Kind != DocumentDiagnosticReportKindUnchanged || main.Version == nil || *main.Version != 3 {
+		t.Errorf("Expected unchanged report for open file at version 3, got %+v", main)
+	}
+}
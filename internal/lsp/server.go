@@ -4,17 +4,26 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/tliron/glsp"
 	protocol "github.com/tliron/glsp/protocol_3_16"
 	"github.com/tliron/glsp/server"
 
+	"frugal-ls/internal/config"
 	"frugal-ls/internal/document"
 	"frugal-ls/internal/features"
 	"frugal-ls/internal/workspace"
 )
 
 const (
+	// dependentDiagnosticsDelay is how long edits must pause before dependents are re-checked
+	dependentDiagnosticsDelay = 300 * time.Millisecond
+
 	// LanguageServerName is the identifier for the Frugal language server
 	LanguageServerName = "frugal-ls"
 	// LanguageServerVersion is the current version of the Frugal language server
@@ -29,10 +38,46 @@ type Server struct {
 
 	// Workspace management
 	includeResolver *workspace.IncludeResolver
+	typeResolver    *workspace.TypeResolver
 	symbolIndex     *workspace.SymbolIndex
 	workspaceRoots  []string
 
+	// annotationSchema holds the known annotation keys shared by the providers
+	annotationSchema *features.AnnotationSchema
+
+	// config is the project configuration found from the first workspace root
+	config *config.Config
+	// settings are the client's settings, which take precedence over config
+	settings Settings
+	// compatBase is the baseline breaking changes are reported against, if any. It is
+	// guarded by settingsMutex.
+	compatBase string
+	// settingsMutex serializes settings updates, which may arrive from goroutines
+	settingsMutex sync.Mutex
+
+	// configurationSupport is set when the client answers workspace/configuration requests
+	configurationSupport bool
+	// registerConfiguration is set when didChangeConfiguration must be registered dynamically
+	registerConfiguration bool
+
+	// dependentDiagnostics batches diagnostics for files that include an edited file
+	dependentDiagnostics *diagnosticsDebouncer
+
+	// watchFilesDynamically is set when the client supports registering file watchers
+	watchFilesDynamically bool
+
+	// publishWorkspaceDiagnostics publishes diagnostics for files loaded from disk
+	// that are not open in the editor. Settings updates store it while handlers read it.
+	publishWorkspaceDiagnostics atomic.Bool
+
+	// pullDiagnostics is set when the client requests diagnostics itself, in which
+	// case they are no longer pushed
+	pullDiagnostics bool
+	// diagnosticRefreshSupport is set when the client accepts workspace/diagnostic/refresh
+	diagnosticRefreshSupport bool
+
 	// Language feature providers
+	diagnosticsProvider       *features.DiagnosticsProvider
 	completionProvider        *features.CompletionProvider
 	hoverProvider             *features.HoverProvider
 	documentSymbolProvider    *features.DocumentSymbolProvider
@@ -58,22 +103,30 @@ func NewServer() (*Server, error) {
 
 	// Initialize workspace roots (will be updated from InitializeParams)
 	workspaceRoots := []string{"."}
-	includeResolver := workspace.NewIncludeResolver(workspaceRoots)
 	symbolIndex := workspace.NewSymbolIndex()
 
 	// Initialize diagnostics provider
 	diagnosticsProvider := features.NewDiagnosticsProvider()
 	document.SetDiagnosticsProvider(diagnosticsProvider)
 
+	annotationSchema := features.NewAnnotationSchema(nil)
+	diagnosticsProvider.SetAnnotationSchema(annotationSchema)
+	completionProvider := features.NewCompletionProvider()
+	completionProvider.SetAnnotationSchema(annotationSchema)
+	hoverProvider := features.NewHoverProvider()
+	hoverProvider.SetAnnotationSchema(annotationSchema)
+
 	// Create the server with language feature providers
 	lspServer := &Server{
 		docManager:                docManager,
 		logger:                    logger,
-		includeResolver:           includeResolver,
+		diagnosticsProvider:       diagnosticsProvider,
 		symbolIndex:               symbolIndex,
 		workspaceRoots:            workspaceRoots,
-		completionProvider:        features.NewCompletionProvider(),
-		hoverProvider:             features.NewHoverProvider(),
+		config:                    config.Default(),
+		annotationSchema:          annotationSchema,
+		completionProvider:        completionProvider,
+		hoverProvider:             hoverProvider,
 		documentSymbolProvider:    features.NewDocumentSymbolProvider(),
 		definitionProvider:        features.NewDefinitionProvider(),
 		referencesProvider:        features.NewReferencesProvider(),
@@ -83,9 +136,13 @@ func NewServer() (*Server, error) {
 		semanticTokensProvider:    features.NewSemanticTokensProvider(),
 		renameProvider:            features.NewRenameProvider(),
 	}
+	lspServer.setIncludeResolver(workspace.NewIncludeResolver(workspaceRoots))
+	lspServer.dependentDiagnostics = newDiagnosticsDebouncer(dependentDiagnosticsDelay, func(context *glsp.Context, uris []string) {
+		lspServer.refreshDiagnostics(context, "", uris)
+	})
 
 	// Set up GLSP server
-	handler := protocol.Handler{
+	protocolHandler := &protocol.Handler{
 		Initialize:                      lspServer.initialize,
 		Initialized:                     lspServer.initialized,
 		Shutdown:                        lspServer.shutdown,
@@ -107,9 +164,11 @@ func NewServer() (*Server, error) {
 		TextDocumentPrepareRename:       lspServer.textDocumentPrepareRename,
 		TextDocumentRename:              lspServer.textDocumentRename,
 		WorkspaceSymbol:                 lspServer.workspaceSymbol,
+		WorkspaceDidChangeWatchedFiles:  lspServer.workspaceDidChangeWatchedFiles,
+		WorkspaceDidChangeConfiguration: lspServer.workspaceDidChangeConfiguration,
 	}
 
-	serverInstance := server.NewServer(&handler, LanguageServerName, false)
+	serverInstance := server.NewServer(&handler{Handler: protocolHandler, server: lspServer}, LanguageServerName, false)
 	lspServer.server = serverInstance
 
 	return lspServer, nil
@@ -139,13 +198,50 @@ func (s *Server) initialize(context *glsp.Context, params *protocol.InitializePa
 		for _, folder := range params.WorkspaceFolders {
 			s.workspaceRoots = append(s.workspaceRoots, folder.URI)
 		}
-		s.includeResolver = workspace.NewIncludeResolver(s.workspaceRoots)
+		s.setIncludeResolver(workspace.NewIncludeResolver(s.workspaceRoots))
 	} else if params.RootURI != nil {
 		s.workspaceRoots = []string{*params.RootURI}
-		s.includeResolver = workspace.NewIncludeResolver(s.workspaceRoots)
+		s.setIncludeResolver(workspace.NewIncludeResolver(s.workspaceRoots))
+	}
+
+	s.loadConfig()
+
+	if ws := params.Capabilities.Workspace; ws != nil && ws.DidChangeWatchedFiles != nil {
+		dynamic := ws.DidChangeWatchedFiles.DynamicRegistration
+		s.watchFilesDynamically = dynamic != nil && *dynamic
 	}
 
-	capabilities := s.getServerCapabilities()
+	if ws := params.Capabilities.Workspace; ws != nil {
+		s.configurationSupport = ws.Configuration != nil && *ws.Configuration
+		if ws.DidChangeConfiguration != nil {
+			dynamic := ws.DidChangeConfiguration.DynamicRegistration
+			s.registerConfiguration = dynamic != nil && *dynamic
+		}
+	}
+
+	s.settingsMutex.Lock()
+	if params.InitializationOptions != nil {
+		settings, err := parseSettings(params.InitializationOptions)
+		if err != nil {
+			s.logger.Printf("Ignoring initialization options: %v", err)
+		} else {
+			s.settings = settings
+		}
+	}
+	s.applySettings()
+	s.settingsMutex.Unlock()
+
+	if context != nil {
+		s.readDiagnosticClientCapabilities(context.Params)
+	}
+
+	capabilities := serverCapabilities{
+		ServerCapabilities: s.getServerCapabilities(),
+		DiagnosticProvider: &DiagnosticOptions{
+			InterFileDependencies: true,
+			WorkspaceDiagnostics:  true,
+		},
+	}
 
 	version := LanguageServerVersion
 	serverInfo := protocol.InitializeResultServerInfo{
@@ -153,21 +249,290 @@ func (s *Server) initialize(context *glsp.Context, params *protocol.InitializePa
 		Version: &version,
 	}
 
-	return protocol.InitializeResult{
+	return initializeResult{
 		Capabilities: capabilities,
 		ServerInfo:   &serverInfo,
 	}, nil
 }
 
+// loadConfig discovers the project configuration from the first workspace root. An
+// invalid file is logged and the defaults are used instead.
+func (s *Server) loadConfig() {
+	cfg := config.Default()
+	for _, root := range s.workspaceRoots {
+		if !strings.HasPrefix(root, "file://") {
+			continue
+		}
+		loaded, err := config.Discover(strings.TrimPrefix(root, "file://"))
+		if err != nil {
+			s.logger.Printf("Error loading configuration: %v", err)
+		} else {
+			cfg = loaded
+		}
+		break
+	}
+
+	if cfg.Path != "" {
+		s.logger.Printf("Using configuration %s", cfg.Path)
+	}
+	s.config = cfg
+}
+
+// setIncludeResolver replaces the include resolver and rebinds the features that use it
+func (s *Server) setIncludeResolver(resolver *workspace.IncludeResolver) {
+	resolver.SetDocumentSource(s.docManager)
+	s.includeResolver = resolver
+	s.typeResolver = workspace.NewTypeResolver(resolver, s.docManager)
+	s.diagnosticsProvider.SetTypeResolver(s.typeResolver)
+	s.hoverProvider.SetTypeResolver(s.typeResolver)
+	s.documentSymbolProvider.SetTypeResolver(s.typeResolver)
+	s.codeActionProvider.SetTypeResolver(s.typeResolver)
+	s.semanticTokensProvider.SetTypeResolver(s.typeResolver)
+	s.diagnosticsProvider.SetIncludeResolver(resolver)
+	s.codeActionProvider.SetIncludeResolver(resolver)
+}
+
 // initialized handles the initialized notification
 func (s *Server) initialized(context *glsp.Context, params *protocol.InitializedParams) error {
 	s.logger.Println("Client initialized, server ready")
+
+	go s.indexWorkspace(context)
+
+	// Requests to the client must not block this handler
+	if s.watchFilesDynamically {
+		go s.registerFileWatchers(context)
+	}
+	if s.registerConfiguration {
+		go s.registerConfigurationChanges(context)
+	}
+	if s.configurationSupport {
+		go s.fetchSettings(context)
+	}
+
+	return nil
+}
+
+// updateIncludes refreshes a document's include dependencies and re-publishes diagnostics
+// for other documents whose include cycles were created or broken by the change
+func (s *Server) updateIncludes(context *glsp.Context, doc *document.Document) {
+	before := s.includeResolver.CycleMembers(doc.URI)
+
+	if err := s.includeResolver.UpdateDocument(doc); err != nil {
+		s.logger.Printf("Error updating document dependencies: %v", err)
+	}
+
+	after := s.includeResolver.CycleMembers(doc.URI)
+	s.refreshDiagnostics(context, doc.URI, append(before, after...))
+}
+
+// refreshDiagnostics re-publishes diagnostics for the given documents, skipping exclude
+// and unopened files unless workspace diagnostics are enabled
+func (s *Server) refreshDiagnostics(context *glsp.Context, exclude string, uris []string) {
+	seen := map[string]bool{exclude: true}
+	for _, uri := range uris {
+		if seen[uri] {
+			continue
+		}
+		seen[uri] = true
+
+		// Clients pulling diagnostics are asked to pull again instead
+		if s.pullDiagnostics {
+			s.requestDiagnosticRefresh(context)
+			return
+		}
+
+		if !s.docManager.IsOpen(uri) && !s.publishWorkspaceDiagnostics.Load() {
+			continue
+		}
+		if doc, exists := s.docManager.GetDocument(uri); exists {
+			s.publishDiagnostics(context, doc)
+		}
+	}
+}
+
+// refreshIncludesOf recomputes dependencies of documents with an include of the given file name
+func (s *Server) refreshIncludesOf(context *glsp.Context, name string) []string {
+	var refreshed []string
+	for uri, doc := range s.docManager.GetAllDocuments() {
+		for _, include := range doc.GetModel().Includes {
+			if filepath.Base(include.Path) != name {
+				continue
+			}
+			s.updateIncludes(context, doc)
+			refreshed = append(refreshed, uri)
+			break
+		}
+	}
+	return refreshed
+}
+
+// registerFileWatchers asks the client to report changes to .frugal files on disk
+func (s *Server) registerFileWatchers(context *glsp.Context) {
+	context.Call(protocol.ServerClientRegisterCapability, protocol.RegistrationParams{
+		Registrations: []protocol.Registration{{
+			ID:     "frugal-ls-watched-files",
+			Method: string(protocol.MethodWorkspaceDidChangeWatchedFiles),
+			RegisterOptions: protocol.DidChangeWatchedFilesRegistrationOptions{
+				Watchers: []protocol.FileSystemWatcher{{GlobPattern: "**/*.frugal"}},
+			},
+		}},
+	}, nil)
+
+	s.logger.Println("Registered file watchers for **/*.frugal")
+}
+
+// workspaceDidChangeWatchedFiles handles workspace/didChangeWatchedFiles notifications
+func (s *Server) workspaceDidChangeWatchedFiles(context *glsp.Context, params *protocol.DidChangeWatchedFilesParams) error {
+	affected := make(map[string]bool)
+
+	for _, change := range params.Changes {
+		uri := change.URI
+		if !strings.HasSuffix(uri, ".frugal") {
+			continue
+		}
+
+		// The editor owns the contents of open documents
+		if s.docManager.IsOpen(uri) {
+			continue
+		}
+
+		s.logger.Printf("Watched file event %d: %s", change.Type, uri)
+
+		// Dependents are collected before the include graph changes
+		for _, dependent := range s.includeResolver.GetTransitiveDependents(uri) {
+			affected[dependent] = true
+		}
+
+		// Creating or deleting a file can change how includes resolve
+		if change.Type != protocol.FileChangeTypeChanged {
+			s.includeResolver.InvalidateCache()
+		}
+
+		switch change.Type {
+		case protocol.FileChangeTypeCreated, protocol.FileChangeTypeChanged:
+			workspace.LoadFiles([]string{strings.TrimPrefix(uri, "file://")}, 1, func(doc *document.Document) {
+				s.trackWorkspaceDocument(context, doc)
+			})
+
+		case protocol.FileChangeTypeDeleted:
+			s.includeResolver.RemoveDocument(uri)
+			s.symbolIndex.RemoveDocument(uri)
+			s.docManager.RemoveDocument(uri)
+
+			if s.publishWorkspaceDiagnostics.Load() {
+				context.Notify(protocol.ServerTextDocumentPublishDiagnostics, protocol.PublishDiagnosticsParams{
+					URI:         uri,
+					Diagnostics: []protocol.Diagnostic{},
+				})
+			}
+		}
+
+		for _, dependent := range s.includeResolver.GetTransitiveDependents(uri) {
+			affected[dependent] = true
+		}
+
+		// Files whose includes could not resolve before may now resolve to the new file
+		if change.Type == protocol.FileChangeTypeCreated {
+			for _, dependent := range s.refreshIncludesOf(context, filepath.Base(uri)) {
+				affected[dependent] = true
+			}
+		}
+	}
+
+	// Files that include a changed file may gain or lose errors
+	var uris []string
+	for uri := range affected {
+		uris = append(uris, uri)
+	}
+	s.refreshDiagnostics(context, "", uris)
+
 	return nil
 }
 
+// indexWorkspace loads every .frugal file under the client's workspace roots so that
+// cross-file features work for files that have not been opened
+func (s *Server) indexWorkspace(context *glsp.Context) {
+	// Only roots supplied by the client are crawled, never the default working directory
+	var roots []string
+	for _, root := range s.workspaceRoots {
+		if strings.HasPrefix(root, "file://") {
+			roots = append(roots, root)
+		}
+	}
+	if len(roots) == 0 {
+		return
+	}
+
+	start := time.Now()
+	s.settingsMutex.Lock()
+	s.loadBaseline()
+	s.settingsMutex.Unlock()
+	paths := workspace.FindFrugalFiles(roots, s.config.Ignore...)
+
+	workspace.LoadFiles(paths, 0, func(doc *document.Document) {
+		s.trackWorkspaceDocument(context, doc)
+	})
+
+	s.logger.Printf("Indexed %d workspace files in %s", len(paths), time.Since(start))
+
+	// Open documents may reference types from files that were just loaded
+	if s.pullDiagnostics {
+		s.requestDiagnosticRefresh(context)
+		return
+	}
+	for uri, doc := range s.docManager.GetAllDocuments() {
+		if s.docManager.IsOpen(uri) {
+			s.publishDiagnostics(context, doc)
+		}
+	}
+}
+
+// trackWorkspaceDocument indexes a document loaded from disk unless the editor has it open
+func (s *Server) trackWorkspaceDocument(context *glsp.Context, doc *document.Document) bool {
+	if !s.docManager.AddDocument(doc) {
+		return false
+	}
+
+	s.updateIncludes(context, doc)
+	s.symbolIndex.UpdateDocument(doc)
+
+	if s.publishWorkspaceDiagnostics.Load() {
+		s.publishDiagnostics(context, doc)
+	}
+	return true
+}
+
+// workspacePath resolves a configured path relative to the first workspace root
+func (s *Server) workspacePath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	for _, root := range s.workspaceRoots {
+		if strings.HasPrefix(root, "file://") {
+			return filepath.Join(strings.TrimPrefix(root, "file://"), path)
+		}
+	}
+	return path
+}
+
+// isInWorkspace reports whether a file path lies under one of the workspace roots
+func (s *Server) isInWorkspace(path string) bool {
+	for _, root := range s.workspaceRoots {
+		if !strings.HasPrefix(root, "file://") {
+			continue
+		}
+		rel, err := filepath.Rel(strings.TrimPrefix(root, "file://"), path)
+		if err == nil && !strings.HasPrefix(rel, "..") {
+			return true
+		}
+	}
+	return false
+}
+
 // shutdown handles the shutdown request
 func (s *Server) shutdown(context *glsp.Context) error {
 	s.logger.Println("Shutdown request received")
+	s.dependentDiagnostics.Stop()
 	return nil
 }
 
@@ -184,14 +549,15 @@ func (s *Server) textDocumentDidOpen(context *glsp.Context, params *protocol.Did
 	// Send diagnostics if this is a Frugal file
 	if doc.IsValidFrugalFile() {
 		// Update include dependencies
-		if err := s.includeResolver.UpdateDocument(doc); err != nil {
-			s.logger.Printf("Error updating document dependencies: %v", err)
-		}
+		s.updateIncludes(context, doc)
 
 		// Update symbol index
 		s.symbolIndex.UpdateDocument(doc)
 
 		s.publishDiagnostics(context, doc)
+
+		// Unsaved documents can satisfy includes that did not resolve before
+		s.refreshDiagnostics(context, doc.URI, s.refreshIncludesOf(context, filepath.Base(doc.Path)))
 	}
 
 	return nil
@@ -210,15 +576,16 @@ func (s *Server) textDocumentDidChange(context *glsp.Context, params *protocol.D
 	// Send updated diagnostics if this is a Frugal file
 	if doc.IsValidFrugalFile() {
 		// Update include dependencies
-		if err := s.includeResolver.UpdateDocument(doc); err != nil {
-			s.logger.Printf("Error updating document dependencies: %v", err)
-		}
+		s.updateIncludes(context, doc)
 
 		// Update symbol index
 		s.symbolIndex.UpdateDocument(doc)
 
 		s.publishDiagnostics(context, doc)
 
+		// Files that include this one are re-checked once typing pauses
+		s.dependentDiagnostics.Schedule(context, s.includeResolver.GetTransitiveDependents(doc.URI)...)
+
 		// Request semantic token refresh to update highlighting
 		s.refreshSemanticTokens(context)
 	}
@@ -230,7 +597,13 @@ func (s *Server) textDocumentDidChange(context *glsp.Context, params *protocol.D
 func (s *Server) textDocumentDidClose(context *glsp.Context, params *protocol.DidCloseTextDocumentParams) error {
 	s.logger.Printf("Document closed: %s", params.TextDocument.URI)
 
+	var path string
+	if doc, exists := s.docManager.GetDocument(params.TextDocument.URI); exists {
+		path = doc.Path
+	}
+
 	// Remove from include resolver and symbol index
+	cycleMembers := s.includeResolver.CycleMembers(params.TextDocument.URI)
 	s.includeResolver.RemoveDocument(params.TextDocument.URI)
 	s.symbolIndex.RemoveDocument(params.TextDocument.URI)
 
@@ -239,6 +612,20 @@ func (s *Server) textDocumentDidClose(context *glsp.Context, params *protocol.Di
 		s.logger.Printf("Error closing document: %v", err)
 	}
 
+	// Workspace files stay indexed using their contents on disk
+	if path != "" && s.isInWorkspace(path) {
+		tracked := false
+		workspace.LoadFiles([]string{path}, 1, func(doc *document.Document) {
+			tracked = s.trackWorkspaceDocument(context, doc)
+		})
+		if tracked && s.publishWorkspaceDiagnostics.Load() {
+			return err
+		}
+	} else {
+		// Closing a file outside the workspace breaks any cycle through it
+		s.refreshDiagnostics(context, params.TextDocument.URI, cycleMembers)
+	}
+
 	// Clear diagnostics for closed document
 	context.Notify(protocol.ServerTextDocumentPublishDiagnostics, protocol.PublishDiagnosticsParams{
 		URI:         params.TextDocument.URI,
@@ -260,6 +647,10 @@ func (s *Server) textDocumentDidSave(context *glsp.Context, params *protocol.Did
 
 // publishDiagnostics sends diagnostics to the client
 func (s *Server) publishDiagnostics(context *glsp.Context, doc *document.Document) {
+	if s.pullDiagnostics {
+		return
+	}
+
 	diagnostics := doc.GetDiagnostics()
 
 	s.logger.Printf("Publishing %d diagnostics for %s", len(diagnostics), doc.URI)
@@ -337,6 +728,16 @@ func (s *Server) textDocumentHover(context *glsp.Context, params *protocol.Hover
 		return nil, nil
 	}
 
+	// Symbols declared in other files are described from their declaring document
+	if decl, _ := s.typeResolver.Resolve(doc.URI, params.Position); decl != nil && decl.URI != doc.URI {
+		if declDoc, ok := s.docManager.GetDocument(decl.URI); ok {
+			if hover := s.hoverProvider.ProvideDeclarationHover(declDoc, decl.Name); hover != nil {
+				s.logger.Printf("Providing hover for %s from %s", params.TextDocument.URI, decl.URI)
+				return hover, nil
+			}
+		}
+	}
+
 	hover, err := s.hoverProvider.ProvideHover(doc, params.Position)
 	if err != nil {
 		s.logger.Printf("Error providing hover: %v", err)
@@ -373,6 +774,12 @@ func (s *Server) textDocumentDefinition(context *glsp.Context, params *protocol.
 		return nil, nil
 	}
 
+	// Prefer declarations bound through the include graph
+	if decl, _ := s.typeResolver.Resolve(doc.URI, params.Position); decl != nil {
+		s.logger.Printf("Providing resolved definition for %s", params.TextDocument.URI)
+		return []protocol.Location{decl.Location()}, nil
+	}
+
 	// Get all documents for cross-file navigation
 	allDocuments := s.getAllDocuments()
 
@@ -393,6 +800,16 @@ func (s *Server) textDocumentReferences(context *glsp.Context, params *protocol.
 		return nil, nil
 	}
 
+	// Prefer references bound through the include graph
+	if decl, _ := s.typeResolver.Resolve(doc.URI, params.Position); decl != nil {
+		var locations []protocol.Location
+		for _, ref := range s.typeResolver.References(decl, params.Context.IncludeDeclaration) {
+			locations = append(locations, ref.Location())
+		}
+		s.logger.Printf("Providing %d resolved reference locations for %s", len(locations), params.TextDocument.URI)
+		return locations, nil
+	}
+
 	// Get all documents for cross-file reference search
 	allDocuments := s.getAllDocuments()
 
@@ -558,7 +975,14 @@ func (s *Server) textDocumentRename(context *glsp.Context, params *protocol.Rena
 	// Get all documents for cross-file rename
 	allDocuments := s.getAllDocuments()
 
-	workspaceEdit, err := s.renameProvider.Rename(doc, params.Position, params.NewName, allDocuments)
+	var workspaceEdit *protocol.WorkspaceEdit
+	var err error
+	if decl, _ := s.typeResolver.Resolve(doc.URI, params.Position); decl != nil {
+		references := s.typeResolver.References(decl, true)
+		workspaceEdit, err = s.renameProvider.RenameReferences(decl, references, params.NewName, allDocuments)
+	} else {
+		workspaceEdit, err = s.renameProvider.Rename(doc, params.Position, params.NewName, allDocuments)
+	}
 	if err != nil {
 		s.logger.Printf("Error performing rename: %v", err)
 		return nil, err
@@ -1,10 +1,15 @@
 package lsp
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"frugal-ls/internal/document"
+	"frugal-ls/internal/workspace"
 
+	"github.com/tliron/glsp"
 	protocol "github.com/tliron/glsp/protocol_3_16"
 )
 
@@ -258,3 +263,307 @@ service UserService {
 		t.Error("Document should have symbols")
 	}
 }
+
+func TestIndexWorkspace(t *testing.T) {
+	server, err := NewServer()
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "common.frugal"), []byte("struct SharedThing {\n    1: i32 x\n}"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	// The default working directory root is never crawled
+	server.indexWorkspace(nil)
+	if len(server.docManager.GetAllDocuments()) != 0 {
+		t.Fatal("Expected no documents to be loaded without client roots")
+	}
+
+	server.workspaceRoots = []string{"file://" + dir}
+	server.indexWorkspace(nil)
+
+	uri := "file://" + filepath.Join(dir, "common.frugal")
+	if _, exists := server.docManager.GetDocument(uri); !exists {
+		t.Fatal("Expected unopened workspace file to be loaded")
+	}
+	if server.docManager.IsOpen(uri) {
+		t.Error("Workspace file should not be marked open")
+	}
+
+	symbols := server.symbolIndex.Search("SharedThing", 10)
+	if len(symbols) == 0 {
+		t.Error("Expected workspace symbol search to find unopened file symbols")
+	}
+}
+
+func TestInitializeLoadsConfig(t *testing.T) {
+	server, err := NewServer()
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		".frugal-ls.yaml":    "format:\n  indentSize: 2\nignore: [generated]\nlint:\n  rules:\n    naming-convention: off\n",
+		"generated/x.frugal": "struct X {}",
+		"api/common.frugal":  "struct Common {}",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	rootURI := "file://" + dir
+	if _, err := server.initialize(nil, &protocol.InitializeParams{
+		ClientInfo: &struct {
+			Name    string  `json:"name"`
+			Version *string `json:"version,omitempty"`
+		}{Name: "test"},
+		RootURI: &rootURI,
+	}); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if server.config.Path != filepath.Join(dir, ".frugal-ls.yaml") {
+		t.Fatalf("Expected workspace config to be loaded, got %q", server.config.Path)
+	}
+
+	server.indexWorkspace(nil)
+	if _, exists := server.docManager.GetDocument("file://" + filepath.Join(dir, "generated/x.frugal")); exists {
+		t.Error("Expected ignored directory not to be indexed")
+	}
+
+	context := &glsp.Context{Notify: func(method string, params any) {}}
+	uri := "file://" + filepath.Join(dir, "main.frugal")
+	if err := server.textDocumentDidOpen(context, &protocol.DidOpenTextDocumentParams{
+		TextDocument: protocol.TextDocumentItem{URI: uri, LanguageID: "frugal", Version: 1, Text: "struct bad_name {\n1: i32 x\n}"},
+	}); err != nil {
+		t.Fatalf("Failed to open document: %v", err)
+	}
+	doc, _ := server.docManager.GetDocument(uri)
+	if diagnostics := doc.GetDiagnostics(); len(diagnostics) != 0 {
+		t.Errorf("Expected disabled rule not to be reported, got %+v", diagnostics)
+	}
+
+	edits, err := server.textDocumentFormatting(nil, &protocol.DocumentFormattingParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+		Options:      protocol.FormattingOptions{"tabSize": float64(4), "insertSpaces": true},
+	})
+	if err != nil || len(edits) != 1 || !strings.Contains(edits[0].NewText, "\n  1: i32 x") {
+		t.Errorf("Expected configured indentation to override the client, got %+v, %v", edits, err)
+	}
+}
+
+func TestWorkspaceDidChangeWatchedFiles(t *testing.T) {
+	server, err := NewServer()
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	dir := t.TempDir()
+	commonPath := filepath.Join(dir, "common.frugal")
+	if err := os.WriteFile(commonPath, []byte("struct User {\n    1: i64 id\n}"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	commonURI := "file://" + commonPath
+	mainURI := "file://" + filepath.Join(dir, "main.frugal")
+
+	published := make(map[string][]protocol.Diagnostic)
+	context := &glsp.Context{
+		Notify: func(method string, params any) {
+			if p, ok := params.(protocol.PublishDiagnosticsParams); ok {
+				published[p.URI] = p.Diagnostics
+			}
+		},
+	}
+
+	server.workspaceRoots = []string{"file://" + dir}
+	server.setIncludeResolver(workspace.NewIncludeResolver(server.workspaceRoots))
+	server.indexWorkspace(context)
+
+	err = server.textDocumentDidOpen(context, &protocol.DidOpenTextDocumentParams{
+		TextDocument: protocol.TextDocumentItem{
+			URI:        mainURI,
+			LanguageID: "frugal",
+			Version:    1,
+			Text:       "include \"common.frugal\"\n\nstruct Account {\n    1: common.User owner\n}",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to open document: %v", err)
+	}
+	if hasDiagnostic(published[mainURI], "Unknown type") {
+		t.Fatalf("Expected common.User to resolve, got %v", published[mainURI])
+	}
+
+	// Rewriting the include on disk re-publishes diagnostics for the open dependent
+	if err := os.WriteFile(commonPath, []byte("struct Member {\n    1: i64 id\n}"), 0o644); err != nil {
+		t.Fatalf("Failed to rewrite file: %v", err)
+	}
+	err = server.workspaceDidChangeWatchedFiles(context, &protocol.DidChangeWatchedFilesParams{
+		Changes: []protocol.FileEvent{{URI: commonURI, Type: protocol.FileChangeTypeChanged}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to handle change: %v", err)
+	}
+	if !hasDiagnostic(published[mainURI], "Unknown type 'common.User'") {
+		t.Errorf("Expected unknown type after include changed, got %v", published[mainURI])
+	}
+	if len(server.symbolIndex.Search("Member", 10)) == 0 {
+		t.Error("Expected symbol index to contain the rewritten struct")
+	}
+
+	if err := os.Remove(commonPath); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+	err = server.workspaceDidChangeWatchedFiles(context, &protocol.DidChangeWatchedFilesParams{
+		Changes: []protocol.FileEvent{{URI: commonURI, Type: protocol.FileChangeTypeDeleted}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to handle delete: %v", err)
+	}
+	if _, exists := server.docManager.GetDocument(commonURI); exists {
+		t.Error("Expected deleted file to be dropped")
+	}
+	if len(server.symbolIndex.Search("Member", 10)) != 0 {
+		t.Error("Expected deleted file's symbols to be removed from the index")
+	}
+}
+
+func hasDiagnostic(diagnostics []protocol.Diagnostic, message string) bool {
+	for _, diagnostic := range diagnostics {
+		if strings.Contains(diagnostic.Message, message) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCircularIncludeDiagnostics(t *testing.T) {
+	server, err := NewServer()
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	// Edits arm a debounce timer that must not fire during later tests
+	t.Cleanup(server.dependentDiagnostics.Stop)
+
+	dir := t.TempDir()
+	aURI := "file://" + filepath.Join(dir, "a.frugal")
+	bURI := "file://" + filepath.Join(dir, "b.frugal")
+
+	published := make(map[string][]protocol.Diagnostic)
+	context := &glsp.Context{
+		Notify: func(method string, params any) {
+			if p, ok := params.(protocol.PublishDiagnosticsParams); ok {
+				published[p.URI] = p.Diagnostics
+			}
+		},
+	}
+
+	open := func(uri, text string) {
+		err := server.textDocumentDidOpen(context, &protocol.DidOpenTextDocumentParams{
+			TextDocument: protocol.TextDocumentItem{URI: uri, LanguageID: "frugal", Version: 1, Text: text},
+		})
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", uri, err)
+		}
+	}
+
+	open(aURI, "include \"b.frugal\"\n\nstruct A {\n    1: i32 x\n}")
+	open(bURI, "include \"a.frugal\"\n\nstruct B {\n    1: i32 y\n}")
+
+	if !hasDiagnostic(published[bURI], "Circular include: b.frugal -> a.frugal -> b.frugal") {
+		t.Errorf("Expected cycle diagnostic on b.frugal, got %v", published[bURI])
+	}
+	// a.frugal is re-published once b.frugal closes the cycle
+	if !hasDiagnostic(published[aURI], "Circular include: a.frugal -> b.frugal -> a.frugal") {
+		t.Fatalf("Expected cycle diagnostic on a.frugal, got %v", published[aURI])
+	}
+	for _, diagnostic := range published[aURI] {
+		if strings.HasPrefix(diagnostic.Message, "Circular include") && len(diagnostic.RelatedInformation) != 2 {
+			t.Errorf("Expected related information for each include in the cycle, got %v", diagnostic.RelatedInformation)
+		}
+	}
+
+	// Breaking the cycle in b.frugal clears the diagnostic in a.frugal
+	err = server.textDocumentDidChange(context, &protocol.DidChangeTextDocumentParams{
+		TextDocument: protocol.VersionedTextDocumentIdentifier{
+			TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: bURI},
+			Version:                2,
+		},
+		ContentChanges: []any{
+			protocol.TextDocumentContentChangeEvent{Text: "struct B {\n    1: i32 y\n}"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to change document: %v", err)
+	}
+	if hasDiagnostic(published[aURI], "Circular include") {
+		t.Errorf("Expected cycle diagnostic to be cleared, got %v", published[aURI])
+	}
+}
+
+func TestDependentDiagnosticsOnChange(t *testing.T) {
+	server, err := NewServer()
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	// Edits arm a debounce timer that must not fire during later tests
+	t.Cleanup(server.dependentDiagnostics.Stop)
+
+	dir := t.TempDir()
+	commonURI := "file://" + filepath.Join(dir, "common.frugal")
+	mainURI := "file://" + filepath.Join(dir, "main.frugal")
+
+	published := make(map[string][]protocol.Diagnostic)
+	context := &glsp.Context{
+		Notify: func(method string, params any) {
+			if p, ok := params.(protocol.PublishDiagnosticsParams); ok {
+				published[p.URI] = p.Diagnostics
+			}
+		},
+	}
+
+	for uri, text := range map[string]string{
+		commonURI: "struct User {\n    1: i64 id\n}",
+		mainURI:   "include \"common.frugal\"\n\nstruct Account {\n    1: common.User owner\n}",
+	} {
+		err := server.textDocumentDidOpen(context, &protocol.DidOpenTextDocumentParams{
+			TextDocument: protocol.TextDocumentItem{URI: uri, LanguageID: "frugal", Version: 1, Text: text},
+		})
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", uri, err)
+		}
+	}
+	if hasDiagnostic(published[mainURI], "Unknown type") {
+		t.Fatalf("Expected common.User to resolve, got %v", published[mainURI])
+	}
+
+	err = server.textDocumentDidChange(context, &protocol.DidChangeTextDocumentParams{
+		TextDocument: protocol.VersionedTextDocumentIdentifier{
+			TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: commonURI},
+			Version:                2,
+		},
+		ContentChanges: []any{
+			protocol.TextDocumentContentChangeEvent{Text: "struct Member {\n    1: i64 id\n}"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to change document: %v", err)
+	}
+
+	// Dependents are only re-published once the debounce period ends
+	if hasDiagnostic(published[mainURI], "Unknown type") {
+		t.Error("Expected dependent diagnostics to be debounced")
+	}
+	server.dependentDiagnostics.Flush()
+	if !hasDiagnostic(published[mainURI], "Unknown type 'common.User'") {
+		t.Errorf("Expected dependent to report the removed type, got %v", published[mainURI])
+	}
+}
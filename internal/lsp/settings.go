@@ -0,0 +1,176 @@
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/tliron/glsp"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+
+	"frugal-ls/internal/compat"
+	"frugal-ls/internal/config"
+)
+
+// settingsSection is the configuration section clients keep frugal-ls settings under
+const settingsSection = "frugal-ls"
+
+// Settings are the client's settings, taken from initializationOptions and then from
+// workspace/configuration or workspace/didChangeConfiguration. Values that are set take
+// precedence over the project configuration file.
+type Settings struct {
+	Format config.Format `json:"format"`
+	Lint   config.Lint   `json:"lint"`
+	Compat config.Compat `json:"compat"`
+	// Annotations declares annotation keys in addition to the project's
+	Annotations config.Annotations `json:"annotations"`
+	// IncludePaths are searched before the project's, relative to the first workspace root
+	IncludePaths []string `json:"includePaths"`
+	// PublishWorkspaceDiagnostics publishes diagnostics for files that are not open
+	PublishWorkspaceDiagnostics *bool `json:"publishWorkspaceDiagnostics"`
+}
+
+// parseSettings decodes client settings, given either as the frugal-ls section itself or
+// as an object containing it
+func parseSettings(raw any) (Settings, error) {
+	var settings Settings
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return settings, err
+	}
+
+	var sections map[string]json.RawMessage
+	if err := json.Unmarshal(data, &sections); err == nil {
+		if section, ok := sections[settingsSection]; ok {
+			data = section
+		}
+	}
+
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return settings, fmt.Errorf("invalid settings: %w", err)
+	}
+	if err := settings.Lint.Validate(); err != nil {
+		return settings, fmt.Errorf("invalid settings: %w", err)
+	}
+	if err := settings.Annotations.Validate(); err != nil {
+		return settings, fmt.Errorf("invalid settings: %w", err)
+	}
+	return settings, nil
+}
+
+// applySettings combines the project configuration with the client settings and passes
+// the result to the features that use it. The caller must hold settingsMutex.
+func (s *Server) applySettings() {
+	s.formattingProvider.SetFormat(s.config.Format.Merge(s.settings.Format))
+	s.diagnosticsProvider.SetLintConfig(s.config.Lint.Merge(s.settings.Lint))
+	s.annotationSchema.SetAnnotations(s.config.Annotations.Merge(s.settings.Annotations))
+
+	var includePaths []string
+	for _, path := range s.settings.IncludePaths {
+		includePaths = append(includePaths, s.workspacePath(path))
+	}
+	s.includeResolver.SetIncludePaths(append(includePaths, s.config.ResolvedIncludePaths()...))
+
+	if s.settings.PublishWorkspaceDiagnostics != nil {
+		s.publishWorkspaceDiagnostics.Store(*s.settings.PublishWorkspaceDiagnostics)
+	}
+
+	s.compatBase = s.config.Compat.Merge(s.settings.Compat).Base
+}
+
+// loadBaseline loads the snapshot breaking changes are reported against, relative to
+// the first workspace root. The caller must hold settingsMutex.
+func (s *Server) loadBaseline() {
+	root := s.workspacePath(".")
+	if s.compatBase == "" || !filepath.IsAbs(root) {
+		s.diagnosticsProvider.SetBaseline(nil)
+		return
+	}
+
+	snapshot, err := compat.Load(s.compatBase, root, s.config.Ignore)
+	if err != nil {
+		s.logger.Printf("Error loading compat baseline %s: %v", s.compatBase, err)
+		s.diagnosticsProvider.SetBaseline(nil)
+		return
+	}
+
+	s.logger.Printf("Loaded compat baseline %s with %d files", s.compatBase, len(snapshot.Files))
+	s.diagnosticsProvider.SetBaseline(&compat.Baseline{Root: root, Snapshot: snapshot})
+}
+
+// updateSettings replaces the client settings and re-checks every document, since lint
+// rules and include paths may have changed
+func (s *Server) updateSettings(context *glsp.Context, settings Settings) {
+	s.settingsMutex.Lock()
+	defer s.settingsMutex.Unlock()
+
+	previousBase := s.compatBase
+	s.settings = settings
+	s.applySettings()
+	if s.compatBase != previousBase {
+		s.loadBaseline()
+	}
+	s.logger.Println("Applied client settings")
+
+	documents := s.docManager.GetAllDocuments()
+	uris := make([]string, 0, len(documents))
+	for uri, doc := range documents {
+		if err := s.includeResolver.UpdateDocument(doc); err != nil {
+			s.logger.Printf("Error updating document dependencies: %v", err)
+		}
+		uris = append(uris, uri)
+	}
+	s.refreshDiagnostics(context, "", uris)
+}
+
+// workspaceDidChangeConfiguration handles workspace/didChangeConfiguration notifications
+func (s *Server) workspaceDidChangeConfiguration(context *glsp.Context, params *protocol.DidChangeConfigurationParams) error {
+	// Clients that support workspace/configuration may not include the settings, so
+	// the notification is only used as a signal to fetch them
+	if s.configurationSupport {
+		go s.fetchSettings(context)
+		return nil
+	}
+
+	if params.Settings == nil {
+		return nil
+	}
+	settings, err := parseSettings(params.Settings)
+	if err != nil {
+		s.logger.Printf("Ignoring client settings: %v", err)
+		return nil
+	}
+	s.updateSettings(context, settings)
+	return nil
+}
+
+// fetchSettings requests the frugal-ls settings section from the client
+func (s *Server) fetchSettings(context *glsp.Context) {
+	section := settingsSection
+	var result []json.RawMessage
+	context.Call(protocol.ServerWorkspaceConfiguration, protocol.ConfigurationParams{
+		Items: []protocol.ConfigurationItem{{Section: &section}},
+	}, &result)
+	if len(result) == 0 {
+		return
+	}
+
+	settings, err := parseSettings(result[0])
+	if err != nil {
+		s.logger.Printf("Ignoring client settings: %v", err)
+		return
+	}
+	s.updateSettings(context, settings)
+}
+
+// registerConfigurationChanges asks the client to send workspace/didChangeConfiguration,
+// which clients using dynamic registration only do once registered
+func (s *Server) registerConfigurationChanges(context *glsp.Context) {
+	context.Call(protocol.ServerClientRegisterCapability, protocol.RegistrationParams{
+		Registrations: []protocol.Registration{{
+			ID:     "frugal-ls-configuration",
+			Method: string(protocol.MethodWorkspaceDidChangeConfiguration),
+		}},
+	}, nil)
+}
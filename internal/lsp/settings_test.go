@@ -0,0 +1,285 @@
+package lsp
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tliron/glsp"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+
+	"frugal-ls/internal/config"
+)
+
+func TestParseSettings(t *testing.T) {
+	section := map[string]any{
+		"format":       map[string]any{"insertSpaces": false, "alignFields": true},
+		"lint":         map[string]any{"rules": map[string]any{"naming-convention": "off"}},
+		"includePaths": []any{"idl"},
+		"server":       map[string]any{"path": "frugal-ls"},
+	}
+
+	for name, raw := range map[string]any{
+		"section": section,
+		"wrapped": map[string]any{"frugal-ls": section, "editor": map[string]any{"tabSize": 2}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			settings, err := parseSettings(raw)
+			if err != nil {
+				t.Fatalf("parseSettings failed: %v", err)
+			}
+			if settings.Format.InsertSpaces == nil || *settings.Format.InsertSpaces ||
+				settings.Format.AlignFields == nil || !*settings.Format.AlignFields {
+				t.Errorf("Expected insertSpaces to be false and alignFields true, got %+v", settings.Format)
+			}
+			if settings.Lint.Rules["naming-convention"] != config.SeverityOff {
+				t.Errorf("Expected naming-convention to be off, got %v", settings.Lint.Rules)
+			}
+			if len(settings.IncludePaths) != 1 || settings.IncludePaths[0] != "idl" {
+				t.Errorf("Unexpected include paths: %v", settings.IncludePaths)
+			}
+		})
+	}
+
+	if _, err := parseSettings(map[string]any{"lint": map[string]any{"rules": map[string]any{"x": "loud"}}}); err == nil {
+		t.Error("Expected an unknown severity to be rejected")
+	}
+	if _, err := parseSettings(map[string]any{"annotations": map[string]any{"owner": map[string]any{"type": "team"}}}); err == nil {
+		t.Error("Expected an unknown annotation type to be rejected")
+	}
+	if _, err := parseSettings(map[string]any{"includePaths": "idl"}); err == nil {
+		t.Error("Expected a mistyped setting to be rejected")
+	}
+}
+
+func TestDidChangeConfiguration(t *testing.T) {
+	server, err := NewServer()
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	dir := t.TempDir()
+	server.workspaceRoots = []string{"file://" + dir}
+
+	published := make(map[string][]protocol.Diagnostic)
+	context := &glsp.Context{
+		Notify: func(method string, params any) {
+			if p, ok := params.(protocol.PublishDiagnosticsParams); ok {
+				published[p.URI] = p.Diagnostics
+			}
+		},
+	}
+
+	uri := "file://" + filepath.Join(dir, "main.frugal")
+	if err := server.textDocumentDidOpen(context, &protocol.DidOpenTextDocumentParams{
+		TextDocument: protocol.TextDocumentItem{URI: uri, LanguageID: "frugal", Version: 1, Text: "struct bad_name {\n1: i32 x\n}"},
+	}); err != nil {
+		t.Fatalf("Failed to open document: %v", err)
+	}
+	if !hasDiagnostic(published[uri], "PascalCase") {
+		t.Fatalf("Expected naming diagnostic before settings change, got %+v", published[uri])
+	}
+
+	// Settings pushed with the notification
+	err = server.workspaceDidChangeConfiguration(context, &protocol.DidChangeConfigurationParams{
+		Settings: map[string]any{"frugal-ls": map[string]any{
+			"lint":   map[string]any{"rules": map[string]any{"naming-convention": "off"}},
+			"format": map[string]any{"indentSize": 2},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("didChangeConfiguration failed: %v", err)
+	}
+	if len(published[uri]) != 0 {
+		t.Errorf("Expected diagnostics to be re-published without the disabled rule, got %+v", published[uri])
+	}
+
+	edits, err := server.textDocumentFormatting(context, &protocol.DocumentFormattingParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+		Options:      protocol.FormattingOptions{"tabSize": float64(4), "insertSpaces": true},
+	})
+	if err != nil || len(edits) != 1 || !strings.Contains(edits[0].NewText, "\n  1: i32 x") {
+		t.Errorf("Expected settings indentation to be used, got %+v, %v", edits, err)
+	}
+
+	// Settings pulled from the client with workspace/configuration
+	var requested []protocol.ConfigurationItem
+	context.Call = func(method string, params any, result any) {
+		if method != protocol.ServerWorkspaceConfiguration {
+			return
+		}
+		requested = params.(protocol.ConfigurationParams).Items
+		data := `[{"lint":{"rules":{"naming-convention":"error"}}}]`
+		if err := json.Unmarshal([]byte(data), result); err != nil {
+			t.Errorf("Failed to decode configuration result: %v", err)
+		}
+	}
+	server.configurationSupport = true
+	server.fetchSettings(context)
+
+	if len(requested) != 1 || requested[0].Section == nil || *requested[0].Section != settingsSection {
+		t.Errorf("Expected the %s section to be requested, got %+v", settingsSection, requested)
+	}
+	diagnostics := published[uri]
+	if len(diagnostics) != 1 || *diagnostics[0].Severity != protocol.DiagnosticSeverityError {
+		t.Errorf("Expected naming diagnostic as an error, got %+v", diagnostics)
+	}
+}
+
+func TestCompatBaselineSetting(t *testing.T) {
+	server, err := NewServer()
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "v1"), 0o755); err != nil {
+		t.Fatalf("Failed to create baseline directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "v1", "main.frugal"), []byte("struct User {\n    1: string name\n}"), 0o644); err != nil {
+		t.Fatalf("Failed to write baseline: %v", err)
+	}
+	server.workspaceRoots = []string{"file://" + filepath.Join(dir, "v2")}
+
+	published := make(map[string][]protocol.Diagnostic)
+	context := &glsp.Context{
+		Notify: func(method string, params any) {
+			if p, ok := params.(protocol.PublishDiagnosticsParams); ok {
+				published[p.URI] = p.Diagnostics
+			}
+		},
+	}
+
+	uri := "file://" + filepath.Join(dir, "v2", "main.frugal")
+	if err := server.textDocumentDidOpen(context, &protocol.DidOpenTextDocumentParams{
+		TextDocument: protocol.TextDocumentItem{URI: uri, LanguageID: "frugal", Version: 1, Text: "struct User {\n    2: string name\n}"},
+	}); err != nil {
+		t.Fatalf("Failed to open document: %v", err)
+	}
+	if len(published[uri]) != 0 {
+		t.Fatalf("Expected no diagnostics without a baseline, got %+v", published[uri])
+	}
+
+	err = server.workspaceDidChangeConfiguration(context, &protocol.DidChangeConfigurationParams{
+		Settings: map[string]any{"compat": map[string]any{"base": "../v1"}},
+	})
+	if err != nil {
+		t.Fatalf("didChangeConfiguration failed: %v", err)
+	}
+	if !hasDiagnostic(published[uri], "changed ID from 1 to 2") {
+		t.Errorf("Expected a breaking change diagnostic, got %+v", published[uri])
+	}
+}
+
+func TestAlignFieldsSetting(t *testing.T) {
+	server, err := NewServer()
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	context := &glsp.Context{Notify: func(method string, params any) {}}
+
+	uri := "file://" + filepath.Join(t.TempDir(), "main.frugal")
+	if err := server.textDocumentDidOpen(context, &protocol.DidOpenTextDocumentParams{
+		TextDocument: protocol.TextDocumentItem{URI: uri, LanguageID: "frugal", Version: 1, Text: "struct User {\n    1: string name,\n    10: i64 id\n}"},
+	}); err != nil {
+		t.Fatalf("Failed to open document: %v", err)
+	}
+	format := func() []protocol.TextEdit {
+		edits, err := server.textDocumentFormatting(context, &protocol.DocumentFormattingParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+			Options:      protocol.FormattingOptions{"tabSize": 4, "insertSpaces": true},
+		})
+		if err != nil {
+			t.Fatalf("Formatting failed: %v", err)
+		}
+		return edits
+	}
+	if edits := format(); len(edits) != 0 {
+		t.Fatalf("Expected no edits without alignment, got %+v", edits)
+	}
+
+	err = server.workspaceDidChangeConfiguration(context, &protocol.DidChangeConfigurationParams{
+		Settings: map[string]any{"frugal-ls": map[string]any{"format": map[string]any{"alignFields": true}}},
+	})
+	if err != nil {
+		t.Fatalf("didChangeConfiguration failed: %v", err)
+	}
+	edits := format()
+	if len(edits) != 1 || edits[0].NewText != "struct User {\n    1:  string name,\n    10: i64    id\n}" {
+		t.Errorf("Expected the setting to align fields, got %+v", edits)
+	}
+}
+
+func TestAnnotationsSetting(t *testing.T) {
+	server, err := NewServer()
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	published := make(map[string][]protocol.Diagnostic)
+	context := &glsp.Context{
+		Notify: func(method string, params any) {
+			if p, ok := params.(protocol.PublishDiagnosticsParams); ok {
+				published[p.URI] = p.Diagnostics
+			}
+		},
+	}
+
+	uri := "file://" + filepath.Join(t.TempDir(), "main.frugal")
+	if err := server.textDocumentDidOpen(context, &protocol.DidOpenTextDocumentParams{
+		TextDocument: protocol.TextDocumentItem{URI: uri, LanguageID: "frugal", Version: 1, Text: "struct User {\n1: i32 age (retention.days = \"30\")\n}"},
+	}); err != nil {
+		t.Fatalf("Failed to open document: %v", err)
+	}
+	if !hasDiagnostic(published[uri], "Unknown annotation 'retention.days'") {
+		t.Fatalf("Expected unknown annotation diagnostic, got %+v", published[uri])
+	}
+
+	err = server.workspaceDidChangeConfiguration(context, &protocol.DidChangeConfigurationParams{
+		Settings: map[string]any{"frugal-ls": map[string]any{
+			"annotations": map[string]any{"retention.days": map[string]any{"type": "int"}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("didChangeConfiguration failed: %v", err)
+	}
+	if len(published[uri]) != 0 {
+		t.Errorf("Expected the declared annotation to be accepted, got %+v", published[uri])
+	}
+}
+
+func TestSettingsUpdatedWhileIndexing(t *testing.T) {
+	server, err := NewServer()
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.frugal")
+	if err := os.WriteFile(path, []byte("struct User {\n    1: string name\n}"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	server.workspaceRoots = []string{"file://" + dir}
+	context := &glsp.Context{Notify: func(method string, params any) {}}
+
+	// Run with -race: settings fetched in the background must not race with indexing
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			enabled := i%2 == 0
+			server.updateSettings(context, Settings{PublishWorkspaceDiagnostics: &enabled})
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		server.refreshDiagnostics(context, "", []string{"file://" + path})
+		server.indexWorkspace(context)
+	}
+	<-done
+
+	if server.publishWorkspaceDiagnostics.Load() {
+		t.Error("Expected the last settings update to disable workspace diagnostics")
+	}
+}
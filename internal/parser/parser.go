@@ -2,6 +2,7 @@ package parser
 
 import (
 	"fmt"
+	"sort"
 
 	tree_sitter_frugal "github.com/charliestrawn/tree-sitter-frugal/bindings/go"
 	tree_sitter "github.com/tree-sitter/go-tree-sitter"
@@ -17,6 +18,12 @@ type TreeSitterParser struct {
 type ParseResult struct {
 	Tree   *tree_sitter.Tree
 	Errors []ParseError
+	// ChangedRanges lists the regions that differ from the tree this result was
+	// reparsed from. It is nil for results parsed from scratch.
+	ChangedRanges []tree_sitter.Range
+
+	// edited covers every edit applied to Tree since it was parsed
+	edited *tree_sitter.Range
 }
 
 // ParseError represents a parsing error
@@ -57,6 +64,35 @@ func (p *TreeSitterParser) Parse(source []byte) (*ParseResult, error) {
 		return nil, fmt.Errorf("failed to parse source code")
 	}
 
+	return p.newResult(tree, source), nil
+}
+
+// Reparse parses the source reusing an old result whose tree has been brought up to
+// date with Edit. Unchanged subtrees are shared with the old tree, which the caller
+// still owns and must close.
+func (p *TreeSitterParser) Reparse(source []byte, old *ParseResult) (*ParseResult, error) {
+	if old == nil || old.Tree == nil {
+		return p.Parse(source)
+	}
+
+	tree := p.parser.Parse(source, old.Tree)
+	if tree == nil {
+		return nil, fmt.Errorf("failed to reparse source code")
+	}
+
+	result := p.newResult(tree, source)
+	result.ChangedRanges = append([]tree_sitter.Range{}, old.Tree.ChangedRanges(tree)...)
+	if old.edited != nil {
+		// Edits inside a single token leave the tree shape alone, so tree-sitter
+		// does not report them; the edited text itself always counts as changed.
+		result.ChangedRanges = mergeRanges(append(result.ChangedRanges, *old.edited))
+	}
+
+	return result, nil
+}
+
+// newResult wraps a parsed tree and collects its syntax errors
+func (p *TreeSitterParser) newResult(tree *tree_sitter.Tree, source []byte) *ParseResult {
 	result := &ParseResult{
 		Tree:   tree,
 		Errors: []ParseError{},
@@ -67,7 +103,7 @@ func (p *TreeSitterParser) Parse(source []byte) (*ParseResult, error) {
 		result.Errors = p.collectErrors(tree.RootNode(), source)
 	}
 
-	return result, nil
+	return result
 }
 
 // collectErrors walks the syntax tree and collects parsing errors
@@ -113,6 +149,73 @@ func (r *ParseResult) GetRootNode() *tree_sitter.Node {
 	return r.Tree.RootNode()
 }
 
+// Edit records a text edit against the tree so it can be passed to Reparse. Edits
+// must be applied in the order they were made to the source.
+func (r *ParseResult) Edit(edit *tree_sitter.InputEdit) {
+	if r.Tree == nil {
+		return
+	}
+	r.Tree.Edit(edit)
+
+	changed := tree_sitter.Range{
+		StartByte:  edit.StartByte,
+		EndByte:    edit.NewEndByte,
+		StartPoint: edit.StartPosition,
+		EndPoint:   edit.NewEndPosition,
+	}
+	if prev := r.edited; prev != nil {
+		// Move the end of the earlier edits into the new coordinates before joining
+		end, endPoint := prev.EndByte, prev.EndPoint
+		if end >= edit.OldEndByte {
+			end = end - edit.OldEndByte + edit.NewEndByte
+			endPoint = shiftPoint(endPoint, edit)
+		} else if end > edit.StartByte {
+			end, endPoint = edit.NewEndByte, edit.NewEndPosition
+		}
+
+		if prev.StartByte < changed.StartByte {
+			changed.StartByte, changed.StartPoint = prev.StartByte, prev.StartPoint
+		}
+		if end > changed.EndByte {
+			changed.EndByte, changed.EndPoint = end, endPoint
+		}
+	}
+	r.edited = &changed
+}
+
+// shiftPoint moves a point at or after an edit's old end to where it is after the edit
+func shiftPoint(point tree_sitter.Point, edit *tree_sitter.InputEdit) tree_sitter.Point {
+	if point.Row == edit.OldEndPosition.Row {
+		return tree_sitter.Point{
+			Row:    edit.NewEndPosition.Row,
+			Column: point.Column - edit.OldEndPosition.Column + edit.NewEndPosition.Column,
+		}
+	}
+	return tree_sitter.Point{
+		Row:    point.Row - edit.OldEndPosition.Row + edit.NewEndPosition.Row,
+		Column: point.Column,
+	}
+}
+
+// mergeRanges sorts ranges by start and joins those that overlap or touch
+func mergeRanges(ranges []tree_sitter.Range) []tree_sitter.Range {
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].StartByte < ranges[j].StartByte
+	})
+
+	var merged []tree_sitter.Range
+	for _, r := range ranges {
+		if n := len(merged); n > 0 && r.StartByte <= merged[n-1].EndByte {
+			if r.EndByte > merged[n-1].EndByte {
+				merged[n-1].EndByte, merged[n-1].EndPoint = r.EndByte, r.EndPoint
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
 // HasErrors returns true if there are parsing errors
 func (r *ParseResult) HasErrors() bool {
 	return len(r.Errors) > 0
@@ -1,7 +1,10 @@
 package parser
 
 import (
+	"strings"
 	"testing"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
 )
 
 //nolint:gocognit // Test functions are naturally complex
@@ -810,3 +813,90 @@ func TestParseResultMethods(t *testing.T) {
 		t.Error("HasErrors returned false for invalid syntax")
 	}
 }
+
+func TestReparse(t *testing.T) {
+	parser, err := NewParser()
+	if err != nil {
+		t.Fatalf("failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	source := "struct A {\n    1: string name\n}\n\nstruct B {\n    1: i32 count\n}"
+	old, err := parser.Parse([]byte(source))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	defer old.Close()
+
+	if old.ChangedRanges != nil {
+		t.Errorf("Expected no changed ranges for a full parse, got %+v", old.ChangedRanges)
+	}
+
+	// Rename the field in B from "count" to "total"
+	start := uint(strings.Index(source, "count"))
+	old.Edit(&tree_sitter.InputEdit{
+		StartByte:      start,
+		OldEndByte:     start + 5,
+		NewEndByte:     start + 5,
+		StartPosition:  tree_sitter.Point{Row: 5, Column: 11},
+		OldEndPosition: tree_sitter.Point{Row: 5, Column: 16},
+		NewEndPosition: tree_sitter.Point{Row: 5, Column: 16},
+	})
+	edited := strings.Replace(source, "count", "total", 1)
+
+	result, err := parser.Reparse([]byte(edited), old)
+	if err != nil {
+		t.Fatalf("failed to reparse: %v", err)
+	}
+	defer result.Close()
+
+	if result.HasErrors() {
+		t.Errorf("Unexpected errors after reparse: %+v", result.Errors)
+	}
+	if len(result.ChangedRanges) != 1 {
+		t.Fatalf("Expected the edited identifier as the only changed range, got %+v", result.ChangedRanges)
+	}
+	if r := result.ChangedRanges[0]; r.StartPoint.Row != 5 || r.EndPoint.Row != 5 {
+		t.Errorf("Expected changed range on line 5, got %+v", r)
+	}
+}
+
+func TestParseResultEditMergesEdits(t *testing.T) {
+	parser, err := NewParser()
+	if err != nil {
+		t.Fatalf("failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	result, err := parser.Parse([]byte("struct A {\n    1: string a\n}"))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	defer result.Close()
+
+	// Insert a line after the field, then a character at the start of the struct name
+	result.Edit(&tree_sitter.InputEdit{
+		StartByte:      26,
+		OldEndByte:     26,
+		NewEndByte:     40,
+		StartPosition:  tree_sitter.Point{Row: 1, Column: 15},
+		OldEndPosition: tree_sitter.Point{Row: 1, Column: 15},
+		NewEndPosition: tree_sitter.Point{Row: 2, Column: 13},
+	})
+	result.Edit(&tree_sitter.InputEdit{
+		StartByte:      7,
+		OldEndByte:     7,
+		NewEndByte:     8,
+		StartPosition:  tree_sitter.Point{Row: 0, Column: 7},
+		OldEndPosition: tree_sitter.Point{Row: 0, Column: 7},
+		NewEndPosition: tree_sitter.Point{Row: 0, Column: 8},
+	})
+
+	edited := result.edited
+	if edited == nil || edited.StartByte != 7 || edited.EndByte != 41 {
+		t.Fatalf("Expected edits to cover bytes 7-41, got %+v", edited)
+	}
+	if edited.EndPoint.Row != 2 || edited.EndPoint.Column != 13 {
+		t.Errorf("Expected later lines to keep their columns, got %+v", edited.EndPoint)
+	}
+}
@@ -0,0 +1,138 @@
+package workspace
+
+import (
+	"io/fs"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"frugal-ls/internal/document"
+	"frugal-ls/internal/parser"
+)
+
+// skippedDirs are directories that are never crawled for .frugal files
+var skippedDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// FindFrugalFiles returns every .frugal file under the given workspace roots.
+// Roots may be file URIs or paths; hidden directories are skipped, as are directories
+// matching an ignore pattern by name or by path relative to the root.
+func FindFrugalFiles(roots []string, ignore ...string) []string {
+	seen := make(map[string]bool)
+	var paths []string
+
+	for _, root := range roots {
+		rootPath := filepath.Clean(uriToPath(root))
+
+		_ = filepath.WalkDir(rootPath, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				// Unreadable entries are skipped rather than aborting the crawl
+				return nil
+			}
+
+			if entry.IsDir() {
+				if path != rootPath && skipDir(rootPath, path, ignore) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			if strings.HasSuffix(path, ".frugal") && !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+			return nil
+		})
+	}
+
+	sort.Strings(paths)
+	return paths
+}
+
+// skipDir reports whether a directory under root is left out of the crawl
+func skipDir(root, dir string, ignore []string) bool {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return false
+	}
+	return skipRelDir(filepath.ToSlash(rel), ignore)
+}
+
+// IsIgnored reports whether FindFrugalFiles would leave out the file at rel, a
+// slash-separated path relative to a workspace root
+func IsIgnored(rel string, ignore []string) bool {
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		if skipRelDir(strings.Join(parts[:i], "/"), ignore) {
+			return true
+		}
+	}
+	return false
+}
+
+// skipRelDir reports whether a directory, given by slash-separated path relative to
+// the root, is skipped or matches one of the ignore patterns
+func skipRelDir(rel string, ignore []string) bool {
+	name := path.Base(rel)
+	if skippedDirs[name] || strings.HasPrefix(name, ".") {
+		return true
+	}
+
+	for _, pattern := range ignore {
+		pattern = strings.TrimSuffix(filepath.ToSlash(pattern), "/")
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+		if matched, _ := path.Match(pattern, rel); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadFiles parses the files with a pool of workers and calls loaded for each document.
+// loaded may be called concurrently. A workers value of zero uses one worker per CPU.
+func LoadFiles(paths []string, workers int, loaded func(*document.Document)) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// Tree-sitter parsers are not safe for concurrent use, so each worker owns one
+			p, err := parser.NewParser()
+			if err != nil {
+				for range jobs {
+				}
+				return
+			}
+			defer p.Close()
+
+			for path := range jobs {
+				doc, err := document.LoadFile(p, pathToURI(path), path)
+				if err != nil {
+					continue
+				}
+				loaded(doc)
+			}
+		}()
+	}
+
+	for _, path := range paths {
+		jobs <- path
+	}
+	close(jobs)
+
+	wg.Wait()
+}
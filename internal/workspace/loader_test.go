@@ -0,0 +1,137 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"frugal-ls/internal/document"
+)
+
+func writeWorkspaceFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+}
+
+func TestFindFrugalFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeWorkspaceFiles(t, dir, map[string]string{
+		"main.frugal":                 "struct A {}",
+		"nested/common.frugal":        "struct B {}",
+		"nested/readme.md":            "not frugal",
+		".git/ignored.frugal":         "struct C {}",
+		"node_modules/pkg/dep.frugal": "struct D {}",
+		"nested/deeper/events.frugal": "struct E {}",
+	})
+
+	paths := FindFrugalFiles([]string{pathToURI(dir), dir})
+
+	expected := []string{
+		filepath.Join(dir, "main.frugal"),
+		filepath.Join(dir, "nested/common.frugal"),
+		filepath.Join(dir, "nested/deeper/events.frugal"),
+	}
+	if len(paths) != len(expected) {
+		t.Fatalf("Expected %d files, got %d: %v", len(expected), len(paths), paths)
+	}
+	for i, path := range expected {
+		if paths[i] != path {
+			t.Errorf("File %d: expected %s, got %s", i, path, paths[i])
+		}
+	}
+}
+
+func TestFindFrugalFilesIgnore(t *testing.T) {
+	dir := t.TempDir()
+	writeWorkspaceFiles(t, dir, map[string]string{
+		"main.frugal":              "struct A {}",
+		"gen/out.frugal":           "struct B {}",
+		"api/gen/nested.frugal":    "struct C {}",
+		"api/legacy/old.frugal":    "struct D {}",
+		"api/current/user.frugal":  "struct E {}",
+		"other/legacy/keep.frugal": "struct F {}",
+	})
+
+	paths := FindFrugalFiles([]string{dir}, "gen", "api/legacy/")
+
+	expected := []string{
+		filepath.Join(dir, "api/current/user.frugal"),
+		filepath.Join(dir, "main.frugal"),
+		filepath.Join(dir, "other/legacy/keep.frugal"),
+	}
+	if len(paths) != len(expected) {
+		t.Fatalf("Expected %d files, got %d: %v", len(expected), len(paths), paths)
+	}
+	for i, path := range expected {
+		if paths[i] != path {
+			t.Errorf("File %d: expected %s, got %s", i, path, paths[i])
+		}
+	}
+}
+
+func TestIsIgnored(t *testing.T) {
+	ignore := []string{"gen", "api/legacy/"}
+	for rel, expected := range map[string]bool{
+		"main.frugal":                 false,
+		"gen/out.frugal":              true,
+		"api/gen/nested.frugal":       true,
+		"api/legacy/old.frugal":       true,
+		"api/current/user.frugal":     false,
+		"other/legacy/keep.frugal":    false,
+		".git/ignored.frugal":         true,
+		"node_modules/pkg/dep.frugal": true,
+		"gen.frugal":                  false,
+	} {
+		if got := IsIgnored(rel, ignore); got != expected {
+			t.Errorf("IsIgnored(%q) = %v, expected %v", rel, got, expected)
+		}
+	}
+}
+
+func TestFindFrugalFilesMissingRoot(t *testing.T) {
+	if paths := FindFrugalFiles([]string{"/nonexistent/workspace"}); len(paths) != 0 {
+		t.Errorf("Expected no files for missing root, got %v", paths)
+	}
+}
+
+func TestLoadFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeWorkspaceFiles(t, dir, map[string]string{
+		"a.frugal": "struct A {\n    1: i32 x\n}",
+		"b.frugal": "service B {\n    void ping()\n}",
+		"c.frugal": "enum C {\n    ONE = 1\n}",
+	})
+
+	paths := FindFrugalFiles([]string{dir})
+	paths = append(paths, filepath.Join(dir, "missing.frugal"))
+
+	var mu sync.Mutex
+	loaded := make(map[string]*document.Document)
+	LoadFiles(paths, 2, func(doc *document.Document) {
+		mu.Lock()
+		defer mu.Unlock()
+		loaded[doc.URI] = doc
+	})
+
+	if len(loaded) != 3 {
+		t.Fatalf("Expected 3 loaded documents, got %d", len(loaded))
+	}
+
+	doc := loaded[pathToURI(filepath.Join(dir, "b.frugal"))]
+	if doc == nil {
+		t.Fatal("Expected b.frugal to be loaded")
+	}
+	if doc.ParseResult == nil || doc.GetModel().FindService("B") == nil {
+		t.Error("Expected loaded document to be parsed with its model built")
+	}
+}
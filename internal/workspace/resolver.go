@@ -2,7 +2,9 @@ package workspace
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 
@@ -26,6 +28,12 @@ type IncludeResolver struct {
 	// Workspace root paths for resolving relative includes
 	workspaceRoots []string
 
+	// Additional directories searched for includes, like frugal's -I flag
+	includePaths []string
+
+	// Open documents count as existing even when they are not saved to disk
+	documents DocumentSource
+
 	mutex sync.RWMutex
 }
 
@@ -39,6 +47,72 @@ func NewIncludeResolver(workspaceRoots []string) *IncludeResolver {
 	}
 }
 
+// SetIncludePaths sets the additional directories searched for includes
+func (r *IncludeResolver) SetIncludePaths(paths []string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.includePaths = paths
+	r.includeCache = make(map[string]string)
+}
+
+// SetDocumentSource sets the documents consulted before checking the file system
+func (r *IncludeResolver) SetDocumentSource(docs DocumentSource) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.documents = docs
+}
+
+// InvalidateCache drops all cached include resolutions, e.g. after files are created or deleted
+func (r *IncludeResolver) InvalidateCache() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.includeCache = make(map[string]string)
+}
+
+// FindIncludeCandidates returns include paths, relative to the including document, for
+// files in the workspace and include paths whose name matches the given include
+func (r *IncludeResolver) FindIncludeCandidates(fromURI, includePath string) []string {
+	r.mutex.RLock()
+	searchDirs := append(append([]string{}, r.workspaceRoots...), r.includePaths...)
+	docs := r.documents
+	r.mutex.RUnlock()
+
+	files := FindFrugalFiles(searchDirs)
+	if docs != nil {
+		for _, doc := range docs.GetAllDocuments() {
+			files = append(files, doc.Path)
+		}
+	}
+
+	fromPath := uriToPath(fromURI)
+	fromDir := filepath.Dir(fromPath)
+	base := filepath.Base(includePath)
+
+	seen := make(map[string]bool)
+	var candidates []string
+	for _, file := range files {
+		if filepath.Base(file) != base || file == fromPath {
+			continue
+		}
+		rel, err := filepath.Rel(fromDir, file)
+		if err != nil {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == includePath || seen[rel] {
+			continue
+		}
+		seen[rel] = true
+		candidates = append(candidates, rel)
+	}
+
+	sort.Strings(candidates)
+	return candidates
+}
+
 // UpdateDocument updates the dependency tracking for a document
 func (r *IncludeResolver) UpdateDocument(doc *document.Document) error {
 	r.mutex.Lock()
@@ -114,6 +188,32 @@ func (r *IncludeResolver) GetDependents(uri string) []string {
 	return result
 }
 
+// GetTransitiveDependents returns every file that directly or indirectly includes the given document
+func (r *IncludeResolver) GetTransitiveDependents(uri string) []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	visited := map[string]bool{uri: true}
+	queue := []string{uri}
+	var result []string
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, dependent := range r.dependents[current] {
+			if visited[dependent] {
+				continue
+			}
+			visited[dependent] = true
+			result = append(result, dependent)
+			queue = append(queue, dependent)
+		}
+	}
+
+	return result
+}
+
 // GetAllSymbols returns symbols from a document and all its dependencies
 func (r *IncludeResolver) GetAllSymbols(doc *document.Document, docManager *document.Manager) []ast.Symbol {
 	var allSymbols []ast.Symbol
@@ -123,6 +223,14 @@ func (r *IncludeResolver) GetAllSymbols(doc *document.Document, docManager *docu
 	return allSymbols
 }
 
+// ResolveInclude resolves an include path relative to the including document
+func (r *IncludeResolver) ResolveInclude(fromURI, includePath string) (string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.resolveIncludePath(includePath, fromURI)
+}
+
 // HasCircularDependency checks if adding a dependency would create a circular reference
 func (r *IncludeResolver) HasCircularDependency(fromURI, toURI string) bool {
 	r.mutex.RLock()
@@ -132,6 +240,58 @@ func (r *IncludeResolver) HasCircularDependency(fromURI, toURI string) bool {
 	return r.hasCircularDependencyRecursive(toURI, fromURI, visited)
 }
 
+// CyclePath returns the include cycle created by fromURI including toURI, starting and
+// ending at fromURI, or nil if toURI does not lead back to fromURI
+func (r *IncludeResolver) CyclePath(fromURI, toURI string) []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	visited := make(map[string]bool)
+	path := r.findPathRecursive(toURI, fromURI, visited)
+	if path == nil {
+		return nil
+	}
+	return append([]string{fromURI}, path...)
+}
+
+// CycleMembers returns every document that shares an include cycle with uri, including uri
+func (r *IncludeResolver) CycleMembers(uri string) []string {
+	seen := make(map[string]bool)
+	var members []string
+	for _, dep := range r.GetDependencies(uri) {
+		for _, member := range r.CyclePath(uri, dep) {
+			if !seen[member] {
+				seen[member] = true
+				members = append(members, member)
+			}
+		}
+	}
+	sort.Strings(members)
+	return members
+}
+
+// IncludeRange returns the range of the include string in fromURI that resolves to toURI
+func (r *IncludeResolver) IncludeRange(fromURI, toURI string) (ast.Range, bool) {
+	r.mutex.RLock()
+	docs := r.documents
+	r.mutex.RUnlock()
+
+	if docs == nil {
+		return ast.Range{}, false
+	}
+	doc, exists := docs.GetDocument(fromURI)
+	if !exists {
+		return ast.Range{}, false
+	}
+
+	for _, include := range doc.GetModel().Includes {
+		if resolved, err := r.ResolveInclude(fromURI, include.Path); err == nil && resolved == toURI {
+			return include.PathRange, true
+		}
+	}
+	return ast.Range{}, false
+}
+
 // extractIncludes extracts include statements from a document
 func (r *IncludeResolver) extractIncludes(doc *document.Document) []string {
 	if doc.ParseResult == nil || doc.ParseResult.GetRootNode() == nil {
@@ -176,46 +336,64 @@ func (r *IncludeResolver) findIncludesRecursive(node *tree_sitter.Node, source [
 	}
 }
 
-// resolveIncludePath resolves a relative include path to an absolute file URI
+// resolveIncludePath resolves an include path to the URI of an existing file. Candidates are
+// tried relative to the including file, then the include paths, then the workspace roots.
 func (r *IncludeResolver) resolveIncludePath(includePath, fromURI string) (string, error) {
+	if includePath == "" {
+		return "", fmt.Errorf("empty include path")
+	}
+
 	// Check cache first
 	cacheKey := fromURI + ":" + includePath
 	if resolvedURI, exists := r.includeCache[cacheKey]; exists {
 		return resolvedURI, nil
 	}
 
-	// Parse the source URI to get its directory
-	fromPath := uriToPath(fromURI)
-
-	fromDir := filepath.Dir(fromPath)
-
-	// Try resolving relative to the source file
-	candidates := []string{
-		filepath.Join(fromDir, includePath),
-	}
-
-	// Also try resolving relative to workspace roots
-	for _, root := range r.workspaceRoots {
-		candidates = append(candidates, filepath.Join(root, includePath))
+	var candidates []string
+	if filepath.IsAbs(includePath) {
+		candidates = append(candidates, includePath)
+	} else {
+		// Try resolving relative to the source file
+		fromDir := filepath.Dir(uriToPath(fromURI))
+		candidates = append(candidates, filepath.Join(fromDir, includePath))
+
+		// Then the configured include paths and workspace roots
+		for _, dir := range r.includePaths {
+			candidates = append(candidates, filepath.Join(uriToPath(dir), includePath))
+		}
+		for _, root := range r.workspaceRoots {
+			candidates = append(candidates, filepath.Join(uriToPath(root), includePath))
+		}
 	}
 
 	// Find the first existing file
 	for _, candidate := range candidates {
-		// Clean and normalize the path
 		cleanPath := filepath.Clean(candidate)
+		if !r.fileExists(cleanPath) {
+			continue
+		}
 
-		// Convert back to file URI
+		// Only successful resolutions are cached so new files are found later
 		resolvedURI := pathToURI(cleanPath)
-
-		// Cache the result
 		r.includeCache[cacheKey] = resolvedURI
-
 		return resolvedURI, nil
 	}
 
 	return "", fmt.Errorf("could not resolve include path: %s", includePath)
 }
 
+// fileExists reports whether a path is an open document or a regular file on disk
+func (r *IncludeResolver) fileExists(path string) bool {
+	if r.documents != nil {
+		if _, exists := r.documents.GetDocument(pathToURI(path)); exists {
+			return true
+		}
+	}
+
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
 // clearDependencies removes all dependency mappings for a document
 func (r *IncludeResolver) clearDependencies(uri string) {
 	// Remove from dependents of our dependencies
@@ -290,6 +468,26 @@ func (r *IncludeResolver) hasCircularDependencyRecursive(currentURI, targetURI s
 	return false
 }
 
+// findPathRecursive returns the dependency path from currentURI to targetURI, inclusive
+func (r *IncludeResolver) findPathRecursive(currentURI, targetURI string, visited map[string]bool) []string {
+	if currentURI == targetURI {
+		return []string{currentURI}
+	}
+
+	if visited[currentURI] {
+		return nil
+	}
+	visited[currentURI] = true
+
+	for _, depURI := range r.dependencies[currentURI] {
+		if path := r.findPathRecursive(depURI, targetURI, visited); path != nil {
+			return append([]string{currentURI}, path...)
+		}
+	}
+
+	return nil
+}
+
 // uriToPath converts a file URI to a file system path
 func uriToPath(uri string) string {
 	if strings.HasPrefix(uri, "file://") {
@@ -1,6 +1,9 @@
 package workspace
 
 import (
+	"os"
+	"path/filepath"
+	"sort"
 	"testing"
 
 	"frugal-ls/internal/document"
@@ -60,6 +63,7 @@ func TestUpdateDocumentNoIncludes(t *testing.T) {
 
 func TestUpdateDocumentWithIncludes(t *testing.T) {
 	resolver := NewIncludeResolver([]string{"/workspace"})
+	withDocuments(resolver, "file:///common.frugal", "file:///types.frugal")
 
 	content := `include "common.frugal"
 include "types.frugal"
@@ -93,6 +97,7 @@ func TestGetDependenciesNotFound(t *testing.T) {
 
 func TestGetDependents(t *testing.T) {
 	resolver := NewIncludeResolver([]string{"/workspace"})
+	withDocuments(resolver, "file:///common.frugal")
 
 	// Create a document that includes another file
 	content1 := `include "common.frugal"
@@ -139,6 +144,11 @@ func TestGetDependentsNotFound(t *testing.T) {
 
 func TestResolveIncludePath(t *testing.T) {
 	resolver := NewIncludeResolver([]string{"/workspace"})
+	withDocuments(resolver,
+		"file:///workspace/common.frugal",
+		"file:///workspace/shared/types.frugal",
+		"file:///workspace/models/user.frugal",
+	)
 
 	testCases := []struct {
 		name        string
@@ -168,6 +178,13 @@ func TestResolveIncludePath(t *testing.T) {
 			expected:    "file:///workspace/models/user.frugal",
 			expectError: false,
 		},
+		{
+			name:        "missing file",
+			includePath: "missing.frugal",
+			fromFile:    "file:///workspace/service.frugal",
+			expected:    "",
+			expectError: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -190,6 +207,7 @@ func TestResolveIncludePath(t *testing.T) {
 
 func TestClearDependencies(t *testing.T) {
 	resolver := NewIncludeResolver([]string{"/workspace"})
+	withDocuments(resolver, "file:///common.frugal")
 
 	// Add some dependencies first
 	content := `include "common.frugal"
@@ -342,6 +360,7 @@ service UserService {
 
 func TestResolveIncludePathEdgeCases(t *testing.T) {
 	resolver := NewIncludeResolver([]string{"/workspace"})
+	withDocuments(resolver, "file:///workspace/common.frugal")
 
 	testCases := []struct {
 		name        string
@@ -353,7 +372,7 @@ func TestResolveIncludePathEdgeCases(t *testing.T) {
 			name:        "empty include path",
 			includePath: "",
 			fromFile:    "file:///test.frugal",
-			expectError: false, // Resolver handles gracefully
+			expectError: true,
 		},
 		{
 			name:        "empty from file",
@@ -391,6 +410,7 @@ func TestResolveIncludePathEdgeCases(t *testing.T) {
 
 func TestDependencyGraphIntegrity(t *testing.T) {
 	resolver := NewIncludeResolver([]string{"/workspace"})
+	withDocuments(resolver, "file:///b.frugal", "file:///c.frugal")
 
 	// Create a dependency chain: A includes B, B includes C
 	contentA := `include "b.frugal"
@@ -433,6 +453,15 @@ struct B {}`
 	}
 }
 
+// withDocuments makes the given URIs resolvable as open documents
+func withDocuments(resolver *IncludeResolver, uris ...string) {
+	source := make(testDocumentSource)
+	for _, uri := range uris {
+		source[uri] = &document.Document{URI: uri, Path: uriToPath(uri)}
+	}
+	resolver.SetDocumentSource(source)
+}
+
 // Helper function to create a test document
 func createTestDocument(uri string, content string) *document.Document {
 	// Create with parse result for realistic testing
@@ -469,3 +498,132 @@ func createTestDocument(uri string, content string) *document.Document {
 		ParseResult: parseResult,
 	}
 }
+
+func TestResolveIncludeSearchPaths(t *testing.T) {
+	root := t.TempDir()
+	vendor := t.TempDir()
+	writeWorkspaceFiles(t, root, map[string]string{"service/main.frugal": "", "local.frugal": ""})
+	writeWorkspaceFiles(t, vendor, map[string]string{"shared/base.frugal": ""})
+
+	resolver := NewIncludeResolver([]string{pathToURI(root)})
+	fromURI := pathToURI(filepath.Join(root, "service/main.frugal"))
+
+	if _, err := resolver.ResolveInclude(fromURI, "shared/base.frugal"); err == nil {
+		t.Fatal("Expected include outside search paths not to resolve")
+	}
+
+	resolver.SetIncludePaths([]string{vendor})
+	resolved, err := resolver.ResolveInclude(fromURI, "shared/base.frugal")
+	if err != nil || resolved != pathToURI(filepath.Join(vendor, "shared/base.frugal")) {
+		t.Errorf("Expected include path to resolve, got %q (%v)", resolved, err)
+	}
+
+	// Workspace roots given as URIs are searched too
+	resolved, err = resolver.ResolveInclude(fromURI, "local.frugal")
+	if err != nil || resolved != pathToURI(filepath.Join(root, "local.frugal")) {
+		t.Errorf("Expected workspace root to resolve, got %q (%v)", resolved, err)
+	}
+}
+
+func TestResolveIncludeCacheInvalidation(t *testing.T) {
+	root := t.TempDir()
+	writeWorkspaceFiles(t, root, map[string]string{"main.frugal": "", "common.frugal": ""})
+
+	resolver := NewIncludeResolver([]string{root})
+	fromURI := pathToURI(filepath.Join(root, "main.frugal"))
+
+	if _, err := resolver.ResolveInclude(fromURI, "common.frugal"); err != nil {
+		t.Fatalf("Expected include to resolve: %v", err)
+	}
+
+	if err := os.Remove(filepath.Join(root, "common.frugal")); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+	if _, err := resolver.ResolveInclude(fromURI, "common.frugal"); err != nil {
+		t.Error("Expected cached resolution before invalidation")
+	}
+
+	resolver.InvalidateCache()
+	if _, err := resolver.ResolveInclude(fromURI, "common.frugal"); err == nil {
+		t.Error("Expected deleted include not to resolve after invalidation")
+	}
+}
+
+func TestFindIncludeCandidates(t *testing.T) {
+	root := t.TempDir()
+	writeWorkspaceFiles(t, root, map[string]string{
+		"service/main.frugal":  "",
+		"shared/common.frugal": "",
+		"other/common.frugal":  "",
+		"shared/types.frugal":  "",
+	})
+
+	resolver := NewIncludeResolver([]string{root})
+	candidates := resolver.FindIncludeCandidates(pathToURI(filepath.Join(root, "service/main.frugal")), "common.frugal")
+
+	expected := []string{"../other/common.frugal", "../shared/common.frugal"}
+	if len(candidates) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, candidates)
+	}
+	for i := range expected {
+		if candidates[i] != expected[i] {
+			t.Errorf("Candidate %d: expected %s, got %s", i, expected[i], candidates[i])
+		}
+	}
+}
+
+func TestCyclePath(t *testing.T) {
+	resolver := NewIncludeResolver([]string{"/workspace"})
+	withDocuments(resolver, "file:///a.frugal", "file:///b.frugal", "file:///c.frugal", "file:///d.frugal")
+
+	resolver.UpdateDocument(createTestDocument("file:///a.frugal", `include "b.frugal"`))
+	resolver.UpdateDocument(createTestDocument("file:///b.frugal", `include "c.frugal"`))
+	resolver.UpdateDocument(createTestDocument("file:///c.frugal", `include "a.frugal"`))
+	resolver.UpdateDocument(createTestDocument("file:///d.frugal", `include "a.frugal"`))
+
+	cycle := resolver.CyclePath("file:///a.frugal", "file:///b.frugal")
+	expected := []string{"file:///a.frugal", "file:///b.frugal", "file:///c.frugal", "file:///a.frugal"}
+	if len(cycle) != len(expected) {
+		t.Fatalf("Expected cycle %v, got %v", expected, cycle)
+	}
+	for i := range expected {
+		if cycle[i] != expected[i] {
+			t.Errorf("Cycle step %d: expected %s, got %s", i, expected[i], cycle[i])
+		}
+	}
+
+	if members := resolver.CycleMembers("file:///d.frugal"); len(members) != 0 {
+		t.Errorf("Expected d.frugal not to be in a cycle, got %v", members)
+	}
+	if members := resolver.CycleMembers("file:///c.frugal"); len(members) != 3 {
+		t.Errorf("Expected 3 cycle members, got %v", members)
+	}
+
+	// Breaking the cycle in any file clears it
+	resolver.UpdateDocument(createTestDocument("file:///b.frugal", `struct B {}`))
+	if cycle := resolver.CyclePath("file:///a.frugal", "file:///b.frugal"); cycle != nil {
+		t.Errorf("Expected no cycle after breaking it, got %v", cycle)
+	}
+}
+
+func TestGetTransitiveDependents(t *testing.T) {
+	resolver := NewIncludeResolver([]string{"/workspace"})
+	withDocuments(resolver, "file:///base.frugal", "file:///mid.frugal", "file:///top.frugal")
+
+	resolver.UpdateDocument(createTestDocument("file:///mid.frugal", `include "base.frugal"`))
+	resolver.UpdateDocument(createTestDocument("file:///top.frugal", `include "mid.frugal"`))
+	resolver.UpdateDocument(createTestDocument("file:///other.frugal", `include "base.frugal"`))
+
+	dependents := resolver.GetTransitiveDependents("file:///base.frugal")
+	sort.Strings(dependents)
+
+	expected := []string{"file:///mid.frugal", "file:///other.frugal", "file:///top.frugal"}
+	if len(dependents) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, dependents)
+	}
+	for i := range expected {
+		if dependents[i] != expected[i] {
+			t.Errorf("Dependent %d: expected %s, got %s", i, expected[i], dependents[i])
+		}
+	}
+}
@@ -183,7 +183,7 @@ func (si *SymbolIndex) extractNestedSymbols(containerSymbol ast.Symbol, doc *doc
 		nested = append(nested, si.extractServiceSymbols(containerSymbol, doc, containerName)...)
 	case ast.NodeTypeScope:
 		nested = append(nested, si.extractScopeSymbols(containerSymbol, doc, containerName)...)
-	case ast.NodeTypeStruct, ast.NodeTypeException:
+	case ast.NodeTypeStruct, ast.NodeTypeException, ast.NodeTypeUnion:
 		nested = append(nested, si.extractStructSymbols(containerSymbol, doc, containerName)...)
 	case ast.NodeTypeEnum:
 		nested = append(nested, si.extractEnumSymbols(containerSymbol, doc, containerName)...)
@@ -216,14 +216,36 @@ func (si *SymbolIndex) extractServiceSymbols(serviceSymbol ast.Symbol, doc *docu
 	return methods
 }
 
-// extractScopeSymbols extracts event symbols from a scope
+// extractScopeSymbols extracts prefix variable and event symbols from a scope
 func (si *SymbolIndex) extractScopeSymbols(scopeSymbol ast.Symbol, doc *document.Document, containerName string) []IndexedSymbol {
-	var events []IndexedSymbol
+	var symbols []IndexedSymbol
+
+	// Prefix variables come before the events, as in the source
+	if scope := doc.GetModel().FindScope(scopeSymbol.Name); scope != nil {
+		for _, segment := range scope.PrefixSegments {
+			if !segment.Variable {
+				continue
+			}
+			symbols = append(symbols, IndexedSymbol{
+				Symbol: ast.Symbol{
+					Name: segment.Text,
+					Type: ast.NodeTypePrefixVariable,
+					Line: segment.Range.Start.Line,
+					// Skip the opening brace so the symbol range covers the name
+					Column: segment.Range.Start.Column + 1,
+					Node:   scopeSymbol.Node,
+				},
+				URI:           doc.URI,
+				ContainerName: containerName,
+				FullName:      containerName + "." + segment.Text,
+			})
+		}
+	}
 
 	// Find scope body and extract events
 	scopeBody := ast.FindNodeByType(scopeSymbol.Node, "scope_body")
 	if scopeBody == nil {
-		return events
+		return symbols
 	}
 
 	childCount := scopeBody.ChildCount()
@@ -231,11 +253,11 @@ func (si *SymbolIndex) extractScopeSymbols(scopeSymbol ast.Symbol, doc *document
 		child := scopeBody.Child(i)
 		eventSymbol := si.extractEventSymbol(child, doc.Content, containerName, doc.URI)
 		if eventSymbol != nil {
-			events = append(events, *eventSymbol)
+			symbols = append(symbols, *eventSymbol)
 		}
 	}
 
-	return events
+	return symbols
 }
 
 // extractStructSymbols extracts field symbols from a struct
@@ -693,7 +715,7 @@ func (si *SymbolIndex) getSymbolKind(nodeType ast.NodeType) protocol.SymbolKind
 		return protocol.SymbolKindClass
 	case ast.NodeTypeScope:
 		return protocol.SymbolKindClass
-	case ast.NodeTypeStruct:
+	case ast.NodeTypeStruct, ast.NodeTypeUnion:
 		return protocol.SymbolKindStruct
 	case ast.NodeTypeEnum:
 		return protocol.SymbolKindEnum
@@ -713,6 +735,8 @@ func (si *SymbolIndex) getSymbolKind(nodeType ast.NodeType) protocol.SymbolKind
 		return protocol.SymbolKindField
 	case ast.NodeTypeEvent:
 		return protocol.SymbolKindEvent
+	case ast.NodeTypePrefixVariable:
+		return protocol.SymbolKindVariable
 	case ast.NodeTypeEnumValue:
 		return protocol.SymbolKindEnumMember
 	default:
@@ -3,6 +3,8 @@ package workspace
 import (
 	"testing"
 
+	protocol "github.com/tliron/glsp/protocol_3_16"
+
 	"frugal-ls/internal/document"
 	"frugal-ls/internal/parser"
 	"frugal-ls/pkg/ast"
@@ -391,3 +393,27 @@ func createTestDocumentForIndex(uri, content string) (*document.Document, error)
 
 	return doc, nil
 }
+
+func TestSymbolIndexScopePrefixVariables(t *testing.T) {
+	index := NewSymbolIndex()
+
+	content := `scope UserEvents prefix "user.{region}" {
+    Created: string
+}`
+
+	doc, err := createTestDocumentForIndex("file:///events.frugal", content)
+	if err != nil {
+		t.Fatalf("Failed to create document: %v", err)
+	}
+	defer doc.ParseResult.Close()
+
+	index.UpdateDocument(doc)
+
+	variables := index.SearchByType([]ast.NodeType{ast.NodeTypePrefixVariable}, "", 10)
+	if len(variables) != 1 || variables[0].Name != "region" || variables[0].Kind != protocol.SymbolKindVariable {
+		t.Fatalf("Expected prefix variable region, got %+v", variables)
+	}
+	if start := variables[0].Location.Range.Start; start.Line != 0 || start.Character != 31 {
+		t.Errorf("Expected the variable name's position, got %+v", start)
+	}
+}
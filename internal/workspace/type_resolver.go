@@ -0,0 +1,414 @@
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	protocol "github.com/tliron/glsp/protocol_3_16"
+
+	"frugal-ls/internal/document"
+	"frugal-ls/pkg/ast"
+)
+
+var (
+	// ErrUnknownInclude is returned when a type uses a prefix that no include declares
+	ErrUnknownInclude = errors.New("unknown include")
+	// ErrIncludeNotLoaded is returned when the included file is not available to the resolver
+	ErrIncludeNotLoaded = errors.New("included file not loaded")
+	// ErrUndefinedType is returned when no declaration matches the referenced name
+	ErrUndefinedType = errors.New("undefined type")
+	// ErrNotService is returned when a service extends a declaration that is not a service
+	ErrNotService = errors.New("not a service")
+	// ErrExtendsCycle is returned when a service's extends chain leads back into itself
+	ErrExtendsCycle = errors.New("extends cycle")
+)
+
+// DocumentSource provides access to parsed documents by URI
+type DocumentSource interface {
+	GetDocument(uri string) (*document.Document, bool)
+	GetAllDocuments() map[string]*document.Document
+}
+
+// Declaration is a top-level definition bound to the document that declares it
+type Declaration struct {
+	URI       string
+	Name      string
+	Kind      ast.NodeType
+	Range     ast.Range
+	NameRange ast.Range
+}
+
+// Location returns the protocol location of the declaration's name
+func (d *Declaration) Location() protocol.Location {
+	return protocol.Location{
+		URI:   d.URI,
+		Range: toProtocolRange(d.NameRange),
+	}
+}
+
+// Reference is a single use or declaration of a symbol
+type Reference struct {
+	URI string
+	// Range covers the reference as written, including any include prefix
+	Range ast.Range
+	// NameRange covers only the referenced name and is what renames replace
+	NameRange ast.Range
+	// IsDeclaration marks the declaring occurrence
+	IsDeclaration bool
+}
+
+// Location returns the protocol location of the reference
+func (r Reference) Location() protocol.Location {
+	return protocol.Location{
+		URI:   r.URI,
+		Range: toProtocolRange(r.Range),
+	}
+}
+
+// TypeResolver binds type references to declarations through the include graph
+type TypeResolver struct {
+	includes *IncludeResolver
+	docs     DocumentSource
+}
+
+// NewTypeResolver creates a type resolver over the given include graph and documents
+func NewTypeResolver(includes *IncludeResolver, docs DocumentSource) *TypeResolver {
+	return &TypeResolver{
+		includes: includes,
+		docs:     docs,
+	}
+}
+
+// ResolveTypeRef resolves a user type reference in the document at uri to its declaration
+func (r *TypeResolver) ResolveTypeRef(uri string, ref *ast.TypeRef) (*Declaration, error) {
+	if ref == nil || ref.Kind != ast.TypeRefUser {
+		return nil, fmt.Errorf("%w: not a user-defined type", ErrUndefinedType)
+	}
+	return r.resolveName(uri, ref.Include, ref.Name)
+}
+
+// ResolveUnderlying follows typedef chains until it reaches a type that is not a typedef.
+// It returns the final type reference and the URI of the document it was written in.
+func (r *TypeResolver) ResolveUnderlying(uri string, ref *ast.TypeRef) (*ast.TypeRef, string, error) {
+	visited := make(map[string]bool)
+
+	for ref != nil && ref.Kind == ast.TypeRefUser {
+		decl, err := r.ResolveTypeRef(uri, ref)
+		if err != nil {
+			return nil, "", err
+		}
+		if decl.Kind != ast.NodeTypeTypedef {
+			return ref, uri, nil
+		}
+
+		key := decl.URI + "#" + decl.Name
+		if visited[key] {
+			return nil, "", fmt.Errorf("typedef cycle through '%s'", decl.Name)
+		}
+		visited[key] = true
+
+		typedef := r.model(decl.URI).FindTypedef(decl.Name)
+		if typedef == nil {
+			return nil, "", fmt.Errorf("%w: %s", ErrUndefinedType, decl.Name)
+		}
+		ref, uri = typedef.Type, decl.URI
+	}
+
+	return ref, uri, nil
+}
+
+// ServiceAncestor is a service inherited from, with the document that declares it
+type ServiceAncestor struct {
+	URI     string
+	Service *ast.Service
+}
+
+// Ancestors returns the services a service in the document at uri inherits from, nearest
+// first. If an extends clause can't be followed it returns the ancestors found so far
+// along with the reason.
+func (r *TypeResolver) Ancestors(uri string, service *ast.Service) ([]ServiceAncestor, error) {
+	var ancestors []ServiceAncestor
+	visited := map[string]bool{uri + "#" + service.Name: true}
+
+	for service.Extends != "" {
+		decl, err := r.ResolveTypeRef(uri, extendsTypeRef(service))
+		if err != nil {
+			return ancestors, err
+		}
+		if decl.Kind != ast.NodeTypeService {
+			return ancestors, fmt.Errorf("%w: %s is a %s", ErrNotService, decl.Name, decl.Kind)
+		}
+
+		key := decl.URI + "#" + decl.Name
+		if visited[key] {
+			return ancestors, fmt.Errorf("%w: %s", ErrExtendsCycle, decl.Name)
+		}
+		visited[key] = true
+
+		uri, service = decl.URI, r.model(decl.URI).FindService(decl.Name)
+		ancestors = append(ancestors, ServiceAncestor{URI: uri, Service: service})
+	}
+
+	return ancestors, nil
+}
+
+// Resolve returns the declaration for the symbol at the given position.
+// The position may be on a type reference, a service's extends clause or a declaration name.
+func (r *TypeResolver) Resolve(uri string, position protocol.Position) (*Declaration, error) {
+	model := r.model(uri)
+	line, column := int(position.Line), int(position.Character)
+
+	if ref := innermostUserRef(model, line, column); ref != nil {
+		return r.ResolveTypeRef(uri, ref)
+	}
+
+	for _, value := range identifierValues(model) {
+		if value.Range.Contains(line, column) {
+			decl, _, err := r.resolveValue(uri, value)
+			return decl, err
+		}
+	}
+
+	for _, service := range model.Services {
+		if service.Extends != "" && service.ExtendsRange.Contains(line, column) {
+			return r.ResolveTypeRef(uri, extendsTypeRef(service))
+		}
+	}
+
+	for _, def := range model.Definitions() {
+		if def.NameRange.Contains(line, column) {
+			return &Declaration{
+				URI:       uri,
+				Name:      def.Name,
+				Kind:      def.Type,
+				Range:     def.Range,
+				NameRange: def.NameRange,
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// References returns every reference to the declaration across all known documents
+func (r *TypeResolver) References(decl *Declaration, includeDeclaration bool) []Reference {
+	var refs []Reference
+
+	if includeDeclaration {
+		refs = append(refs, Reference{
+			URI:           decl.URI,
+			Range:         decl.NameRange,
+			NameRange:     decl.NameRange,
+			IsDeclaration: true,
+		})
+	}
+
+	for uri, doc := range r.docs.GetAllDocuments() {
+		if !doc.IsValidFrugalFile() {
+			continue
+		}
+		for _, ref := range userTypeRefs(doc.GetModel()) {
+			if ref.Name != decl.Name {
+				continue
+			}
+			target, err := r.ResolveTypeRef(uri, ref)
+			if err != nil || target.URI != decl.URI || target.Name != decl.Name {
+				continue
+			}
+			refs = append(refs, Reference{
+				URI:       uri,
+				Range:     ref.Range,
+				NameRange: nameRange(ref),
+			})
+		}
+		for _, value := range identifierValues(doc.GetModel()) {
+			target, name, err := r.resolveValue(uri, value)
+			if err != nil || target.URI != decl.URI || target.Name != decl.Name {
+				continue
+			}
+			refs = append(refs, Reference{
+				URI:       uri,
+				Range:     ast.Range{Start: value.Range.Start, End: name.End},
+				NameRange: name,
+			})
+		}
+	}
+
+	return refs
+}
+
+// resolveName looks up a possibly include-prefixed name from the document at uri
+func (r *TypeResolver) resolveName(uri, include, name string) (*Declaration, error) {
+	targetURI := uri
+
+	if include != "" {
+		inc := r.model(uri).FindInclude(include)
+		if inc == nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownInclude, include)
+		}
+
+		resolved, err := r.includes.ResolveInclude(uri, inc.Path)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrIncludeNotLoaded, inc.Path)
+		}
+		if _, exists := r.docs.GetDocument(resolved); !exists {
+			return nil, fmt.Errorf("%w: %s", ErrIncludeNotLoaded, inc.Path)
+		}
+		targetURI = resolved
+	}
+
+	def := r.model(targetURI).FindDefinition(name)
+	if def == nil {
+		if include != "" {
+			return nil, fmt.Errorf("%w: %s.%s", ErrUndefinedType, include, name)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrUndefinedType, name)
+	}
+
+	return &Declaration{
+		URI:       targetURI,
+		Name:      def.Name,
+		Kind:      def.Type,
+		Range:     def.Range,
+		NameRange: def.NameRange,
+	}, nil
+}
+
+// resolveValue binds an identifier constant value such as MAX, Status.ACTIVE or
+// common.Status.ACTIVE to the const or enum it names. It also returns the range of
+// the declaration's name within the value.
+func (r *TypeResolver) resolveValue(uri string, value *ast.ConstValue) (*Declaration, ast.Range, error) {
+	parts := strings.Split(value.Text, ".")
+	include := ""
+	offset := 0
+	if len(parts) > 1 && r.model(uri).FindInclude(parts[0]) != nil {
+		include = parts[0]
+		offset = len(parts[0]) + 1
+		parts = parts[1:]
+	}
+
+	decl, err := r.resolveName(uri, include, parts[0])
+	if err != nil {
+		return nil, ast.Range{}, err
+	}
+
+	name := value.Range
+	name.Start.Column += offset
+	name.End = ast.Position{Line: name.Start.Line, Column: name.Start.Column + len(parts[0])}
+	return decl, name, nil
+}
+
+// Model returns the typed model for a document, or an empty model if it is unknown
+func (r *TypeResolver) Model(uri string) *ast.File {
+	return r.model(uri)
+}
+
+// model returns the typed model for a document, or an empty model if it is unknown
+func (r *TypeResolver) model(uri string) *ast.File {
+	doc, exists := r.docs.GetDocument(uri)
+	if !exists {
+		return &ast.File{}
+	}
+	return doc.GetModel()
+}
+
+// userTypeRefs returns all user type references in a file, including service extends clauses
+func userTypeRefs(model *ast.File) []*ast.TypeRef {
+	var refs []*ast.TypeRef
+	for _, ref := range model.TypeRefs() {
+		if ref.Kind == ast.TypeRefUser {
+			refs = append(refs, ref)
+		}
+	}
+	for _, service := range model.Services {
+		if service.Extends != "" {
+			refs = append(refs, extendsTypeRef(service))
+		}
+	}
+	return refs
+}
+
+// identifierValues returns every identifier constant value in a file, including list and
+// map elements, in const definitions and field defaults
+func identifierValues(model *ast.File) []*ast.ConstValue {
+	var values []*ast.ConstValue
+	var collect func(value *ast.ConstValue)
+	collect = func(value *ast.ConstValue) {
+		if value == nil {
+			return
+		}
+		if value.Kind == ast.ConstValueIdentifier {
+			values = append(values, value)
+		}
+		for _, elem := range value.Elements {
+			collect(elem)
+		}
+		for _, entry := range value.Entries {
+			collect(entry.Key)
+			collect(entry.Value)
+		}
+	}
+	collectFields := func(fields []*ast.Field) {
+		for _, field := range fields {
+			collect(field.Default)
+		}
+	}
+
+	for _, c := range model.Consts {
+		collect(c.Value)
+	}
+	for _, s := range model.Structs {
+		collectFields(s.Fields)
+	}
+	for _, service := range model.Services {
+		for _, method := range service.Methods {
+			collectFields(method.Params)
+			collectFields(method.Throws)
+		}
+	}
+	return values
+}
+
+// innermostUserRef finds the most deeply nested user type reference at a position
+func innermostUserRef(model *ast.File, line, column int) *ast.TypeRef {
+	var found *ast.TypeRef
+	for _, ref := range model.TypeRefs() {
+		if ref.Kind == ast.TypeRefUser && ref.Range.Contains(line, column) {
+			found = ref
+		}
+	}
+	return found
+}
+
+// extendsTypeRef converts a service's extends clause into a type reference
+func extendsTypeRef(service *ast.Service) *ast.TypeRef {
+	ref := &ast.TypeRef{
+		Kind:  ast.TypeRefUser,
+		Text:  service.Extends,
+		Name:  service.Extends,
+		Range: service.ExtendsRange,
+	}
+	if idx := strings.LastIndex(service.Extends, "."); idx >= 0 {
+		ref.Include = service.Extends[:idx]
+		ref.Name = service.Extends[idx+1:]
+	}
+	return ref
+}
+
+// nameRange returns the range of a type reference without its include prefix
+func nameRange(ref *ast.TypeRef) ast.Range {
+	r := ref.Range
+	if ref.Include != "" && r.Start.Line == r.End.Line {
+		r.Start.Column = r.End.Column - len(ref.Name)
+	}
+	return r
+}
+
+// toProtocolRange converts a model range into a protocol range
+func toProtocolRange(r ast.Range) protocol.Range {
+	return protocol.Range{
+		Start: protocol.Position{Line: uint32(r.Start.Line), Character: uint32(r.Start.Column)},
+		End:   protocol.Position{Line: uint32(r.End.Line), Character: uint32(r.End.Column)},
+	}
+}
@@ -0,0 +1,278 @@
+package workspace
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	protocol "github.com/tliron/glsp/protocol_3_16"
+
+	"frugal-ls/internal/document"
+	"frugal-ls/pkg/ast"
+)
+
+// testDocumentSource is a map-backed DocumentSource for tests
+type testDocumentSource map[string]*document.Document
+
+func (s testDocumentSource) GetDocument(uri string) (*document.Document, bool) {
+	doc, exists := s[uri]
+	return doc, exists
+}
+
+func (s testDocumentSource) GetAllDocuments() map[string]*document.Document {
+	return s
+}
+
+// newTestTypeResolver writes the files to a temporary workspace and returns a resolver over them
+func newTestTypeResolver(t *testing.T, files map[string]string) (*TypeResolver, testDocumentSource, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	source := make(testDocumentSource)
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		uri := pathToURI(path)
+		source[uri] = createTestDocument(uri, content)
+	}
+
+	return NewTypeResolver(NewIncludeResolver([]string{dir}), source), source, dir
+}
+
+const typeResolverCommon = `typedef i64 UserId
+typedef UserId AccountId
+
+enum Status {
+    ACTIVE = 1
+}
+
+struct User {
+    1: UserId id
+}
+
+service BaseService {
+    void ping()
+}`
+
+const typeResolverMain = `include "common.frugal"
+
+const common.Status DEFAULT_STATUS = common.Status.ACTIVE
+
+struct Account {
+    1: common.AccountId id,
+    2: list<common.User> members,
+    3: Local local
+}
+
+struct Local {
+    1: string name
+}
+
+service AccountService extends common.BaseService {
+    common.User getOwner(1: common.AccountId id)
+}`
+
+func TestResolveTypeRef(t *testing.T) {
+	resolver, _, dir := newTestTypeResolver(t, map[string]string{
+		"common.frugal": typeResolverCommon,
+		"main.frugal":   typeResolverMain,
+	})
+	mainURI := pathToURI(filepath.Join(dir, "main.frugal"))
+	commonURI := pathToURI(filepath.Join(dir, "common.frugal"))
+
+	account := resolver.model(mainURI).FindStruct("Account")
+	if account == nil {
+		t.Fatal("Expected Account struct in model")
+	}
+
+	decl, err := resolver.ResolveTypeRef(mainURI, account.Fields[0].Type)
+	if err != nil || decl.URI != commonURI || decl.Name != "AccountId" || decl.Kind != ast.NodeTypeTypedef {
+		t.Errorf("Expected common.AccountId typedef, got %+v (%v)", decl, err)
+	}
+
+	decl, err = resolver.ResolveTypeRef(mainURI, account.Fields[1].Type.Elem)
+	if err != nil || decl.URI != commonURI || decl.Name != "User" {
+		t.Errorf("Expected list element to resolve to common.User, got %+v (%v)", decl, err)
+	}
+
+	decl, err = resolver.ResolveTypeRef(mainURI, account.Fields[2].Type)
+	if err != nil || decl.URI != mainURI || decl.Name != "Local" {
+		t.Errorf("Expected local struct, got %+v (%v)", decl, err)
+	}
+
+	missing := &ast.TypeRef{Kind: ast.TypeRefUser, Include: "common", Name: "Missing"}
+	if _, err := resolver.ResolveTypeRef(mainURI, missing); !errors.Is(err, ErrUndefinedType) {
+		t.Errorf("Expected ErrUndefinedType, got %v", err)
+	}
+
+	unknown := &ast.TypeRef{Kind: ast.TypeRefUser, Include: "other", Name: "User"}
+	if _, err := resolver.ResolveTypeRef(mainURI, unknown); !errors.Is(err, ErrUnknownInclude) {
+		t.Errorf("Expected ErrUnknownInclude, got %v", err)
+	}
+}
+
+func TestResolveTypeRefIncludeNotLoaded(t *testing.T) {
+	resolver, source, dir := newTestTypeResolver(t, map[string]string{
+		"common.frugal": typeResolverCommon,
+		"main.frugal":   typeResolverMain,
+	})
+	delete(source, pathToURI(filepath.Join(dir, "common.frugal")))
+
+	ref := &ast.TypeRef{Kind: ast.TypeRefUser, Include: "common", Name: "User"}
+	if _, err := resolver.ResolveTypeRef(pathToURI(filepath.Join(dir, "main.frugal")), ref); !errors.Is(err, ErrIncludeNotLoaded) {
+		t.Errorf("Expected ErrIncludeNotLoaded, got %v", err)
+	}
+}
+
+func TestResolveUnderlying(t *testing.T) {
+	resolver, _, dir := newTestTypeResolver(t, map[string]string{
+		"common.frugal": typeResolverCommon,
+		"main.frugal":   typeResolverMain,
+	})
+	mainURI := pathToURI(filepath.Join(dir, "main.frugal"))
+
+	ref := resolver.model(mainURI).FindStruct("Account").Fields[0].Type
+	underlying, uri, err := resolver.ResolveUnderlying(mainURI, ref)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if underlying.Kind != ast.TypeRefBase || underlying.Name != "i64" {
+		t.Errorf("Expected i64 through typedef chain, got %+v", underlying)
+	}
+	if uri != pathToURI(filepath.Join(dir, "common.frugal")) {
+		t.Errorf("Expected underlying type from common.frugal, got %s", uri)
+	}
+}
+
+func TestResolveUnderlyingCycle(t *testing.T) {
+	resolver, _, dir := newTestTypeResolver(t, map[string]string{
+		"cycle.frugal": "typedef B A\ntypedef A B",
+	})
+	uri := pathToURI(filepath.Join(dir, "cycle.frugal"))
+
+	ref := resolver.model(uri).FindTypedef("A").Type
+	if _, _, err := resolver.ResolveUnderlying(uri, ref); err == nil {
+		t.Error("Expected error for typedef cycle")
+	}
+}
+
+func TestAncestors(t *testing.T) {
+	resolver, _, dir := newTestTypeResolver(t, map[string]string{
+		"common.frugal": typeResolverCommon,
+		"main.frugal": typeResolverMain + `
+
+service AuditService extends AccountService {
+    void audit()
+}
+
+struct Plain {
+    1: string name
+}
+
+service Broken extends Plain {}
+
+service Loop extends Loop {}`,
+	})
+	mainURI := pathToURI(filepath.Join(dir, "main.frugal"))
+	model := resolver.model(mainURI)
+
+	ancestors, err := resolver.Ancestors(mainURI, model.FindService("AuditService"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(ancestors) != 2 || ancestors[0].Service.Name != "AccountService" || ancestors[1].Service.Name != "BaseService" {
+		t.Fatalf("Expected AccountService then BaseService, got %+v", ancestors)
+	}
+	if ancestors[1].URI != pathToURI(filepath.Join(dir, "common.frugal")) {
+		t.Errorf("Expected BaseService from common.frugal, got %s", ancestors[1].URI)
+	}
+
+	if _, err := resolver.Ancestors(mainURI, model.FindService("Broken")); !errors.Is(err, ErrNotService) {
+		t.Errorf("Expected ErrNotService, got %v", err)
+	}
+	if _, err := resolver.Ancestors(mainURI, model.FindService("Loop")); !errors.Is(err, ErrExtendsCycle) {
+		t.Errorf("Expected ErrExtendsCycle, got %v", err)
+	}
+}
+
+func TestResolveAtPosition(t *testing.T) {
+	resolver, _, dir := newTestTypeResolver(t, map[string]string{
+		"common.frugal": typeResolverCommon,
+		"main.frugal":   typeResolverMain,
+	})
+	mainURI := pathToURI(filepath.Join(dir, "main.frugal"))
+
+	testCases := []struct {
+		name     string
+		position protocol.Position
+		expected string
+	}{
+		{"prefixed field type", protocol.Position{Line: 5, Character: 15}, "AccountId"},
+		{"list element type", protocol.Position{Line: 6, Character: 20}, "User"},
+		{"extends clause", protocol.Position{Line: 14, Character: 38}, "BaseService"},
+		{"enum value in const", protocol.Position{Line: 2, Character: 45}, "Status"},
+		{"declaration name", protocol.Position{Line: 10, Character: 8}, "Local"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			decl, err := resolver.Resolve(mainURI, tc.position)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if decl == nil || decl.Name != tc.expected {
+				t.Errorf("Expected %s, got %+v", tc.expected, decl)
+			}
+		})
+	}
+
+	if decl, _ := resolver.Resolve(mainURI, protocol.Position{Line: 1, Character: 0}); decl != nil {
+		t.Errorf("Expected no declaration on blank line, got %+v", decl)
+	}
+}
+
+func TestTypeResolverReferences(t *testing.T) {
+	resolver, _, dir := newTestTypeResolver(t, map[string]string{
+		"common.frugal": typeResolverCommon,
+		"main.frugal":   typeResolverMain,
+	})
+	mainURI := pathToURI(filepath.Join(dir, "main.frugal"))
+	commonURI := pathToURI(filepath.Join(dir, "common.frugal"))
+
+	decl, err := resolver.Resolve(commonURI, protocol.Position{Line: 7, Character: 8})
+	if err != nil || decl == nil || decl.Name != "User" {
+		t.Fatalf("Expected User declaration, got %+v (%v)", decl, err)
+	}
+
+	refs := resolver.References(decl, true)
+	// declaration, list<common.User>, getOwner return type
+	if len(refs) != 3 {
+		t.Fatalf("Expected 3 references, got %d: %+v", len(refs), refs)
+	}
+	if !refs[0].IsDeclaration || refs[0].URI != commonURI {
+		t.Errorf("Expected declaration first, got %+v", refs[0])
+	}
+
+	for _, ref := range refs[1:] {
+		if ref.URI != mainURI {
+			t.Errorf("Expected reference in main.frugal, got %s", ref.URI)
+		}
+		// Renames only replace the name after the include prefix
+		width := ref.NameRange.End.Column - ref.NameRange.Start.Column
+		if width != len("User") {
+			t.Errorf("Expected name range to cover 'User', got %+v", ref.NameRange)
+		}
+	}
+
+	status, _ := resolver.Resolve(commonURI, protocol.Position{Line: 3, Character: 6})
+	if status == nil || status.Name != "Status" {
+		t.Fatalf("Expected Status declaration, got %+v", status)
+	}
+	// const type and enum value reference
+	if refs := resolver.References(status, false); len(refs) != 2 {
+		t.Errorf("Expected 2 references to Status, got %d: %+v", len(refs), refs)
+	}
+}
@@ -25,6 +25,8 @@ const (
 	NodeTypeTypedef NodeType = "typedef"
 	// NodeTypeException represents an exception definition node
 	NodeTypeException NodeType = "exception"
+	// NodeTypeUnion represents a union definition node
+	NodeTypeUnion NodeType = "union"
 	// NodeTypeInclude represents an include directive node
 	NodeTypeInclude NodeType = "include"
 	// NodeTypeNamespace represents a namespace declaration node
@@ -37,6 +39,8 @@ const (
 	NodeTypeEvent NodeType = "event"
 	// NodeTypeEnumValue represents an enum value definition node
 	NodeTypeEnumValue NodeType = "enum_value"
+	// NodeTypePrefixVariable represents a {variable} placeholder in a scope prefix
+	NodeTypePrefixVariable NodeType = "prefix_variable"
 )
 
 // Symbol represents a symbol in the Frugal AST
@@ -135,6 +139,10 @@ func extractSymbolsRecursive(node *tree_sitter.Node, source []byte, symbols *[]S
 		if symbol := extractExceptionSymbol(node, source); symbol != nil {
 			*symbols = append(*symbols, *symbol)
 		}
+	case "union_definition":
+		if symbol := extractUnionSymbol(node, source); symbol != nil {
+			*symbols = append(*symbols, *symbol)
+		}
 	}
 
 	// Recursively process child nodes
@@ -226,7 +234,8 @@ func extractEnumSymbol(node *tree_sitter.Node, source []byte) *Symbol {
 }
 
 func extractConstSymbol(node *tree_sitter.Node, source []byte) *Symbol {
-	nameNode := FindNodeByType(node, "identifier")
+	// The type may be a user type with its own identifier, so only direct children are names
+	nameNode := firstChild(node, "identifier")
 	if nameNode == nil {
 		return nil
 	}
@@ -246,7 +255,8 @@ func extractConstSymbol(node *tree_sitter.Node, source []byte) *Symbol {
 }
 
 func extractTypedefSymbol(node *tree_sitter.Node, source []byte) *Symbol {
-	nameNode := FindNodeByType(node, "identifier")
+	// The type may be a user type with its own identifier, so only direct children are names
+	nameNode := firstChild(node, "identifier")
 	if nameNode == nil {
 		return nil
 	}
@@ -285,6 +295,26 @@ func extractExceptionSymbol(node *tree_sitter.Node, source []byte) *Symbol {
 	}
 }
 
+func extractUnionSymbol(node *tree_sitter.Node, source []byte) *Symbol {
+	nameNode := FindNodeByType(node, "identifier")
+	if nameNode == nil {
+		return nil
+	}
+
+	name := GetText(nameNode, source)
+	point := node.StartPosition()
+
+	return &Symbol{
+		Name:     name,
+		Type:     NodeTypeUnion,
+		Line:     int(point.Row),
+		Column:   int(point.Column),
+		StartPos: node.StartByte(),
+		EndPos:   node.EndByte(),
+		Node:     node,
+	}
+}
+
 // PrintTree prints the AST tree for debugging purposes
 func PrintTree(node *tree_sitter.Node, source []byte, indent int) {
 	if node == nil {
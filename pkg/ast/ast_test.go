@@ -24,6 +24,7 @@ func TestNodeTypeConstants(t *testing.T) {
 		NodeTypeConst:     "const",
 		NodeTypeTypedef:   "typedef",
 		NodeTypeException: "exception",
+		NodeTypeUnion:     "union",
 		NodeTypeInclude:   "include",
 		NodeTypeNamespace: "namespace",
 		NodeTypeMethod:    "method",
@@ -971,3 +972,49 @@ func TestGetTextEdgeCasesExtended(t *testing.T) {
 		t.Errorf("Expected empty string for nil node with empty source, got %q", emptyResult)
 	}
 }
+
+func TestExtractSymbolsUserTypedNames(t *testing.T) {
+	content := `const common.Status DEFAULT = common.Status.ACTIVE
+typedef common.User Owner`
+
+	p, err := parser.NewParser()
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer p.Close()
+
+	result, err := p.Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	defer result.Close()
+
+	symbols := ExtractSymbols(result.GetRootNode(), []byte(content))
+	if len(symbols) != 2 || symbols[0].Name != "DEFAULT" || symbols[1].Name != "Owner" {
+		t.Errorf("Expected the declared names rather than the types, got %+v", symbols)
+	}
+}
+
+func TestExtractSymbolsUnion(t *testing.T) {
+	content := `union Value {
+    1: string text,
+    2: i64 number
+}`
+
+	p, err := parser.NewParser()
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer p.Close()
+
+	result, err := p.Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	defer result.Close()
+
+	symbols := ExtractSymbols(result.GetRootNode(), []byte(content))
+	if len(symbols) != 1 || symbols[0].Name != "Value" || symbols[0].Type != NodeTypeUnion {
+		t.Errorf("Expected union symbol Value, got %+v", symbols)
+	}
+}
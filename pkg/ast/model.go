@@ -0,0 +1,1372 @@
+package ast
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// Position is a zero-based line/column location in a document
+type Position struct {
+	Line   int
+	Column int
+}
+
+// Range is a half-open span between two positions
+type Range struct {
+	Start Position
+	End   Position
+}
+
+// Contains reports whether the position lies within the range (end inclusive)
+func (r Range) Contains(line, column int) bool {
+	if line < r.Start.Line || line > r.End.Line {
+		return false
+	}
+	if line == r.Start.Line && column < r.Start.Column {
+		return false
+	}
+	if line == r.End.Line && column > r.End.Column {
+		return false
+	}
+	return true
+}
+
+// NodeRange returns the range covered by a tree-sitter node
+func NodeRange(node *tree_sitter.Node) Range {
+	if node == nil {
+		return Range{}
+	}
+	start := node.StartPosition()
+	end := node.EndPosition()
+	return Range{
+		Start: Position{Line: int(start.Row), Column: int(start.Column)},
+		End:   Position{Line: int(end.Row), Column: int(end.Column)},
+	}
+}
+
+// TypeRefKind classifies a type reference
+type TypeRefKind string
+
+const (
+	// TypeRefBase is a built-in base type such as i32 or string
+	TypeRefBase TypeRefKind = "base"
+	// TypeRefUser is a reference to a user-defined type, possibly include-prefixed
+	TypeRefUser TypeRefKind = "user"
+	// TypeRefList is a list<T> container
+	TypeRefList TypeRefKind = "list"
+	// TypeRefSet is a set<T> container
+	TypeRefSet TypeRefKind = "set"
+	// TypeRefMap is a map<K, V> container
+	TypeRefMap TypeRefKind = "map"
+	// TypeRefVoid is the void return type of a method
+	TypeRefVoid TypeRefKind = "void"
+)
+
+// TypeRef is a reference to a type as written in the source
+type TypeRef struct {
+	Kind TypeRefKind
+	// Text is the type exactly as written, e.g. "map<string, common.User>"
+	Text string
+	// Name is the base type keyword or the user type name without include prefix
+	Name string
+	// Include is the include prefix of a user type ("common" in "common.User")
+	Include string
+	// Elem is the element type of list and set containers
+	Elem *TypeRef
+	// Key and Value are the type parameters of map containers
+	Key   *TypeRef
+	Value *TypeRef
+	Range Range
+}
+
+// QualifiedName returns the user type name including its include prefix
+func (t *TypeRef) QualifiedName() string {
+	if t.Include == "" {
+		return t.Name
+	}
+	return t.Include + "." + t.Name
+}
+
+// Walk calls fn for the type reference and every nested type reference
+func (t *TypeRef) Walk(fn func(*TypeRef)) {
+	if t == nil {
+		return
+	}
+	fn(t)
+	t.Elem.Walk(fn)
+	t.Key.Walk(fn)
+	t.Value.Walk(fn)
+}
+
+// ConstValueKind classifies a constant value literal
+type ConstValueKind string
+
+const (
+	// ConstValueInteger is an integer literal
+	ConstValueInteger ConstValueKind = "integer"
+	// ConstValueDouble is a floating point literal
+	ConstValueDouble ConstValueKind = "double"
+	// ConstValueString is a string literal
+	ConstValueString ConstValueKind = "string"
+	// ConstValueIdentifier is a reference to a const, enum value or boolean keyword
+	ConstValueIdentifier ConstValueKind = "identifier"
+	// ConstValueList is a [a, b] literal
+	ConstValueList ConstValueKind = "list"
+	// ConstValueMap is a {k: v} literal
+	ConstValueMap ConstValueKind = "map"
+)
+
+// ConstValue is a literal value used by consts and field defaults
+type ConstValue struct {
+	Kind ConstValueKind
+	Text string
+	// Elements holds list literal items
+	Elements []*ConstValue
+	// Entries holds map literal items
+	Entries []*ConstMapEntry
+	Range   Range
+}
+
+// ConstMapEntry is a single key/value pair in a map literal
+type ConstMapEntry struct {
+	Key   *ConstValue
+	Value *ConstValue
+}
+
+// Include is an include directive
+type Include struct {
+	// Path is the include path without quotes
+	Path string
+	// Name is the prefix used to reference the include's types ("common" for "common.frugal")
+	Name      string
+	Range     Range
+	PathRange Range
+}
+
+// Namespace is a namespace declaration
+type Namespace struct {
+	Scope string
+	Name  string
+	Range Range
+}
+
+// Annotation is a key, optionally with a quoted value, in the parenthesized list
+// following a declaration, e.g. `(go.tag = "json:\"id\"")`
+type Annotation struct {
+	// Key is the annotation name, which may be dotted, e.g. go.tag
+	Key string
+	// Value is the value without quotes
+	Value    string
+	HasValue bool
+	// Range covers the key and its value
+	Range      Range
+	KeyRange   Range
+	ValueRange Range
+}
+
+// Deprecation marks a declaration as deprecated, either with a `deprecated` annotation
+// or an @deprecated tag in the comment above it
+type Deprecation struct {
+	// Message is the annotation value or the text after the tag, if any
+	Message string
+}
+
+// DocComment is the documentation in the comments directly above a declaration, with
+// comment markers removed
+type DocComment struct {
+	// Text is the description before the first tag
+	Text string
+	// Tags are the @tag lines in order, e.g. @param, @throws and @return
+	Tags []*DocTag
+}
+
+// DocTag is an @tag line in a doc comment, e.g. `@param id the user's ID`
+type DocTag struct {
+	// Name is the tag without the @, e.g. param
+	Name string
+	// Target is the parameter or exception described by a @param or @throws tag
+	Target string
+	Text   string
+}
+
+// Field is a struct, exception, parameter or throws field
+type Field struct {
+	ID           int
+	HasID        bool
+	Requiredness string
+	Type         *TypeRef
+	Name         string
+	Default      *ConstValue
+	Range        Range
+	NameRange    Range
+	IDRange      Range
+	Annotations  []*Annotation
+	Deprecated   *Deprecation
+	Doc          *DocComment
+}
+
+// Reserved is a `@reserved` comment retiring field IDs and names that must not be
+// reused, e.g. `// @reserved 4, 7 "oldName"`
+type Reserved struct {
+	IDs   []int
+	Names []string
+	// Range is the range of the comment
+	Range Range
+}
+
+// Struct is a struct, exception or union definition
+type Struct struct {
+	Kind        NodeType
+	Name        string
+	Fields      []*Field
+	Reserved    []*Reserved
+	Range       Range
+	NameRange   Range
+	Annotations []*Annotation
+	Deprecated  *Deprecation
+	Doc         *DocComment
+}
+
+// ReservedID returns the reservation retiring a field ID, or nil if it is not reserved
+func (s *Struct) ReservedID(id int) *Reserved {
+	for _, reserved := range s.Reserved {
+		for _, reservedID := range reserved.IDs {
+			if reservedID == id {
+				return reserved
+			}
+		}
+	}
+	return nil
+}
+
+// ReservedName returns the reservation retiring a field name, or nil if it is not reserved
+func (s *Struct) ReservedName(name string) *Reserved {
+	for _, reserved := range s.Reserved {
+		for _, reservedName := range reserved.Names {
+			if reservedName == name {
+				return reserved
+			}
+		}
+	}
+	return nil
+}
+
+// Method is a service method
+type Method struct {
+	Name        string
+	Oneway      bool
+	ReturnType  *TypeRef
+	Params      []*Field
+	Throws      []*Field
+	Range       Range
+	NameRange   Range
+	Annotations []*Annotation
+	Deprecated  *Deprecation
+	Doc         *DocComment
+}
+
+// Service is a service definition
+type Service struct {
+	Name         string
+	Extends      string
+	ExtendsRange Range
+	Methods      []*Method
+	Range        Range
+	NameRange    Range
+	Annotations  []*Annotation
+	Deprecated   *Deprecation
+	Doc          *DocComment
+}
+
+// FindMethod returns the method declared with the given name, or nil
+func (s *Service) FindMethod(name string) *Method {
+	for _, method := range s.Methods {
+		if method.Name == name {
+			return method
+		}
+	}
+	return nil
+}
+
+// Event is a scope event
+type Event struct {
+	Name        string
+	Type        *TypeRef
+	Range       Range
+	NameRange   Range
+	Annotations []*Annotation
+	Doc         *DocComment
+}
+
+// Scope is a Frugal pub/sub scope definition
+type Scope struct {
+	Name        string
+	Prefix      string
+	PrefixRange Range
+	// PrefixSegments splits the prefix into literal text and {variable} placeholders
+	PrefixSegments []*PrefixSegment
+	// PrefixErrors lists malformed placeholders in the prefix
+	PrefixErrors []*PrefixError
+	Events       []*Event
+	Range        Range
+	NameRange    Range
+	Doc          *DocComment
+}
+
+// PrefixSegment is a literal run or a {variable} placeholder in a scope prefix
+type PrefixSegment struct {
+	// Text is the literal text, or the variable name without braces
+	Text     string
+	Variable bool
+	// Range covers the segment in the document, including a placeholder's braces
+	Range Range
+}
+
+// PrefixError is a malformed placeholder in a scope prefix
+type PrefixError struct {
+	Message string
+	Range   Range
+}
+
+// PrefixVariables returns the names of the prefix's placeholders in order
+func (s *Scope) PrefixVariables() []string {
+	var names []string
+	for _, segment := range s.PrefixSegments {
+		if segment.Variable {
+			names = append(names, segment.Text)
+		}
+	}
+	return names
+}
+
+// Topic returns the topic pattern an event is published on, with prefix variables
+// left as {placeholders}, e.g. "user.{region}.UserEvents.Created"
+func (s *Scope) Topic(event string) string {
+	topic := s.Name + "." + event
+	if s.Prefix != "" {
+		topic = s.Prefix + "." + topic
+	}
+	return topic
+}
+
+// EnumValue is a single enum member
+type EnumValue struct {
+	Name        string
+	Value       int
+	HasValue    bool
+	Range       Range
+	NameRange   Range
+	Annotations []*Annotation
+	Deprecated  *Deprecation
+	Doc         *DocComment
+}
+
+// Enum is an enum definition
+type Enum struct {
+	Name        string
+	Values      []*EnumValue
+	Range       Range
+	NameRange   Range
+	Annotations []*Annotation
+	Deprecated  *Deprecation
+	Doc         *DocComment
+}
+
+// Const is a constant definition
+type Const struct {
+	Name      string
+	Type      *TypeRef
+	Value     *ConstValue
+	Range     Range
+	NameRange Range
+	Doc       *DocComment
+}
+
+// Typedef is a type alias definition
+type Typedef struct {
+	Name        string
+	Type        *TypeRef
+	Range       Range
+	NameRange   Range
+	Annotations []*Annotation
+	Deprecated  *Deprecation
+	Doc         *DocComment
+}
+
+// File is the typed model of a parsed Frugal document
+type File struct {
+	Includes   []*Include
+	Namespaces []*Namespace
+	Consts     []*Const
+	Typedefs   []*Typedef
+	Enums      []*Enum
+	Structs    []*Struct
+	Services   []*Service
+	Scopes     []*Scope
+}
+
+// Definition describes any top-level named declaration in a file
+type Definition struct {
+	Name      string
+	Type      NodeType
+	Range     Range
+	NameRange Range
+}
+
+// Definitions returns every top-level declaration in source order
+func (f *File) Definitions() []Definition {
+	var defs []Definition
+	for _, c := range f.Consts {
+		defs = append(defs, Definition{Name: c.Name, Type: NodeTypeConst, Range: c.Range, NameRange: c.NameRange})
+	}
+	for _, t := range f.Typedefs {
+		defs = append(defs, Definition{Name: t.Name, Type: NodeTypeTypedef, Range: t.Range, NameRange: t.NameRange})
+	}
+	for _, e := range f.Enums {
+		defs = append(defs, Definition{Name: e.Name, Type: NodeTypeEnum, Range: e.Range, NameRange: e.NameRange})
+	}
+	for _, s := range f.Structs {
+		defs = append(defs, Definition{Name: s.Name, Type: s.Kind, Range: s.Range, NameRange: s.NameRange})
+	}
+	for _, s := range f.Services {
+		defs = append(defs, Definition{Name: s.Name, Type: NodeTypeService, Range: s.Range, NameRange: s.NameRange})
+	}
+	for _, s := range f.Scopes {
+		defs = append(defs, Definition{Name: s.Name, Type: NodeTypeScope, Range: s.Range, NameRange: s.NameRange})
+	}
+	sortDefinitions(defs)
+	return defs
+}
+
+// FindDefinition returns the top-level declaration with the given name
+func (f *File) FindDefinition(name string) *Definition {
+	for _, def := range f.Definitions() {
+		if def.Name == name {
+			d := def
+			return &d
+		}
+	}
+	return nil
+}
+
+// FindStruct returns the struct, exception or union with the given name
+func (f *File) FindStruct(name string) *Struct {
+	for _, s := range f.Structs {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// FindEnum returns the enum with the given name
+func (f *File) FindEnum(name string) *Enum {
+	for _, e := range f.Enums {
+		if e.Name == name {
+			return e
+		}
+	}
+	return nil
+}
+
+// FindScope returns the scope with the given name
+func (f *File) FindScope(name string) *Scope {
+	for _, s := range f.Scopes {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// FindService returns the service with the given name
+func (f *File) FindService(name string) *Service {
+	for _, s := range f.Services {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// FindConst returns the const with the given name
+func (f *File) FindConst(name string) *Const {
+	for _, c := range f.Consts {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// FindTypedef returns the typedef with the given name
+func (f *File) FindTypedef(name string) *Typedef {
+	for _, t := range f.Typedefs {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// FindInclude returns the include whose prefix matches name
+func (f *File) FindInclude(name string) *Include {
+	for _, inc := range f.Includes {
+		if inc.Name == name {
+			return inc
+		}
+	}
+	return nil
+}
+
+// TypeRefs returns every type reference in the file, including nested container types
+func (f *File) TypeRefs() []*TypeRef {
+	var refs []*TypeRef
+	collect := func(t *TypeRef) {
+		t.Walk(func(ref *TypeRef) {
+			refs = append(refs, ref)
+		})
+	}
+	collectFields := func(fields []*Field) {
+		for _, field := range fields {
+			collect(field.Type)
+		}
+	}
+
+	for _, c := range f.Consts {
+		collect(c.Type)
+	}
+	for _, t := range f.Typedefs {
+		collect(t.Type)
+	}
+	for _, s := range f.Structs {
+		collectFields(s.Fields)
+	}
+	for _, s := range f.Services {
+		for _, m := range s.Methods {
+			collect(m.ReturnType)
+			collectFields(m.Params)
+			collectFields(m.Throws)
+		}
+	}
+	for _, s := range f.Scopes {
+		for _, e := range s.Events {
+			collect(e.Type)
+		}
+	}
+	return refs
+}
+
+// BuildFile builds the typed model for a parsed document
+func BuildFile(root *tree_sitter.Node, source []byte) *File {
+	file := &File{}
+	if root == nil {
+		return file
+	}
+	buildFileRecursive(root, source, file)
+	return file
+}
+
+func buildFileRecursive(node *tree_sitter.Node, source []byte, file *File) {
+	switch node.Kind() {
+	case "include":
+		if inc := buildInclude(node, source); inc != nil {
+			file.Includes = append(file.Includes, inc)
+		}
+		return
+	case "namespace":
+		if ns := buildNamespace(node, source); ns != nil {
+			file.Namespaces = append(file.Namespaces, ns)
+		}
+		return
+	case "const_definition":
+		if c := buildConst(node, source); c != nil {
+			file.Consts = append(file.Consts, c)
+		}
+		return
+	case "typedef_definition":
+		if t := buildTypedef(node, source); t != nil {
+			file.Typedefs = append(file.Typedefs, t)
+		}
+		return
+	case "enum_definition":
+		if e := buildEnum(node, source); e != nil {
+			file.Enums = append(file.Enums, e)
+		}
+		return
+	case "struct_definition":
+		if s := buildStruct(node, source, NodeTypeStruct); s != nil {
+			file.Structs = append(file.Structs, s)
+		}
+		return
+	case "exception_definition":
+		if s := buildStruct(node, source, NodeTypeException); s != nil {
+			file.Structs = append(file.Structs, s)
+		}
+		return
+	case "union_definition":
+		if s := buildStruct(node, source, NodeTypeUnion); s != nil {
+			file.Structs = append(file.Structs, s)
+		}
+		return
+	case "service_definition":
+		if s := buildService(node, source); s != nil {
+			file.Services = append(file.Services, s)
+		}
+		return
+	case "scope_definition":
+		if s := buildScope(node, source); s != nil {
+			file.Scopes = append(file.Scopes, s)
+		}
+		return
+	}
+
+	childCount := node.ChildCount()
+	for i := uint(0); i < childCount; i++ {
+		buildFileRecursive(node.Child(i), source, file)
+	}
+}
+
+// namedChildren returns the direct children of node with the given kind
+func namedChildren(node *tree_sitter.Node, kind string) []*tree_sitter.Node {
+	var result []*tree_sitter.Node
+	childCount := node.ChildCount()
+	for i := uint(0); i < childCount; i++ {
+		child := node.Child(i)
+		if child.Kind() == kind {
+			result = append(result, child)
+		}
+	}
+	return result
+}
+
+// firstChild returns the first direct child of node with the given kind
+func firstChild(node *tree_sitter.Node, kind string) *tree_sitter.Node {
+	childCount := node.ChildCount()
+	for i := uint(0); i < childCount; i++ {
+		child := node.Child(i)
+		if child.Kind() == kind {
+			return child
+		}
+	}
+	return nil
+}
+
+// unquote strips the surrounding quotes from a string literal
+func unquote(text string) string {
+	if len(text) >= 2 && (text[0] == '"' || text[0] == '\'') && text[len(text)-1] == text[0] {
+		return text[1 : len(text)-1]
+	}
+	return text
+}
+
+func buildInclude(node *tree_sitter.Node, source []byte) *Include {
+	pathNode := firstChild(node, "literal_string")
+	if pathNode == nil {
+		return nil
+	}
+	path := unquote(GetText(pathNode, source))
+	name := path
+	if idx := strings.LastIndexAny(name, "/\\"); idx >= 0 {
+		name = name[idx+1:]
+	}
+	name = strings.TrimSuffix(name, ".frugal")
+	return &Include{
+		Path:      path,
+		Name:      name,
+		Range:     NodeRange(node),
+		PathRange: NodeRange(pathNode),
+	}
+}
+
+func buildNamespace(node *tree_sitter.Node, source []byte) *Namespace {
+	scopeNode := firstChild(node, "namespace_scope")
+	nameNode := firstChild(node, "identifier")
+	if scopeNode == nil || nameNode == nil {
+		return nil
+	}
+	return &Namespace{
+		Scope: GetText(scopeNode, source),
+		Name:  GetText(nameNode, source),
+		Range: NodeRange(node),
+	}
+}
+
+func buildConst(node *tree_sitter.Node, source []byte) *Const {
+	nameNode := firstChild(node, "identifier")
+	if nameNode == nil {
+		return nil
+	}
+	return &Const{
+		Name:      GetText(nameNode, source),
+		Type:      BuildTypeRef(firstChild(node, "field_type"), source),
+		Value:     BuildConstValue(firstChild(node, "const_value"), source),
+		Range:     NodeRange(node),
+		NameRange: NodeRange(nameNode),
+		Doc:       buildDocComment(node, source),
+	}
+}
+
+func buildTypedef(node *tree_sitter.Node, source []byte) *Typedef {
+	nameNode := firstChild(node, "identifier")
+	if nameNode == nil {
+		return nil
+	}
+	typedef := &Typedef{
+		Name:        GetText(nameNode, source),
+		Type:        BuildTypeRef(firstChild(node, "field_type"), source),
+		Range:       NodeRange(node),
+		NameRange:   NodeRange(nameNode),
+		Annotations: buildAnnotations(node, source),
+	}
+	typedef.Deprecated = buildDeprecation(node, typedef.Annotations, source)
+	typedef.Doc = buildDocComment(node, source)
+	return typedef
+}
+
+func buildEnum(node *tree_sitter.Node, source []byte) *Enum {
+	nameNode := firstChild(node, "identifier")
+	if nameNode == nil {
+		return nil
+	}
+	enum := &Enum{
+		Name:        GetText(nameNode, source),
+		Range:       NodeRange(node),
+		NameRange:   NodeRange(nameNode),
+		Annotations: buildAnnotations(node, source),
+	}
+	enum.Deprecated = buildDeprecation(node, enum.Annotations, source)
+	enum.Doc = buildDocComment(node, source)
+
+	body := firstChild(node, "enum_body")
+	if body == nil {
+		return enum
+	}
+
+	// Values without an explicit number continue from the previous value
+	next := 0
+	for _, fieldNode := range namedChildren(body, "enum_field") {
+		valueName := firstChild(fieldNode, "identifier")
+		if valueName == nil {
+			continue
+		}
+		value := &EnumValue{
+			Name:        GetText(valueName, source),
+			Value:       next,
+			Range:       NodeRange(fieldNode),
+			NameRange:   NodeRange(valueName),
+			Annotations: buildAnnotations(fieldNode, source),
+		}
+		value.Deprecated = buildDeprecation(fieldNode, value.Annotations, source)
+		value.Doc = buildDocComment(fieldNode, source)
+		if intNode := firstChild(fieldNode, "integer"); intNode != nil {
+			if v, err := strconv.Atoi(GetText(intNode, source)); err == nil {
+				value.Value = v
+				value.HasValue = true
+			}
+		}
+		next = value.Value + 1
+		enum.Values = append(enum.Values, value)
+	}
+
+	return enum
+}
+
+func buildStruct(node *tree_sitter.Node, source []byte, kind NodeType) *Struct {
+	nameNode := firstChild(node, "identifier")
+	if nameNode == nil {
+		return nil
+	}
+	s := &Struct{
+		Kind:        kind,
+		Name:        GetText(nameNode, source),
+		Range:       NodeRange(node),
+		NameRange:   NodeRange(nameNode),
+		Annotations: buildAnnotations(node, source),
+	}
+	s.Deprecated = buildDeprecation(node, s.Annotations, source)
+	s.Doc = buildDocComment(node, source)
+	if body := firstChild(node, "struct_body"); body != nil {
+		s.Fields = buildFields(body, source)
+	}
+	for _, comment := range definitionComments(node) {
+		if reserved := buildReserved(comment, source); reserved != nil {
+			s.Reserved = append(s.Reserved, reserved)
+		}
+	}
+	return s
+}
+
+// leadingComments returns the comments directly above a declaration. A comment trailing
+// the previous declaration on its line belongs to that declaration.
+func leadingComments(node *tree_sitter.Node) []*tree_sitter.Node {
+	// Definitions are wrapped in a definition node whose siblings hold leading comments,
+	// and comments above the first member of a body are siblings of the body
+	anchor := node
+	if parent := node.Parent(); parent != nil && (parent.Kind() == "definition" || node.PrevSibling() == nil) {
+		anchor = parent
+	}
+
+	var leading []*tree_sitter.Node
+	next := anchor
+	for prev := anchor.PrevSibling(); prev != nil && prev.Kind() == "comment"; prev = prev.PrevSibling() {
+		if prev.EndPosition().Row+1 < next.StartPosition().Row {
+			break // Separated by a blank line
+		}
+		if before := prev.PrevSibling(); before != nil && before.Kind() != "comment" && before.EndPosition().Row == prev.StartPosition().Row {
+			break
+		}
+		leading = append([]*tree_sitter.Node{prev}, leading...)
+		next = prev
+	}
+	return leading
+}
+
+// trailingComment returns the comment following a declaration on its last line, or nil
+func trailingComment(node *tree_sitter.Node) *tree_sitter.Node {
+	if parent := node.Parent(); parent != nil && parent.Kind() == "definition" {
+		node = parent
+	}
+	for next := node.NextSibling(); next != nil && next.StartPosition().Row == node.EndPosition().Row; next = next.NextSibling() {
+		if next.Kind() == "comment" {
+			return next
+		}
+	}
+	return nil
+}
+
+// definitionComments returns the comments directly above a definition followed by the
+// comments inside it
+func definitionComments(node *tree_sitter.Node) []*tree_sitter.Node {
+	comments := leadingComments(node)
+	var walk func(*tree_sitter.Node)
+	walk = func(n *tree_sitter.Node) {
+		childCount := n.ChildCount()
+		for i := uint(0); i < childCount; i++ {
+			child := n.Child(i)
+			if child.Kind() == "comment" {
+				comments = append(comments, child)
+			} else {
+				walk(child)
+			}
+		}
+	}
+	walk(node)
+	return comments
+}
+
+// deprecatedPattern matches an @deprecated tag and the message following it on its line
+var deprecatedPattern = regexp.MustCompile(`@deprecated\b[ \t]*([^\n]*)`)
+
+// buildDeprecation returns how a declaration is deprecated, or nil if it is not. A
+// `deprecated` annotation takes precedence over an @deprecated tag in the comments above;
+// its value is the message, except that "true" deprecates without one and "false" does
+// not deprecate.
+func buildDeprecation(node *tree_sitter.Node, annotations []*Annotation, source []byte) *Deprecation {
+	for _, annotation := range annotations {
+		if annotation.Key != "deprecated" {
+			continue
+		}
+		switch {
+		case strings.EqualFold(annotation.Value, "false"):
+			return nil
+		case strings.EqualFold(annotation.Value, "true"):
+			return &Deprecation{}
+		}
+		return &Deprecation{Message: annotation.Value}
+	}
+	comments := leadingComments(node)
+	if trailing := trailingComment(node); trailing != nil {
+		comments = append(comments, trailing)
+	}
+	for _, comment := range comments {
+		if match := deprecatedPattern.FindStringSubmatch(GetText(comment, source)); match != nil {
+			message := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(match[1]), "*/"))
+			return &Deprecation{Message: message}
+		}
+	}
+	return nil
+}
+
+// docTagTargets are the tags whose first word names what they describe
+var docTagTargets = map[string]bool{"param": true, "throws": true, "exception": true}
+
+// buildDocComment builds the documentation in the comments directly above a
+// declaration, or nil if there is none. frugal-ls directives are left out.
+func buildDocComment(node *tree_sitter.Node, source []byte) *DocComment {
+	doc := &DocComment{}
+	var description []string
+	var tag *DocTag
+	for _, comment := range leadingComments(node) {
+		for _, line := range commentLines(GetText(comment, source)) {
+			trimmed := strings.TrimSpace(line)
+			switch {
+			case strings.HasPrefix(trimmed, "frugal-ls:"):
+				continue
+			case len(trimmed) > 1 && trimmed[0] == '@':
+				name, text, _ := strings.Cut(trimmed[1:], " ")
+				tag = &DocTag{Name: name, Text: strings.TrimSpace(text)}
+				if docTagTargets[name] {
+					target, rest, _ := strings.Cut(tag.Text, " ")
+					tag.Target, tag.Text = target, strings.TrimSpace(rest)
+				}
+				doc.Tags = append(doc.Tags, tag)
+			case tag != nil:
+				// Lines after a tag continue its text
+				if trimmed != "" {
+					tag.Text = strings.TrimSpace(tag.Text + " " + trimmed)
+				}
+			default:
+				description = append(description, line)
+			}
+		}
+	}
+
+	doc.Text = strings.TrimSpace(strings.Join(description, "\n"))
+	if doc.Text == "" && len(doc.Tags) == 0 {
+		return nil
+	}
+	return doc
+}
+
+// commentLines returns the lines of a comment with the comment markers removed
+func commentLines(text string) []string {
+	if !strings.HasPrefix(text, "/*") {
+		text = strings.TrimLeft(text, "/#")
+		return []string{strings.TrimRight(strings.TrimPrefix(text, " "), " \t\r")}
+	}
+
+	lines := strings.Split(strings.TrimSuffix(strings.TrimPrefix(text, "/*"), "*/"), "\n")
+	for i, line := range lines {
+		// Lines inside a block comment are usually aligned on a leading *
+		line = strings.TrimPrefix(strings.TrimLeft(line, " \t"), "*")
+		lines[i] = strings.TrimRight(strings.TrimPrefix(line, " "), " \t\r")
+	}
+	return lines
+}
+
+// reservedPattern matches a @reserved directive and its arguments in a comment
+var reservedPattern = regexp.MustCompile(`@reserved\b([^\n*]*)`)
+
+// reservedArgPattern matches the next field ID or quoted field name at the start of a
+// directive's arguments, separated from what follows by a comma, space or the end
+var reservedArgPattern = regexp.MustCompile(`^[\s,]*(-?\d+|"[^"]*"|'[^']*')(?:[\s,]|$)`)
+
+// buildReserved builds the reservation declared by a comment, or nil if it has none
+func buildReserved(node *tree_sitter.Node, source []byte) *Reserved {
+	match := reservedPattern.FindStringSubmatch(GetText(node, source))
+	if match == nil {
+		return nil
+	}
+
+	// The list ends at the first token that is not an ID or quoted name, so trailing
+	// prose such as "(removed in v2)" reserves nothing
+	reserved := &Reserved{Range: NodeRange(node)}
+	args := match[1]
+	for {
+		loc := reservedArgPattern.FindStringSubmatchIndex(args)
+		if loc == nil {
+			break
+		}
+		arg := args[loc[2]:loc[3]]
+		if id, err := strconv.Atoi(arg); err == nil {
+			reserved.IDs = append(reserved.IDs, id)
+		} else {
+			reserved.Names = append(reserved.Names, unquote(arg))
+		}
+		args = args[loc[3]:]
+	}
+	return reserved
+}
+
+// buildAnnotations builds the annotation list attached to a declaration node
+func buildAnnotations(node *tree_sitter.Node, source []byte) []*Annotation {
+	annotationNode := firstChild(node, "annotation")
+	if annotationNode == nil {
+		return nil
+	}
+	list := firstChild(annotationNode, "annotation_list")
+	if list == nil {
+		return nil
+	}
+
+	var annotations []*Annotation
+	var current *Annotation
+	childCount := list.ChildCount()
+	for i := uint(0); i < childCount; i++ {
+		child := list.Child(i)
+		switch child.Kind() {
+		case "identifier":
+			current = &Annotation{
+				Key:      GetText(child, source),
+				Range:    NodeRange(child),
+				KeyRange: NodeRange(child),
+			}
+			annotations = append(annotations, current)
+		case "literal_string":
+			if current == nil || current.HasValue {
+				continue
+			}
+			current.Value = unquote(GetText(child, source))
+			current.HasValue = true
+			current.ValueRange = NodeRange(child)
+			current.Range.End = current.ValueRange.End
+		case "list_separator":
+			current = nil
+		}
+	}
+	return annotations
+}
+
+func buildFields(list *tree_sitter.Node, source []byte) []*Field {
+	var fields []*Field
+	for _, fieldNode := range namedChildren(list, "field") {
+		if field := BuildField(fieldNode, source); field != nil {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// BuildField builds the model for a single field node
+func BuildField(node *tree_sitter.Node, source []byte) *Field {
+	nameNode := firstChild(node, "identifier")
+	if nameNode == nil {
+		return nil
+	}
+	field := &Field{
+		Name:        GetText(nameNode, source),
+		Type:        BuildTypeRef(firstChild(node, "field_type"), source),
+		Default:     BuildConstValue(firstChild(node, "const_value"), source),
+		Range:       NodeRange(node),
+		NameRange:   NodeRange(nameNode),
+		Annotations: buildAnnotations(node, source),
+	}
+	field.Deprecated = buildDeprecation(node, field.Annotations, source)
+	field.Doc = buildDocComment(node, source)
+	if idNode := firstChild(node, "field_id"); idNode != nil {
+		if intNode := firstChild(idNode, "integer"); intNode != nil {
+			if id, err := strconv.Atoi(GetText(intNode, source)); err == nil {
+				field.ID = id
+				field.HasID = true
+				field.IDRange = NodeRange(intNode)
+			}
+		}
+	}
+	if reqNode := firstChild(node, "field_req"); reqNode != nil {
+		field.Requiredness = GetText(reqNode, source)
+	}
+	return field
+}
+
+func buildService(node *tree_sitter.Node, source []byte) *Service {
+	identifiers := namedChildren(node, "identifier")
+	if len(identifiers) == 0 {
+		return nil
+	}
+	service := &Service{
+		Name:        GetText(identifiers[0], source),
+		Range:       NodeRange(node),
+		NameRange:   NodeRange(identifiers[0]),
+		Annotations: buildAnnotations(node, source),
+	}
+	service.Deprecated = buildDeprecation(node, service.Annotations, source)
+	service.Doc = buildDocComment(node, source)
+	if len(identifiers) > 1 {
+		service.Extends = GetText(identifiers[1], source)
+		service.ExtendsRange = NodeRange(identifiers[1])
+	}
+
+	body := firstChild(node, "service_body")
+	if body == nil {
+		return service
+	}
+	for _, fnNode := range namedChildren(body, "function_definition") {
+		if method := buildMethod(fnNode, source); method != nil {
+			service.Methods = append(service.Methods, method)
+		}
+	}
+	return service
+}
+
+func buildMethod(node *tree_sitter.Node, source []byte) *Method {
+	nameNode := firstChild(node, "identifier")
+	if nameNode == nil {
+		return nil
+	}
+	method := &Method{
+		Name:        GetText(nameNode, source),
+		Oneway:      firstChild(node, "oneway") != nil,
+		Range:       NodeRange(node),
+		NameRange:   NodeRange(nameNode),
+		Annotations: buildAnnotations(node, source),
+	}
+	method.Deprecated = buildDeprecation(node, method.Annotations, source)
+	method.Doc = buildDocComment(node, source)
+
+	if fnType := firstChild(node, "function_type"); fnType != nil {
+		if typeNode := firstChild(fnType, "field_type"); typeNode != nil {
+			method.ReturnType = BuildTypeRef(typeNode, source)
+		} else {
+			method.ReturnType = &TypeRef{
+				Kind:  TypeRefVoid,
+				Text:  "void",
+				Name:  "void",
+				Range: NodeRange(fnType),
+			}
+		}
+	}
+
+	// The first field_list holds parameters unless it follows the throws keyword
+	inThrows := false
+	childCount := node.ChildCount()
+	for i := uint(0); i < childCount; i++ {
+		child := node.Child(i)
+		switch child.Kind() {
+		case "throws":
+			inThrows = true
+		case "field_list":
+			if inThrows {
+				method.Throws = buildFields(child, source)
+			} else {
+				method.Params = buildFields(child, source)
+			}
+		}
+	}
+
+	return method
+}
+
+func buildScope(node *tree_sitter.Node, source []byte) *Scope {
+	nameNode := firstChild(node, "identifier")
+	if nameNode == nil {
+		return nil
+	}
+	scope := &Scope{
+		Name:      GetText(nameNode, source),
+		Range:     NodeRange(node),
+		NameRange: NodeRange(nameNode),
+		Doc:       buildDocComment(node, source),
+	}
+
+	if prefixNode := firstChild(node, "scope_prefix"); prefixNode != nil {
+		if lit := firstChild(prefixNode, "literal_string"); lit != nil {
+			scope.Prefix = unquote(GetText(lit, source))
+			scope.PrefixRange = NodeRange(lit)
+		} else if ident := firstChild(prefixNode, "identifier"); ident != nil {
+			scope.Prefix = GetText(ident, source)
+			scope.PrefixRange = NodeRange(ident)
+		}
+
+		// The prefix text starts after the opening quote of a string literal
+		start := scope.PrefixRange.Start
+		if scope.PrefixRange.End.Column-start.Column > len(scope.Prefix) {
+			start.Column++
+		}
+		scope.PrefixSegments, scope.PrefixErrors = parsePrefix(scope.Prefix, start)
+	}
+
+	body := firstChild(node, "scope_body")
+	if body == nil {
+		return scope
+	}
+	for _, opNode := range namedChildren(body, "scope_operation") {
+		eventName := firstChild(opNode, "identifier")
+		if eventName == nil {
+			continue
+		}
+		scope.Events = append(scope.Events, &Event{
+			Name:        GetText(eventName, source),
+			Type:        BuildTypeRef(firstChild(opNode, "field_type"), source),
+			Range:       NodeRange(opNode),
+			NameRange:   NodeRange(eventName),
+			Annotations: buildAnnotations(opNode, source),
+			Doc:         buildDocComment(opNode, source),
+		})
+	}
+	return scope
+}
+
+// prefixVariablePattern matches valid prefix variable names
+var prefixVariablePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// parsePrefix splits a single-line scope prefix starting at start into segments and
+// reports unbalanced braces, empty or invalid placeholders and repeated variables.
+// Malformed placeholders are kept as literal text.
+func parsePrefix(prefix string, start Position) ([]*PrefixSegment, []*PrefixError) {
+	var segments []*PrefixSegment
+	var errs []*PrefixError
+	span := func(from, to int) Range {
+		return Range{
+			Start: Position{Line: start.Line, Column: start.Column + from},
+			End:   Position{Line: start.Line, Column: start.Column + to},
+		}
+	}
+	addLiteral := func(from, to int) {
+		if to > from {
+			segments = append(segments, &PrefixSegment{Text: prefix[from:to], Range: span(from, to)})
+		}
+	}
+
+	seen := make(map[string]bool)
+	literalStart := 0
+	for i := 0; i < len(prefix); i++ {
+		switch prefix[i] {
+		case '}':
+			errs = append(errs, &PrefixError{Message: "Unmatched '}' in scope prefix", Range: span(i, i+1)})
+		case '{':
+			end := strings.IndexAny(prefix[i+1:], "{}")
+			if end < 0 || prefix[i+1+end] == '{' {
+				errs = append(errs, &PrefixError{Message: "Unclosed '{' in scope prefix", Range: span(i, i+1)})
+				continue
+			}
+			end += i + 1
+
+			name := prefix[i+1 : end]
+			switch {
+			case name == "":
+				errs = append(errs, &PrefixError{Message: "Empty variable in scope prefix", Range: span(i, end+1)})
+			case !prefixVariablePattern.MatchString(name):
+				errs = append(errs, &PrefixError{Message: fmt.Sprintf("Invalid variable name '%s' in scope prefix", name), Range: span(i, end+1)})
+			default:
+				if seen[name] {
+					errs = append(errs, &PrefixError{Message: fmt.Sprintf("Duplicate variable '%s' in scope prefix", name), Range: span(i, end+1)})
+				}
+				seen[name] = true
+				addLiteral(literalStart, i)
+				literalStart = end + 1
+				segments = append(segments, &PrefixSegment{Text: name, Variable: true, Range: span(i, end+1)})
+			}
+			i = end
+		}
+	}
+	addLiteral(literalStart, len(prefix))
+
+	return segments, errs
+}
+
+// BuildTypeRef builds a type reference from a field_type node
+func BuildTypeRef(node *tree_sitter.Node, source []byte) *TypeRef {
+	if node == nil {
+		return nil
+	}
+
+	ref := &TypeRef{
+		Text:  GetText(node, source),
+		Range: NodeRange(node),
+	}
+
+	if base := firstChild(node, "base_type"); base != nil {
+		ref.Kind = TypeRefBase
+		ref.Name = GetText(base, source)
+		return ref
+	}
+
+	if ident := firstChild(node, "identifier"); ident != nil {
+		ref.Kind = TypeRefUser
+		name := GetText(ident, source)
+		if idx := strings.LastIndex(name, "."); idx >= 0 {
+			ref.Include = name[:idx]
+			name = name[idx+1:]
+		}
+		ref.Name = name
+		return ref
+	}
+
+	container := firstChild(node, "container_type")
+	if container == nil || container.ChildCount() == 0 {
+		return ref
+	}
+
+	inner := container.Child(0)
+	params := namedChildren(inner, "field_type")
+	switch inner.Kind() {
+	case "list_type":
+		ref.Kind = TypeRefList
+		ref.Name = "list"
+		if len(params) > 0 {
+			ref.Elem = BuildTypeRef(params[0], source)
+		}
+	case "set_type":
+		ref.Kind = TypeRefSet
+		ref.Name = "set"
+		if len(params) > 0 {
+			ref.Elem = BuildTypeRef(params[0], source)
+		}
+	case "map_type":
+		ref.Kind = TypeRefMap
+		ref.Name = "map"
+		if len(params) > 0 {
+			ref.Key = BuildTypeRef(params[0], source)
+		}
+		if len(params) > 1 {
+			ref.Value = BuildTypeRef(params[1], source)
+		}
+	}
+
+	return ref
+}
+
+// BuildConstValue builds a constant value from a const_value node
+func BuildConstValue(node *tree_sitter.Node, source []byte) *ConstValue {
+	if node == nil {
+		return nil
+	}
+
+	value := &ConstValue{
+		Text:  GetText(node, source),
+		Range: NodeRange(node),
+	}
+
+	if node.ChildCount() == 0 {
+		return value
+	}
+
+	inner := node.Child(0)
+	switch inner.Kind() {
+	case "integer":
+		value.Kind = ConstValueInteger
+	case "double":
+		value.Kind = ConstValueDouble
+	case "literal_string":
+		value.Kind = ConstValueString
+	case "identifier":
+		value.Kind = ConstValueIdentifier
+	case "const_list":
+		value.Kind = ConstValueList
+		if contents := firstChild(inner, "const_list_contents"); contents != nil {
+			for _, item := range namedChildren(contents, "const_value") {
+				value.Elements = append(value.Elements, BuildConstValue(item, source))
+			}
+		}
+	case "const_map":
+		value.Kind = ConstValueMap
+		if contents := firstChild(inner, "const_map_contents"); contents != nil {
+			items := namedChildren(contents, "const_value")
+			for i := 0; i+1 < len(items); i += 2 {
+				value.Entries = append(value.Entries, &ConstMapEntry{
+					Key:   BuildConstValue(items[i], source),
+					Value: BuildConstValue(items[i+1], source),
+				})
+			}
+		}
+	}
+
+	return value
+}
+
+// sortDefinitions orders definitions by their position in the source
+func sortDefinitions(defs []Definition) {
+	sort.SliceStable(defs, func(i, j int) bool {
+		a, b := defs[i].Range.Start, defs[j].Range.Start
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+}
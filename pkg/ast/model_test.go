@@ -0,0 +1,498 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"frugal-ls/internal/parser"
+)
+
+func buildTestFile(t *testing.T, content string) *File {
+	t.Helper()
+
+	p, err := parser.NewParser()
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer p.Close()
+
+	result, err := p.Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	defer result.Close()
+
+	return BuildFile(result.GetRootNode(), []byte(content))
+}
+
+func TestBuildFileDefinitions(t *testing.T) {
+	content := `include "common.frugal"
+namespace go example
+
+const i32 MAX_USERS = 100
+typedef i64 UserId
+
+enum Status {
+    ACTIVE = 1,
+    INACTIVE,
+    BANNED = 10
+}
+
+struct User {
+    1: required UserId id,
+    2: optional string name = "anon",
+    3: list<common.Tag> tags
+}
+
+exception NotFound {
+    1: string message
+}
+
+service UserService extends common.BaseService {
+    User getUser(1: UserId id) throws (1: NotFound nf),
+    oneway void ping()
+}
+
+scope UserEvents prefix "user" {
+    Created: User
+}`
+
+	file := buildTestFile(t, content)
+
+	if len(file.Includes) != 1 || file.Includes[0].Path != "common.frugal" || file.Includes[0].Name != "common" {
+		t.Fatalf("Unexpected includes: %+v", file.Includes)
+	}
+	if len(file.Namespaces) != 1 || file.Namespaces[0].Scope != "go" || file.Namespaces[0].Name != "example" {
+		t.Errorf("Unexpected namespaces: %+v", file.Namespaces)
+	}
+
+	if c := file.FindConst("MAX_USERS"); c == nil || c.Type.Name != "i32" || c.Value.Kind != ConstValueInteger || c.Value.Text != "100" {
+		t.Errorf("Unexpected const: %+v", c)
+	}
+
+	if td := file.FindTypedef("UserId"); td == nil || td.Type.Kind != TypeRefBase || td.Type.Name != "i64" {
+		t.Errorf("Unexpected typedef: %+v", td)
+	}
+
+	enum := file.FindEnum("Status")
+	if enum == nil || len(enum.Values) != 3 {
+		t.Fatalf("Expected enum with 3 values, got %+v", enum)
+	}
+	expectedValues := map[string]int{"ACTIVE": 1, "INACTIVE": 2, "BANNED": 10}
+	for _, v := range enum.Values {
+		if expectedValues[v.Name] != v.Value {
+			t.Errorf("Enum value %s: expected %d, got %d", v.Name, expectedValues[v.Name], v.Value)
+		}
+	}
+
+	user := file.FindStruct("User")
+	if user == nil || user.Kind != NodeTypeStruct || len(user.Fields) != 3 {
+		t.Fatalf("Unexpected struct: %+v", user)
+	}
+	if f := user.Fields[0]; f.ID != 1 || f.Requiredness != "required" || f.Type.Kind != TypeRefUser || f.Type.Name != "UserId" {
+		t.Errorf("Unexpected first field: %+v", f)
+	}
+	if f := user.Fields[1]; f.Default == nil || f.Default.Kind != ConstValueString {
+		t.Errorf("Expected string default on second field, got %+v", f.Default)
+	}
+	tags := user.Fields[2].Type
+	if tags.Kind != TypeRefList || tags.Elem == nil || tags.Elem.Include != "common" || tags.Elem.Name != "Tag" {
+		t.Errorf("Unexpected list type: %+v", tags)
+	}
+
+	if nf := file.FindStruct("NotFound"); nf == nil || nf.Kind != NodeTypeException {
+		t.Errorf("Expected exception NotFound, got %+v", nf)
+	}
+
+	service := file.FindService("UserService")
+	if service == nil || service.Extends != "common.BaseService" || len(service.Methods) != 2 {
+		t.Fatalf("Unexpected service: %+v", service)
+	}
+	getUser := service.Methods[0]
+	if getUser.Name != "getUser" || getUser.ReturnType.Name != "User" || len(getUser.Params) != 1 || len(getUser.Throws) != 1 {
+		t.Errorf("Unexpected method: %+v", getUser)
+	}
+	ping := service.Methods[1]
+	if !ping.Oneway || ping.ReturnType.Kind != TypeRefVoid {
+		t.Errorf("Expected oneway void ping, got %+v", ping)
+	}
+
+	if len(file.Scopes) != 1 || file.Scopes[0].Prefix != "user" || len(file.Scopes[0].Events) != 1 {
+		t.Fatalf("Unexpected scopes: %+v", file.Scopes)
+	}
+	if event := file.Scopes[0].Events[0]; event.Name != "Created" || event.Type.Name != "User" {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+}
+
+func TestFileDefinitionsOrder(t *testing.T) {
+	content := `struct B {
+    1: i32 x
+}
+
+const i32 A = 1
+
+service C {
+    void run()
+}`
+
+	file := buildTestFile(t, content)
+	defs := file.Definitions()
+
+	expected := []string{"B", "A", "C"}
+	if len(defs) != len(expected) {
+		t.Fatalf("Expected %d definitions, got %d", len(expected), len(defs))
+	}
+	for i, name := range expected {
+		if defs[i].Name != name {
+			t.Errorf("Definition %d: expected %s, got %s", i, name, defs[i].Name)
+		}
+	}
+}
+
+func TestFileTypeRefs(t *testing.T) {
+	content := `struct User {
+    1: map<string, list<Tag>> tags
+}`
+
+	file := buildTestFile(t, content)
+	refs := file.TypeRefs()
+
+	// map, string, list, Tag
+	if len(refs) != 4 {
+		t.Fatalf("Expected 4 type refs, got %d", len(refs))
+	}
+	if refs[0].Kind != TypeRefMap || refs[1].Name != "string" || refs[2].Kind != TypeRefList || refs[3].Name != "Tag" {
+		t.Errorf("Unexpected type refs: %+v %+v %+v %+v", refs[0], refs[1], refs[2], refs[3])
+	}
+}
+
+func TestConstValueContainers(t *testing.T) {
+	content := `const list<i32> NUMBERS = [1, 2, 3]
+const map<string, i32> LOOKUP = {"a": 1, "b": 2}`
+
+	file := buildTestFile(t, content)
+
+	numbers := file.FindConst("NUMBERS")
+	if numbers == nil || numbers.Value.Kind != ConstValueList || len(numbers.Value.Elements) != 3 {
+		t.Fatalf("Unexpected list const: %+v", numbers)
+	}
+
+	lookup := file.FindConst("LOOKUP")
+	if lookup == nil || lookup.Value.Kind != ConstValueMap || len(lookup.Value.Entries) != 2 {
+		t.Fatalf("Unexpected map const: %+v", lookup)
+	}
+	if lookup.Value.Entries[0].Key.Text != `"a"` || lookup.Value.Entries[0].Value.Text != "1" {
+		t.Errorf("Unexpected first entry: %+v", lookup.Value.Entries[0])
+	}
+}
+
+func TestBuildFileNilRoot(t *testing.T) {
+	file := BuildFile(nil, nil)
+	if file == nil || len(file.Definitions()) != 0 {
+		t.Error("Expected empty file for nil root")
+	}
+}
+
+func TestStructReserved(t *testing.T) {
+	content := `// @reserved 99
+
+// User is a user
+// @reserved 4, 7 "oldName"
+struct User {
+    // @reserved 5 'legacy'
+    1: string name,
+    /* @reserved 9 */
+    // not a reservation
+    // @reserved 6 "gone" (removed in v2, see 3x)
+}`
+
+	file := buildTestFile(t, content)
+	user := file.FindStruct("User")
+	if user == nil {
+		t.Fatal("Expected User struct")
+	}
+	if len(user.Reserved) != 4 {
+		t.Fatalf("Expected 4 reservations, got %+v", user.Reserved)
+	}
+
+	leading := user.Reserved[0]
+	if len(leading.IDs) != 2 || leading.IDs[0] != 4 || leading.IDs[1] != 7 || len(leading.Names) != 1 || leading.Names[0] != "oldName" {
+		t.Errorf("Unexpected leading reservation: %+v", leading)
+	}
+	if leading.Range.Start.Line != 3 {
+		t.Errorf("Expected the reservation to cover its comment, got %+v", leading.Range)
+	}
+
+	for _, id := range []int{4, 5, 6, 7, 9} {
+		if user.ReservedID(id) == nil {
+			t.Errorf("Expected ID %d to be reserved", id)
+		}
+	}
+	if trailing := user.Reserved[3]; len(trailing.IDs) != 1 || len(trailing.Names) != 1 || trailing.Names[0] != "gone" {
+		t.Errorf("Expected the list to end at the first other token, got %+v", trailing)
+	}
+	if user.ReservedID(99) != nil || user.ReservedID(1) != nil {
+		t.Error("Expected comments separated by a blank line and field IDs not to be reserved")
+	}
+	if user.ReservedName("legacy") == nil || user.ReservedName("name") != nil {
+		t.Error("Unexpected reserved names")
+	}
+}
+
+func TestBuildUnion(t *testing.T) {
+	file := buildTestFile(t, `union Value {
+    1: string text,
+    2: i64 number
+}`)
+
+	value := file.FindStruct("Value")
+	if value == nil || value.Kind != NodeTypeUnion || len(value.Fields) != 2 {
+		t.Fatalf("Expected union Value with 2 fields, got %+v", value)
+	}
+	if def := file.FindDefinition("Value"); def == nil || def.Type != NodeTypeUnion {
+		t.Errorf("Expected union definition, got %+v", def)
+	}
+}
+
+func TestScopePrefixSegments(t *testing.T) {
+	file := buildTestFile(t, `scope UserEvents prefix "user.{region}.{id}" {
+    Created: string
+}
+
+scope Broken prefix "a.{}.{1x}.{id}.{id}.{open" {
+    Changed: string
+}`)
+
+	events := file.FindScope("UserEvents")
+	if events == nil {
+		t.Fatal("Expected UserEvents scope")
+	}
+	var parts []string
+	for _, segment := range events.PrefixSegments {
+		parts = append(parts, fmt.Sprintf("%s:%v@%d-%d", segment.Text, segment.Variable, segment.Range.Start.Column, segment.Range.End.Column))
+	}
+	expected := "user.:false@25-30 region:true@30-38 .:false@38-39 id:true@39-43"
+	if strings.Join(parts, " ") != expected {
+		t.Errorf("Expected segments %s, got %s", expected, strings.Join(parts, " "))
+	}
+	if len(events.PrefixErrors) != 0 {
+		t.Errorf("Expected no prefix errors, got %+v", events.PrefixErrors)
+	}
+	if vars := events.PrefixVariables(); len(vars) != 2 || vars[0] != "region" || vars[1] != "id" {
+		t.Errorf("Expected variables region and id, got %v", vars)
+	}
+	if topic := events.Topic("Created"); topic != "user.{region}.{id}.UserEvents.Created" {
+		t.Errorf("Unexpected topic %q", topic)
+	}
+
+	var messages []string
+	for _, prefixError := range file.FindScope("Broken").PrefixErrors {
+		messages = append(messages, prefixError.Message)
+	}
+	expectedErrors := []string{
+		"Empty variable in scope prefix",
+		"Invalid variable name '1x' in scope prefix",
+		"Duplicate variable 'id' in scope prefix",
+		"Unclosed '{' in scope prefix",
+	}
+	if strings.Join(messages, "\n") != strings.Join(expectedErrors, "\n") {
+		t.Errorf("Expected %v, got %v", expectedErrors, messages)
+	}
+}
+
+func TestBuildAnnotations(t *testing.T) {
+	file := buildTestFile(t, `typedef i64 UserID (go.name = "ID")
+
+enum Status {
+    ACTIVE (deprecated)
+} (go.name = "State")
+
+struct User {
+    1: string id (go.tag = "json:\"id\"", deprecated; java.name = "userId")
+} (deprecated = "use Account")
+
+service Users {
+    void ping(1: string token (deprecated)) (deprecated = "no longer needed")
+} (go.name = "UserService")
+
+scope Events {
+    Created: User (deprecated)
+}`)
+
+	format := func(annotations []*Annotation) string {
+		var parts []string
+		for _, annotation := range annotations {
+			part := annotation.Key
+			if annotation.HasValue {
+				part += "=" + annotation.Value
+			}
+			parts = append(parts, part)
+		}
+		return strings.Join(parts, " ")
+	}
+
+	user := file.FindStruct("User")
+	method := file.FindService("Users").Methods[0]
+	tests := map[string]struct {
+		annotations []*Annotation
+		expected    string
+	}{
+		"typedef":    {file.FindTypedef("UserID").Annotations, "go.name=ID"},
+		"enum":       {file.FindEnum("Status").Annotations, "go.name=State"},
+		"enum value": {file.FindEnum("Status").Values[0].Annotations, "deprecated"},
+		"struct":     {user.Annotations, "deprecated=use Account"},
+		"field":      {user.Fields[0].Annotations, `go.tag=json:\"id\" deprecated java.name=userId`},
+		"service":    {file.FindService("Users").Annotations, "go.name=UserService"},
+		"method":     {method.Annotations, "deprecated=no longer needed"},
+		"parameter":  {method.Params[0].Annotations, "deprecated"},
+		"event":      {file.FindScope("Events").Events[0].Annotations, "deprecated"},
+	}
+	for name, tt := range tests {
+		if got := format(tt.annotations); got != tt.expected {
+			t.Errorf("%s: expected annotations %q, got %q", name, tt.expected, got)
+		}
+	}
+
+	tag := user.Fields[0].Annotations[0]
+	if tag.KeyRange.Start.Column != 18 || tag.ValueRange.Start.Column != 27 || tag.Range.End != tag.ValueRange.End {
+		t.Errorf("Unexpected ranges for go.tag: %+v", tag)
+	}
+	deprecated := user.Fields[0].Annotations[1]
+	if deprecated.Range != deprecated.KeyRange {
+		t.Errorf("Expected a bare annotation's range to be its key, got %+v", deprecated)
+	}
+}
+
+func TestBuildDeprecation(t *testing.T) {
+	file := buildTestFile(t, `// @deprecated Use Account instead.
+struct User {
+    1: string id, // @deprecated applies to id only
+    2: string name,
+
+    // @deprecated
+    3: string nickname,
+    /**
+     * The user's email.
+     * @deprecated Use contacts instead.
+     */
+    4: string email
+}
+
+// Still supported.
+
+struct Account {
+    1: string id (deprecated = "use uuid"),
+    2: string legacy (deprecated = "true"),
+    // @deprecated
+    3: string current (deprecated = "false")
+}
+
+enum Status {
+    ACTIVE,
+    LEGACY (deprecated)
+}
+
+service Users {
+    // @deprecated
+    void ping()
+}`)
+
+	describe := func(deprecation *Deprecation) string {
+		if deprecation == nil {
+			return "-"
+		}
+		return "deprecated:" + deprecation.Message
+	}
+
+	user := file.FindStruct("User")
+	tests := map[string]struct {
+		deprecation *Deprecation
+		expected    string
+	}{
+		"struct comment":          {user.Deprecated, "deprecated:Use Account instead."},
+		"trailing comment":        {user.Fields[0].Deprecated, "deprecated:applies to id only"},
+		"next field":              {user.Fields[1].Deprecated, "-"},
+		"line comment":            {user.Fields[2].Deprecated, "deprecated:"},
+		"doc comment":             {user.Fields[3].Deprecated, "deprecated:Use contacts instead."},
+		"separated comment":       {file.FindStruct("Account").Deprecated, "-"},
+		"annotation":              {file.FindStruct("Account").Fields[0].Deprecated, "deprecated:use uuid"},
+		"true annotation":         {file.FindStruct("Account").Fields[1].Deprecated, "deprecated:"},
+		"false annotation":        {file.FindStruct("Account").Fields[2].Deprecated, "-"},
+		"enum value":              {file.FindEnum("Status").Values[1].Deprecated, "deprecated:"},
+		"undeprecated enum value": {file.FindEnum("Status").Values[0].Deprecated, "-"},
+		"method":                  {file.FindService("Users").Methods[0].Deprecated, "deprecated:"},
+	}
+	for name, tt := range tests {
+		if got := describe(tt.deprecation); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", name, tt.expected, got)
+		}
+	}
+}
+
+func TestBuildDocComment(t *testing.T) {
+	file := buildTestFile(t, `// frugal-ls:disable FL003
+// A user account.
+//
+// Created on sign-up.
+struct User {
+    1: string id, // The ID.
+    2: string name
+}
+
+service Users {
+    /**
+     * Looks up a user.
+     *
+     * @param id the user's ID,
+     *   as returned by create
+     * @throws NotFound if there is no such user
+     * @return the user
+     */
+    User get(1: string id) throws (1: NotFound missing)
+}
+
+/* Published on every change */
+scope Changes {
+    // The user after the change
+    Updated: User
+}
+
+enum Status {
+    /// Can sign in
+    ACTIVE
+}`)
+
+	describe := func(doc *DocComment) string {
+		if doc == nil {
+			return "-"
+		}
+		parts := []string{doc.Text}
+		for _, tag := range doc.Tags {
+			parts = append(parts, fmt.Sprintf("@%s[%s] %s", tag.Name, tag.Target, tag.Text))
+		}
+		return strings.Join(parts, "|")
+	}
+
+	user := file.FindStruct("User")
+	tests := map[string]struct {
+		doc      *DocComment
+		expected string
+	}{
+		"struct":           {user.Doc, "A user account.\n\nCreated on sign-up."},
+		"trailing comment": {user.Fields[0].Doc, "-"},
+		"next field":       {user.Fields[1].Doc, "-"},
+		"method": {file.FindService("Users").Methods[0].Doc,
+			"Looks up a user.|@param[id] the user's ID, as returned by create|@throws[NotFound] if there is no such user|@return[] the user"},
+		"block comment": {file.FindScope("Changes").Doc, "Published on every change"},
+		"event":         {file.FindScope("Changes").Events[0].Doc, "The user after the change"},
+		"enum value":    {file.FindEnum("Status").Values[0].Doc, "Can sign in"},
+		"undocumented":  {file.FindEnum("Status").Doc, "-"},
+	}
+	for name, tt := range tests {
+		if got := describe(tt.doc); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", name, tt.expected, got)
+		}
+	}
+}
@@ -32,13 +32,11 @@ export function activate(context: vscode.ExtensionContext) {
 		// Register the server for Frugal documents
 		documentSelector: [{ scheme: 'file', language: 'frugal' }],
 		synchronize: {
-			// Notify the server about file changes to '.frugal' files contained in the workspace
-			fileEvents: vscode.workspace.createFileSystemWatcher('**/*.frugal')
+			// Notify the server when frugal-ls settings change so they apply without a restart
+			configurationSection: 'frugal-ls'
 		},
 		// Pass workspace configuration to the server
-		initializationOptions: {
-			// Any initialization options can go here
-		},
+		initializationOptions: vscode.workspace.getConfiguration('frugal-ls'),
 		middleware: {
 			// Add any middleware here if needed
 		}